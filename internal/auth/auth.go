@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // JWTConfig holds the configuration for JWT token generation and validation
@@ -26,20 +27,26 @@ func NewJWTConfig(secretKey string, tokenDuration time.Duration) *JWTConfig {
 	}
 }
 
-// GenerateToken creates a new JWT token with user information
+// GenerateToken creates a new JWT token with user information and a unique ID (jti)
+// so the token can be revoked through the Denylist
 // Parameters:
 //   - userID: The unique identifier of the user
 //   - username: The user's username
 //   - role: The user's role in the system
+//   - tokenVersion: The user's current token version, see UserStateCache
+//   - sessionID: The session the token belongs to, see SessionCache
 //
 // Returns:
 //   - A signed JWT token string and any error that occurred during signing
-func (c *JWTConfig) GenerateToken(userID, username, role string) (string, error) {
+func (c *JWTConfig) GenerateToken(userID, username, role string, tokenVersion int, sessionID string) (string, error) {
 	claims := jwt.MapClaims{
-		"user_id":  userID,
-		"username": username,
-		"role":     role,
-		"exp":      time.Now().Add(c.TokenDuration).Unix(),
+		"user_id":       userID,
+		"username":      username,
+		"role":          role,
+		"token_version": tokenVersion,
+		"sid":           sessionID,
+		"jti":           uuid.NewString(),
+		"exp":           time.Now().Add(c.TokenDuration).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateToken(t *testing.T) {
+	config := NewJWTConfig("test-secret", 15*time.Minute)
+
+	first, err := config.GenerateToken("user-id", "alice", "user", 3, "session-id")
+	require.NoError(t, err)
+	second, err := config.GenerateToken("user-id", "alice", "user", 3, "session-id")
+	require.NoError(t, err)
+
+	token, err := config.ValidateToken(first)
+	require.NoError(t, err)
+	claims := token.Claims.(jwt.MapClaims)
+	assert.Equal(t, "user-id", claims["user_id"])
+	assert.Equal(t, float64(3), claims["token_version"])
+	assert.Equal(t, "session-id", claims["sid"])
+	exp, err := claims.GetExpirationTime()
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), exp.Time, 5*time.Second)
+
+	// Every token gets its own ID so it can be revoked alone
+	other, err := config.ValidateToken(second)
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims["jti"])
+	assert.NotEqual(t, claims["jti"], other.Claims.(jwt.MapClaims)["jti"])
+}
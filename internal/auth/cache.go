@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxCacheEntries is the size from which expired entries are swept out of a cache
+const maxCacheEntries = 10000
+
+type cacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// ttlCache remembers loaded values for ttl. Invalidate takes effect immediately,
+// even for a load that is running at the time.
+type ttlCache[V any] struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry[V]
+	// generation counts invalidations so a load racing with one isn't cached
+	generation uint64
+	now        func() time.Time
+}
+
+func newTTLCache[V any](ttl time.Duration) *ttlCache[V] {
+	return &ttlCache[V]{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry[V]),
+		now:     time.Now,
+	}
+}
+
+// get returns the cached value of key, calling load when there is none
+func (c *ttlCache[V]) get(ctx context.Context, key string, load func(ctx context.Context, key string) (V, error)) (V, error) {
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	generation := c.generation
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	value, err := load(ctx, key)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	c.mu.Lock()
+	if c.ttl > 0 && c.generation == generation {
+		// Drop expired entries now and then so the map doesn't grow with every key ever seen
+		if len(c.entries) >= maxCacheEntries {
+			for k, e := range c.entries {
+				if !now.Before(e.expiresAt) {
+					delete(c.entries, k)
+				}
+			}
+		}
+		c.entries[key] = cacheEntry[V]{value: value, expiresAt: now.Add(c.ttl)}
+	}
+	c.mu.Unlock()
+	return value, nil
+}
+
+// invalidate forgets the cached values of keys
+func (c *ttlCache[V]) invalidate(keys ...string) {
+	c.mu.Lock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	c.generation++
+	c.mu.Unlock()
+}
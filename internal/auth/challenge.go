@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Purposes of a login challenge
+const (
+	ChallengeTwoFactor      = "2fa"       // Enter a code of the enrolled authenticator
+	ChallengeTwoFactorSetup = "2fa_setup" // Enrol an authenticator, required by policy
+)
+
+// ErrInvalidChallenge is returned for expired, tampered or foreign challenge tokens
+var ErrInvalidChallenge = errors.New("invalid or expired challenge token")
+
+// Challenge is the second step of a login the password was checked for
+type Challenge struct {
+	ID        string
+	UserID    string
+	Purpose   string
+	ExpiresAt time.Time
+}
+
+// challengeKey signs challenge tokens. It differs from the access token key so a
+// challenge token is never accepted as an access token, and the other way around.
+func (c *JWTConfig) challengeKey() []byte {
+	return []byte(c.SecretKey + "/login-challenge")
+}
+
+// GenerateChallengeToken signs a challenge token valid for duration
+func (c *JWTConfig) GenerateChallengeToken(userID, purpose string, duration time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"purpose": purpose,
+		"jti":     uuid.NewString(),
+		"exp":     time.Now().Add(duration).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(c.challengeKey())
+}
+
+// ValidateChallengeToken checks a challenge token and returns its challenge
+func (c *JWTConfig) ValidateChallengeToken(tokenString string) (*Challenge, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return c.challengeKey(), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidChallenge
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidChallenge
+	}
+	challenge := &Challenge{}
+	challenge.ID, _ = claims["jti"].(string)
+	challenge.UserID, _ = claims["user_id"].(string)
+	challenge.Purpose, _ = claims["purpose"].(string)
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		challenge.ExpiresAt = exp.Time
+	}
+	if challenge.ID == "" || challenge.UserID == "" || challenge.Purpose == "" {
+		return nil, ErrInvalidChallenge
+	}
+	return challenge, nil
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengeToken(t *testing.T) {
+	config := NewJWTConfig("test-secret", 15*time.Minute)
+
+	token, err := config.GenerateChallengeToken("user-id", ChallengeTwoFactor, 5*time.Minute)
+	require.NoError(t, err)
+	challenge, err := config.ValidateChallengeToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-id", challenge.UserID)
+	assert.Equal(t, ChallengeTwoFactor, challenge.Purpose)
+	assert.NotEmpty(t, challenge.ID)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), challenge.ExpiresAt, 5*time.Second)
+
+	// Challenge tokens and access tokens are not interchangeable
+	_, err = config.ValidateToken(token)
+	assert.Error(t, err)
+	access, err := config.GenerateToken("user-id", "alice", "admin", 0, "session-id")
+	require.NoError(t, err)
+	_, err = config.ValidateChallengeToken(access)
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+
+	// Expired challenges are rejected
+	expired, err := config.GenerateChallengeToken("user-id", ChallengeTwoFactor, -time.Minute)
+	require.NoError(t, err)
+	_, err = config.ValidateChallengeToken(expired)
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+}
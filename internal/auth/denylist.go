@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenCheck runs after the signature and expiry of a token were verified.
+// It returns ErrAccountInactive or ErrTokenRevoked to reject the token.
+type TokenCheck func(ctx context.Context, claims jwt.MapClaims) error
+
+// Denylist holds the IDs (jti) of access tokens revoked before they expire,
+// such as the token used to log out
+type Denylist struct {
+	db      *sql.DB
+	mu      sync.Mutex
+	revoked map[string]time.Time // Tokens known to be revoked, until they expire
+}
+
+func NewDenylist(db *sql.DB) *Denylist {
+	return &Denylist{
+		db:      db,
+		revoked: make(map[string]time.Time),
+	}
+}
+
+// Revoke denies the access token with ID jti until it expires
+func (d *Denylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := d.db.ExecContext(ctx, `
+		INSERT INTO revoked_access_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, expiresAt)
+	if err != nil {
+		return fmt.Errorf("error revoking access token: %v", err)
+	}
+
+	// Expired tokens are rejected anyway
+	_, err = d.db.ExecContext(ctx, `DELETE FROM revoked_access_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		return fmt.Errorf("error removing expired revocations: %v", err)
+	}
+
+	d.remember(jti, expiresAt)
+	return nil
+}
+
+func (d *Denylist) remember(jti string, expiresAt time.Time) {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for id, expiry := range d.revoked {
+		if now.After(expiry) {
+			delete(d.revoked, id)
+		}
+	}
+	d.revoked[jti] = expiresAt
+}
+
+// IsRevoked reports whether the access token with ID jti was revoked
+func (d *Denylist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	d.mu.Lock()
+	_, ok := d.revoked[jti]
+	d.mu.Unlock()
+	if ok {
+		return true, nil
+	}
+
+	var expiresAt time.Time
+	err := d.db.QueryRowContext(ctx, `SELECT expires_at FROM revoked_access_tokens WHERE jti = $1`, jti).Scan(&expiresAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error checking revoked access token: %v", err)
+	}
+	d.remember(jti, expiresAt)
+	return true, nil
+}
+
+// TokenCheck rejects revoked access tokens. Tokens without an ID predate the denylist.
+func (d *Denylist) TokenCheck() TokenCheck {
+	return func(ctx context.Context, claims jwt.MapClaims) error {
+		jti, _ := claims["jti"].(string)
+		if jti == "" {
+			return nil
+		}
+		revoked, err := d.IsRevoked(ctx, jti)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return ErrTokenRevoked
+		}
+		return nil
+	}
+}
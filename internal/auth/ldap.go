@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var (
+	// ErrLDAPUserNotFound is returned when no directory entry matches a username
+	ErrLDAPUserNotFound = errors.New("user not found in directory")
+	// ErrLDAPInvalidCredentials is returned when the directory refuses the password of a user
+	ErrLDAPInvalidCredentials = errors.New("invalid directory credentials")
+)
+
+// LDAPConfig configures the connection to a directory and where its users and groups live
+type LDAPConfig struct {
+	URL                string // ldap:// or ldaps:// URL of the server
+	StartTLS           bool   // Upgrade an ldap:// connection with StartTLS
+	InsecureSkipVerify bool
+	RootCAs            *x509.CertPool // CAs to verify the server with, nil uses the system pool
+
+	BindDN       string // Service account searching the directory, empty binds anonymously
+	BindPassword string
+
+	UserBaseDN   string
+	UserFilter   string // Filter finding a user, %s is replaced with the escaped username
+	UsernameAttr string
+	EmailAttr    string
+
+	GroupBaseDN string // Empty reads groups from the memberOf attribute only
+	GroupFilter string // Filter finding the groups of a user, %s is replaced with the escaped user DN
+
+	Timeout time.Duration
+}
+
+// LDAPEntry is a user as the directory describes them
+type LDAPEntry struct {
+	DN       string
+	Username string
+	Email    string
+	Groups   []string // DNs of the groups the user is a member of
+}
+
+// GroupNames returns the group DNs of the entry along with the value of their
+// first RDN, so role mappings can name groups either way
+func (e *LDAPEntry) GroupNames() []string {
+	names := make([]string, 0, 2*len(e.Groups))
+	for _, group := range e.Groups {
+		names = append(names, group)
+		dn, err := ldap.ParseDN(group)
+		if err == nil && len(dn.RDNs) > 0 && len(dn.RDNs[0].Attributes) > 0 {
+			names = append(names, dn.RDNs[0].Attributes[0].Value)
+		}
+	}
+	return names
+}
+
+// LDAPDirectory authenticates users with a bind-and-search against an LDAP directory:
+// the service account finds the entry of the user, which is then bound with the
+// password the user gave
+type LDAPDirectory struct {
+	config LDAPConfig
+	source string
+}
+
+// NewLDAPDirectory creates a directory client, filling in the defaults for unset
+// filters and attributes
+func NewLDAPDirectory(config LDAPConfig) *LDAPDirectory {
+	if config.UserFilter == "" {
+		config.UserFilter = "(uid=%s)"
+	}
+	if config.UsernameAttr == "" {
+		config.UsernameAttr = "uid"
+	}
+	if config.EmailAttr == "" {
+		config.EmailAttr = "mail"
+	}
+	if config.GroupFilter == "" {
+		config.GroupFilter = "(member=%s)"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &LDAPDirectory{
+		config: config,
+		source: "ldap:" + strings.TrimSuffix(config.URL, "/") + "/" + config.UserBaseDN,
+	}
+}
+
+// Source returns the name identities from this directory are linked under
+func (d *LDAPDirectory) Source() string {
+	return d.source
+}
+
+// connect opens a connection bound as the service account. The connection is
+// closed when ctx is done.
+func (d *LDAPDirectory) connect(ctx context.Context) (*ldap.Conn, func(), error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: d.config.InsecureSkipVerify,
+		RootCAs:            d.config.RootCAs,
+	}
+	if parsed, err := url.Parse(d.config.URL); err == nil {
+		tlsConfig.ServerName = parsed.Hostname()
+	}
+
+	conn, err := ldap.DialURL(d.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: d.config.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error connecting to LDAP server: %v", err)
+	}
+	conn.SetTimeout(d.config.Timeout)
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	closeConn := func() {
+		stop()
+		conn.Close()
+	}
+
+	if d.config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			closeConn()
+			return nil, nil, fmt.Errorf("error starting TLS with LDAP server: %v", err)
+		}
+	}
+	if d.config.BindDN != "" {
+		err = conn.Bind(d.config.BindDN, d.config.BindPassword)
+	} else {
+		err = conn.UnauthenticatedBind("")
+	}
+	if err != nil {
+		closeConn()
+		return nil, nil, fmt.Errorf("error binding LDAP service account: %v", err)
+	}
+	return conn, closeConn, nil
+}
+
+// search finds the entry of a user and its groups
+func (d *LDAPDirectory) search(conn *ldap.Conn, username string) (*LDAPEntry, error) {
+	result, err := conn.Search(ldap.NewSearchRequest(
+		d.config.UserBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(d.config.UserFilter, ldap.EscapeFilter(username)),
+		[]string{d.config.UsernameAttr, d.config.EmailAttr, "memberOf"},
+		nil,
+	))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) || (err == nil && len(result.Entries) > 1) {
+		return nil, fmt.Errorf("LDAP username %s matches several entries", username)
+	}
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, fmt.Errorf("error searching LDAP user: %v", err)
+	}
+	if result == nil || len(result.Entries) == 0 {
+		return nil, ErrLDAPUserNotFound
+	}
+
+	found := result.Entries[0]
+	entry := &LDAPEntry{
+		DN:       found.DN,
+		Username: found.GetAttributeValue(d.config.UsernameAttr),
+		Email:    found.GetAttributeValue(d.config.EmailAttr),
+		Groups:   found.GetAttributeValues("memberOf"),
+	}
+	if entry.Username == "" {
+		entry.Username = username
+	}
+
+	if d.config.GroupBaseDN != "" {
+		groups, err := conn.Search(ldap.NewSearchRequest(
+			d.config.GroupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+			fmt.Sprintf(d.config.GroupFilter, ldap.EscapeFilter(entry.DN)),
+			[]string{"1.1"}, // No attributes, the DN is enough
+			nil,
+		))
+		if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, fmt.Errorf("error searching LDAP groups: %v", err)
+		}
+		if groups != nil {
+			for _, group := range groups.Entries {
+				if !hasGroup(entry.Groups, group.DN) {
+					entry.Groups = append(entry.Groups, group.DN)
+				}
+			}
+		}
+	}
+	return entry, nil
+}
+
+// hasGroup reports whether groups holds the DN group, ignoring case
+func hasGroup(groups []string, group string) bool {
+	for _, g := range groups {
+		if strings.EqualFold(g, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticate checks the password of a user by binding as them and returns their entry
+func (d *LDAPDirectory) Authenticate(ctx context.Context, username, password string) (*LDAPEntry, error) {
+	// Most servers treat a bind without password as an anonymous bind that succeeds
+	if username == "" || password == "" {
+		return nil, ErrLDAPInvalidCredentials
+	}
+
+	conn, closeConn, err := d.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeConn()
+
+	entry, err := d.search(conn, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrLDAPInvalidCredentials
+		}
+		return nil, fmt.Errorf("error binding LDAP user: %v", err)
+	}
+	return entry, nil
+}
+
+// Lookup returns the entry of a user without checking a password
+func (d *LDAPDirectory) Lookup(ctx context.Context, username string) (*LDAPEntry, error) {
+	conn, closeConn, err := d.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeConn()
+
+	return d.search(conn, username)
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"testing"
+
+	"github.com/jimlambrt/gldap/testdirectory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestDirectory starts an in-process directory with alice in the admins
+// group by memberOf, bob in the kitchen group by member and a lone carol
+func startTestDirectory(t *testing.T, opts ...testdirectory.Option) *testdirectory.Directory {
+	opts = append(opts, testdirectory.WithDefaults(t, &testdirectory.Defaults{AllowAnonymousBind: true}))
+	td := testdirectory.Start(t, opts...)
+
+	users := testdirectory.NewUsers(t, []string{"alice"}, testdirectory.WithMembersOf(t, "cn=admins,ou=groups,dc=example,dc=org"))
+	users = append(users, testdirectory.NewUsers(t, []string{"bob", "carol"})...)
+	td.SetUsers(users...)
+	td.SetGroups(testdirectory.NewGroup(t, "kitchen", []string{"bob"}))
+	return td
+}
+
+func testLDAPConfig(td *testdirectory.Directory) LDAPConfig {
+	return LDAPConfig{
+		URL:          fmt.Sprintf("ldap://%s:%d", td.Host(), td.Port()),
+		UserBaseDN:   "ou=people,dc=example,dc=org",
+		UserFilter:   "(cn=%s)",
+		UsernameAttr: "name",
+		EmailAttr:    "email",
+		GroupBaseDN:  "ou=groups,dc=example,dc=org",
+	}
+}
+
+func TestLDAPDirectoryAuthenticate(t *testing.T) {
+	td := startTestDirectory(t, testdirectory.WithNoTLS(t))
+	directory := NewLDAPDirectory(testLDAPConfig(td))
+	ctx := context.Background()
+
+	entry, err := directory.Authenticate(ctx, "alice", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "cn=alice,ou=people,dc=example,dc=org", entry.DN)
+	assert.Equal(t, "alice", entry.Username)
+	assert.Equal(t, "alice@example.com", entry.Email)
+	assert.Equal(t, []string{"cn=admins,ou=groups,dc=example,dc=org", "admins"}, entry.GroupNames())
+
+	// Groups listing the user as member count as well
+	entry, err = directory.Authenticate(ctx, "bob", "password")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cn=kitchen,ou=groups,dc=example,dc=org"}, entry.Groups)
+
+	_, err = directory.Authenticate(ctx, "alice", "wrong")
+	assert.ErrorIs(t, err, ErrLDAPInvalidCredentials)
+	_, err = directory.Authenticate(ctx, "dave", "password")
+	assert.ErrorIs(t, err, ErrLDAPUserNotFound)
+
+	// The directory accepts binds without password, the client must not
+	_, err = directory.Authenticate(ctx, "alice", "")
+	assert.ErrorIs(t, err, ErrLDAPInvalidCredentials)
+}
+
+func TestLDAPDirectoryLookup(t *testing.T) {
+	td := startTestDirectory(t, testdirectory.WithNoTLS(t))
+	config := testLDAPConfig(td)
+	config.GroupBaseDN = ""
+	directory := NewLDAPDirectory(config)
+	ctx := context.Background()
+
+	entry, err := directory.Lookup(ctx, "carol")
+	require.NoError(t, err)
+	assert.Equal(t, "carol@example.com", entry.Email)
+	assert.Empty(t, entry.Groups)
+
+	// Without a group base only memberOf is read
+	entry, err = directory.Lookup(ctx, "bob")
+	require.NoError(t, err)
+	assert.Empty(t, entry.Groups)
+
+	// A username must identify a single entry
+	td.SetUsers(append(td.Users(), testdirectory.NewUsers(t, []string{"carol2"})...)...)
+	_, err = directory.Lookup(ctx, "carol")
+	assert.ErrorContains(t, err, "matches several entries")
+
+	td.SetUsers()
+	_, err = directory.Lookup(ctx, "alice")
+	assert.ErrorIs(t, err, ErrLDAPUserNotFound)
+}
+
+func TestLDAPDirectoryTLS(t *testing.T) {
+	td := startTestDirectory(t)
+	config := testLDAPConfig(td)
+	config.URL = fmt.Sprintf("ldaps://%s:%d", td.Host(), td.Port())
+
+	// The test directory uses its own CA
+	_, err := NewLDAPDirectory(config).Authenticate(context.Background(), "alice", "password")
+	assert.Error(t, err)
+
+	config.RootCAs = x509.NewCertPool()
+	require.True(t, config.RootCAs.AppendCertsFromPEM([]byte(td.Cert())))
+	_, err = NewLDAPDirectory(config).Authenticate(context.Background(), "alice", "password")
+	assert.NoError(t, err)
+}
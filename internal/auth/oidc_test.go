@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockOIDCServer is a minimal identity provider: it serves discovery and the
+// JWKS, and its token endpoint redeems the codes handed out by authorize
+type mockOIDCServer struct {
+	*httptest.Server
+	t        *testing.T
+	key      *rsa.PrivateKey
+	kid      string
+	clientID string
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+// mockAuthorization is a login approved at the identity provider
+type mockAuthorization struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockOIDCServer(t *testing.T, clientID string) *mockOIDCServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	m := &mockOIDCServer{t: t, key: key, kid: "key-1", clientID: clientID, codes: map[string]mockAuthorization{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": m.kid,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		m.mu.Lock()
+		authorization, ok := m.codes[r.Form.Get("code")]
+		delete(m.codes, r.Form.Get("code"))
+		m.mu.Unlock()
+
+		if !ok || r.Form.Get("client_id") != m.clientID || PKCEChallenge(r.Form.Get("code_verifier")) != authorization.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": m.sign(authorization.claims), "token_type": "Bearer"})
+	})
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// sign issues an ID token with the current key
+func (m *mockOIDCServer) sign(claims jwt.MapClaims) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	signed, err := token.SignedString(m.key)
+	require.NoError(m.t, err)
+	return signed
+}
+
+// authorize approves the login started by authURL with extra claims and returns the code
+func (m *mockOIDCServer) authorize(authURL string, extra jwt.MapClaims) string {
+	parsed, err := url.Parse(authURL)
+	require.NoError(m.t, err)
+	query := parsed.Query()
+	require.Equal(m.t, "S256", query.Get("code_challenge_method"))
+
+	claims := m.claims(query.Get("nonce"))
+	for name, value := range extra {
+		claims[name] = value
+	}
+	code := "code-" + query.Get("state")
+	m.mu.Lock()
+	m.codes[code] = mockAuthorization{challenge: query.Get("code_challenge"), claims: claims}
+	m.mu.Unlock()
+	return code
+}
+
+func (m *mockOIDCServer) claims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            m.URL,
+		"sub":            "subject-1",
+		"aud":            m.clientID,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(5 * time.Minute).Unix(),
+		"nonce":          nonce,
+		"email":          "alice@example.com",
+		"email_verified": true,
+	}
+}
+
+// rotateKey replaces the signing key
+func (m *mockOIDCServer) rotateKey(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(m.t, err)
+	m.mu.Lock()
+	m.key, m.kid = key, kid
+	m.mu.Unlock()
+}
+
+func TestOIDCProviderLogin(t *testing.T) {
+	idp := newMockOIDCServer(t, "room-booking")
+	provider := NewOIDCProvider(idp.URL, "room-booking", "secret", "http://localhost/callback", []string{"email", "profile"}, nil)
+	ctx := context.Background()
+
+	verifier, err := NewPKCEVerifier()
+	require.NoError(t, err)
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", PKCEChallenge(verifier))
+	require.NoError(t, err)
+	assert.Contains(t, authURL, idp.URL+"/authorize?")
+	assert.Contains(t, authURL, "scope=openid+email+profile")
+
+	code := idp.authorize(authURL, jwt.MapClaims{"preferred_username": "alice", "groups": []string{"admins"}})
+
+	// The code only works with the verifier of the login
+	_, err = provider.Exchange(ctx, code, "wrong-verifier")
+	assert.Error(t, err)
+	code = idp.authorize(authURL, jwt.MapClaims{"preferred_username": "alice", "groups": []string{"admins"}})
+	rawIDToken, err := provider.Exchange(ctx, code, verifier)
+	require.NoError(t, err)
+
+	idToken, err := provider.VerifyIDToken(ctx, rawIDToken, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "subject-1", idToken.Subject)
+	assert.Equal(t, "alice@example.com", idToken.Email)
+	assert.True(t, idToken.EmailVerified)
+	assert.Equal(t, "alice", idToken.PreferredUsername)
+	assert.Equal(t, []interface{}{"admins"}, idToken.Claims["groups"])
+
+	// The nonce ties the token to the login that requested it
+	_, err = provider.VerifyIDToken(ctx, rawIDToken, "nonce-2")
+	assert.ErrorIs(t, err, ErrInvalidIDToken)
+}
+
+func TestOIDCProviderVerifyIDToken(t *testing.T) {
+	idp := newMockOIDCServer(t, "room-booking")
+	provider := NewOIDCProvider(idp.URL, "room-booking", "", "http://localhost/callback", nil, nil)
+	ctx := context.Background()
+
+	with := func(name string, value interface{}) jwt.MapClaims {
+		claims := idp.claims("nonce")
+		claims[name] = value
+		return claims
+	}
+
+	_, err := provider.VerifyIDToken(ctx, idp.sign(idp.claims("nonce")), "nonce")
+	require.NoError(t, err)
+
+	rejected := map[string]jwt.MapClaims{
+		"other issuer":   with("iss", "https://evil.example.com"),
+		"other audience": with("aud", "other-client"),
+		"expired":        with("exp", time.Now().Add(-5*time.Minute).Unix()),
+		"no subject":     with("sub", ""),
+		"foreign azp":    with("aud", []string{"room-booking", "other-client"}),
+	}
+	for name, claims := range rejected {
+		_, err := provider.VerifyIDToken(ctx, idp.sign(claims), "nonce")
+		assert.ErrorIs(t, err, ErrInvalidIDToken, name)
+	}
+
+	// Several audiences are fine when this client is the authorized party
+	claims := with("aud", []string{"room-booking", "other-client"})
+	claims["azp"] = "room-booking"
+	_, err = provider.VerifyIDToken(ctx, idp.sign(claims), "nonce")
+	assert.NoError(t, err)
+
+	// Tokens signed with a key the provider does not publish are rejected
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.claims("nonce"))
+	forged.Header["kid"] = idp.kid
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signed, err := forged.SignedString(otherKey)
+	require.NoError(t, err)
+	_, err = provider.VerifyIDToken(ctx, signed, "nonce")
+	assert.ErrorIs(t, err, ErrInvalidIDToken)
+
+	// A rotated key is fetched once the previous fetch is old enough
+	idp.rotateKey("key-2")
+	rotated := idp.sign(idp.claims("nonce"))
+	_, err = provider.VerifyIDToken(ctx, rotated, "nonce")
+	assert.ErrorIs(t, err, ErrInvalidIDToken)
+	provider.now = func() time.Time { return time.Now().Add(jwksRefreshInterval) }
+	_, err = provider.VerifyIDToken(ctx, rotated, "nonce")
+	assert.NoError(t, err)
+}
+
+func TestOIDCProviderDiscoveryChecksIssuer(t *testing.T) {
+	idp := newMockOIDCServer(t, "room-booking")
+	provider := NewOIDCProvider(idp.URL+"/other", "room-booking", "", "http://localhost/callback", nil, nil)
+
+	_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	assert.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrSessionRevoked is returned for tokens of a session that was signed out
+var ErrSessionRevoked = errors.New("session has been revoked")
+
+// SessionLoader reports whether a session is still active.
+// Unknown sessions are reported as inactive.
+type SessionLoader func(ctx context.Context, sessionID string) (bool, error)
+
+// SessionCache checks tokens against the session (sid) they were issued for.
+// Like UserStateCache it caches for ttl, so a session revoked on another
+// instance is rejected there within ttl.
+type SessionCache struct {
+	load     SessionLoader
+	sessions *ttlCache[bool]
+}
+
+// NewSessionCache creates a cache over load, a ttl of 0 disables caching
+func NewSessionCache(load SessionLoader, ttl time.Duration) *SessionCache {
+	return &SessionCache{
+		load:     load,
+		sessions: newTTLCache[bool](ttl),
+	}
+}
+
+// DBSessionLoader loads sessions from the sessions table. Loading a session marks it
+// as used, so last_used_at is kept up to date once per cache period.
+func DBSessionLoader(db *sql.DB) SessionLoader {
+	return func(ctx context.Context, sessionID string) (bool, error) {
+		var id string
+		err := db.QueryRowContext(ctx, `
+			UPDATE sessions SET last_used_at = NOW()
+			WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+			RETURNING id
+		`, sessionID).Scan(&id)
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("error fetching session: %v", err)
+		}
+		return true, nil
+	}
+}
+
+// Check returns ErrSessionRevoked unless the session is active
+func (c *SessionCache) Check(ctx context.Context, sessionID string) error {
+	active, err := c.sessions.get(ctx, sessionID, c.load)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// TokenCheck checks tokens against their session. Tokens issued before
+// sessions existed carry no session ID.
+func (c *SessionCache) TokenCheck() TokenCheck {
+	return func(ctx context.Context, claims jwt.MapClaims) error {
+		sessionID, _ := claims["sid"].(string)
+		if sessionID == "" {
+			return nil
+		}
+		return c.Check(ctx, sessionID)
+	}
+}
+
+// Invalidate forgets the cached state of sessions after they were revoked
+func (c *SessionCache) Invalidate(sessionIDs ...string) {
+	c.sessions.invalidate(sessionIDs...)
+}
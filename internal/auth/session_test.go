@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionCache(t *testing.T) {
+	active := map[string]bool{"current": true, "other": true}
+	loads := 0
+	cache := NewSessionCache(func(ctx context.Context, sessionID string) (bool, error) {
+		loads++
+		return active[sessionID], nil
+	}, time.Minute)
+	check := cache.TokenCheck()
+	ctx := context.Background()
+
+	assert.NoError(t, check(ctx, jwt.MapClaims{"sid": "current"}))
+	assert.NoError(t, check(ctx, jwt.MapClaims{"sid": "other"}))
+	assert.ErrorIs(t, check(ctx, jwt.MapClaims{"sid": "unknown"}), ErrSessionRevoked)
+
+	// Tokens without a session predate sessions
+	assert.NoError(t, check(ctx, jwt.MapClaims{}))
+	assert.Equal(t, 3, loads)
+
+	// Revoked sessions are rejected as soon as they are invalidated
+	active["current"] = false
+	active["other"] = false
+	assert.NoError(t, check(ctx, jwt.MapClaims{"sid": "current"}))
+	assert.NoError(t, check(ctx, jwt.MapClaims{"sid": "other"}))
+	cache.Invalidate("current", "other")
+	assert.ErrorIs(t, check(ctx, jwt.MapClaims{"sid": "current"}), ErrSessionRevoked)
+	assert.ErrorIs(t, check(ctx, jwt.MapClaims{"sid": "other"}), ErrSessionRevoked)
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors, "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to 6 digits
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		code, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, "time %d", unix)
+	}
+
+	_, err := TOTPCode("not base32!", 1)
+	assert.Error(t, err)
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := TOTPStep(now)
+	previous, err := TOTPCode(rfcSecret, step-1)
+	require.NoError(t, err)
+	stale, err := TOTPCode(rfcSecret, step-2)
+	require.NoError(t, err)
+
+	matched, ok := VerifyTOTP(rfcSecret, "050471", now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, step, matched)
+
+	// One step of clock drift is accepted, more is not
+	matched, ok = VerifyTOTP(rfcSecret, previous, now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, step-1, matched)
+	_, ok = VerifyTOTP(rfcSecret, stale, now, 0)
+	assert.False(t, ok)
+
+	// A code is accepted once
+	_, ok = VerifyTOTP(rfcSecret, "050471", now, step)
+	assert.False(t, ok)
+
+	_, ok = VerifyTOTP(rfcSecret, "12345", now, 0)
+	assert.False(t, ok)
+}
+
+func TestNewTOTPSecret(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+	_, err = TOTPCode(secret, 1)
+	assert.NoError(t, err)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(TOTPProvisioningURI("E-Meeting", "alice@example.com", rfcSecret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/E-Meeting:alice@example.com", uri.Path)
+	assert.Equal(t, rfcSecret, uri.Query().Get("secret"))
+	assert.Equal(t, "E-Meeting", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+}
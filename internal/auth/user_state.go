@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrAccountInactive is returned for tokens of deactivated or deleted accounts
+	ErrAccountInactive = errors.New("account is inactive")
+	// ErrTokenRevoked is returned for tokens issued before the account's token version was bumped
+	ErrTokenRevoked = errors.New("token has been revoked")
+)
+
+// UserState is the part of an account that decides whether its tokens are still accepted
+type UserState struct {
+	Active       bool // Status is set and the account is not deleted
+	TokenVersion int  // Bumped whenever the account loses access
+}
+
+// UserStateLoader reads the current state of an account.
+// Unknown accounts are reported as inactive.
+type UserStateLoader func(ctx context.Context, userID string) (UserState, error)
+
+// UserStateCache checks tokens against the current state of their account.
+// States are cached for ttl so most requests don't reach the database; changes
+// made through Invalidate take effect immediately on this instance and within
+// ttl everywhere else.
+type UserStateCache struct {
+	load   UserStateLoader
+	states *ttlCache[UserState]
+}
+
+// NewUserStateCache creates a cache over load, a ttl of 0 disables caching
+func NewUserStateCache(load UserStateLoader, ttl time.Duration) *UserStateCache {
+	return &UserStateCache{
+		load:   load,
+		states: newTTLCache[UserState](ttl),
+	}
+}
+
+// DBUserStateLoader loads account states from the users table
+func DBUserStateLoader(db *sql.DB) UserStateLoader {
+	return func(ctx context.Context, userID string) (UserState, error) {
+		var state UserState
+		err := db.QueryRowContext(ctx, `
+			SELECT status AND deleted_at IS NULL, token_version
+			FROM users
+			WHERE id = $1
+		`, userID).Scan(&state.Active, &state.TokenVersion)
+		if err == sql.ErrNoRows {
+			return UserState{}, nil
+		}
+		if err != nil {
+			return UserState{}, fmt.Errorf("error fetching user state: %v", err)
+		}
+		return state, nil
+	}
+}
+
+// Check returns an error unless the account is active and the token carries its current version
+func (c *UserStateCache) Check(ctx context.Context, userID string, tokenVersion int) error {
+	state, err := c.states.get(ctx, userID, c.load)
+	if err != nil {
+		return err
+	}
+	if !state.Active {
+		return ErrAccountInactive
+	}
+	if tokenVersion != state.TokenVersion {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// TokenCheck checks tokens against the state of their account.
+// Tokens issued before token versions existed carry version 0.
+func (c *UserStateCache) TokenCheck() TokenCheck {
+	return func(ctx context.Context, claims jwt.MapClaims) error {
+		userID, _ := claims["user_id"].(string)
+		version, _ := claims["token_version"].(float64)
+		return c.Check(ctx, userID, int(version))
+	}
+}
+
+// Invalidate forgets the cached state of an account after it changed
+func (c *UserStateCache) Invalidate(userID string) {
+	c.states.invalidate(userID)
+}
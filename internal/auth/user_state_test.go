@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserStateCache(t *testing.T) {
+	states := map[string]UserState{
+		"active":   {Active: true, TokenVersion: 2},
+		"inactive": {Active: false, TokenVersion: 3},
+	}
+	loads := 0
+	cache := NewUserStateCache(func(ctx context.Context, userID string) (UserState, error) {
+		loads++
+		return states[userID], nil
+	}, time.Minute)
+	now := time.Date(2026, 5, 4, 8, 0, 0, 0, time.UTC)
+	cache.states.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.NoError(t, cache.Check(ctx, "active", 2))
+	assert.ErrorIs(t, cache.Check(ctx, "active", 1), ErrTokenRevoked)
+	assert.ErrorIs(t, cache.Check(ctx, "inactive", 3), ErrAccountInactive)
+	assert.ErrorIs(t, cache.Check(ctx, "unknown", 0), ErrAccountInactive)
+	assert.Equal(t, 3, loads)
+
+	// Invalidate makes a change visible immediately
+	states["inactive"] = UserState{Active: true, TokenVersion: 3}
+	assert.ErrorIs(t, cache.Check(ctx, "inactive", 3), ErrAccountInactive)
+	cache.Invalidate("inactive")
+	assert.NoError(t, cache.Check(ctx, "inactive", 3))
+
+	// A deactivation elsewhere is only seen once the cached state expires
+	states["active"] = UserState{Active: false, TokenVersion: 3}
+	assert.NoError(t, cache.Check(ctx, "active", 2))
+	now = now.Add(time.Minute)
+	assert.ErrorIs(t, cache.Check(ctx, "active", 2), ErrAccountInactive)
+}
+
+func TestUserStateCache_NoCaching(t *testing.T) {
+	loads := 0
+	cache := NewUserStateCache(func(ctx context.Context, userID string) (UserState, error) {
+		loads++
+		return UserState{Active: true}, nil
+	}, 0)
+
+	assert.NoError(t, cache.Check(context.Background(), "user", 0))
+	assert.NoError(t, cache.Check(context.Background(), "user", 0))
+	assert.Equal(t, 2, loads)
+}
@@ -28,8 +28,53 @@ type Config struct {
 
 	// JWT authentication settings
 	JWT struct {
-		SecretKey     string // Secret key for signing JWT tokens
-		TokenDuration int    // Duration (in hours) for which JWT tokens are valid
+		SecretKey             string // Secret key for signing JWT tokens
+		AccessTokenMinutes    int    // Duration (in minutes) for which access tokens are valid
+		RefreshTokenHours     int    // Duration (in hours) for which refresh tokens are valid
+		UserStateCacheSeconds int    // Seconds the account and session behind a token are cached, 0 checks every request
+	}
+
+	// Two-factor authentication settings
+	TwoFactor struct {
+		Issuer           string // Name shown for the account in authenticator apps
+		ChallengeMinutes int    // Time a login has to enter its second factor after the password
+	}
+
+	// OpenID Connect single sign-on, enabled when an issuer is set
+	OIDC struct {
+		Issuer         string   // Issuer URL, the provider configuration is discovered from it
+		ClientID       string   // Client ID registered at the identity provider
+		ClientSecret   string   // Client secret, empty for public clients
+		RedirectURL    string   // Callback URL registered at the identity provider
+		Scopes         []string // Scopes requested on top of openid
+		RoleClaim      string   // Claim listing the groups of a user, empty leaves roles to admins
+		AdminGroups    []string // Groups of the role claim whose members are admins
+		CateringGroups []string // Groups of the role claim whose members are on the catering team
+		LoginMinutes   int      // Time a user has to sign in at the identity provider
+	}
+
+	// Password login providers, asked in order: password checks the password
+	// stored with the account, ldap binds to the directory configured below
+	AuthProviders []string
+
+	// LDAP directory login
+	LDAP struct {
+		URL                string   // ldap:// or ldaps:// URL of the directory server
+		StartTLS           bool     // Upgrade ldap:// connections with StartTLS
+		InsecureSkipVerify bool     // Skip TLS certificate verification
+		CACert             string   // PEM file of the CA the server certificate is verified with, empty uses the system CAs
+		BindDN             string   // Service account searching for users, empty binds anonymously
+		BindPassword       string   // Password of the service account
+		UserBaseDN         string   // Base DN users are searched under
+		UserFilter         string   // Filter finding a user, %s is replaced with the username
+		UsernameAttr       string   // Attribute holding the username
+		EmailAttr          string   // Attribute holding the email address
+		GroupBaseDN        string   // Base DN groups are searched under, empty reads memberOf only
+		GroupFilter        string   // Filter finding the groups of a user, %s is replaced with the user DN
+		AdminGroups        []string // Groups whose members are admins, by name or DN
+		CateringGroups     []string // Groups whose members are on the catering team, by name or DN
+		SyncMinutes        int      // Interval the roles and status of directory users are synced at, 0 disables it
+		TimeoutSeconds     int      // Timeout for connecting to and querying the directory
 	}
 
 	// SMTP email service configuration
@@ -54,6 +99,47 @@ type Config struct {
 	CloudflareR2AccountID  string // Cloudflare account ID
 	CloudflareR2PublicURL  string // Public URL for R2 bucket
 
+	// Pricing engine configuration
+	Pricing struct {
+		Timezone                string // Time zone used to evaluate pricing rule windows and holidays
+		MinBillableMinutes      int    // Minimum billable duration in minutes, 0 disables it
+		BillingIncrementMinutes int    // Billed duration is rounded up to this many minutes, 0 disables it
+		QuoteValidityMinutes    int    // How long a saved price quote stays valid
+	}
+
+	// Tax configuration
+	Tax struct {
+		Rate  float64 // Tax rate in percent applied to the discounted reservation subtotal
+		Label string  // Tax name shown to users (e.g. PPN, VAT)
+	}
+
+	// Payment gateway configuration
+	Payment struct {
+		Provider      string // Payment gateway used for new payments (fake, for development only), empty disables payments
+		WebhookSecret string // Shared secret used to verify gateway webhook signatures, required with a provider
+		Currency      string // ISO 4217 currency code charged for reservations
+	}
+
+	// Snack order configuration
+	Snacks struct {
+		MaxPerVisitor int // Maximum quantity of one snack per visitor in an order, 0 disables the limit
+	}
+
+	// Catering kitchen configuration
+	Catering struct {
+		LeadTimeMinutes int // Snack orders must be placed at least this long before delivery
+	}
+
+	// Uploaded file storage configuration
+	Storage struct {
+		Driver        string // Object store for uploads: local, or s3 for the Cloudflare R2 bucket configured above
+		LocalDir      string // Directory holding uploads with the local driver
+		LocalURL      string // URL path the local directory is served under
+		S3Endpoint    string // S3-compatible endpoint (host[:port]) used instead of the R2 endpoint of the account
+		S3UseSSL      bool   // Use HTTPS to reach the S3 endpoint
+		MaxImageBytes int    // Largest accepted image upload, requests above 4 MB are refused regardless
+	}
+
 	// Server configuration
 	Server struct {
 		Port int // Server port number
@@ -94,7 +180,20 @@ func setDefaults() {
 	viper.SetDefault("DATABASE_MAX_OPEN_CONNECTIONS", 25)
 	viper.SetDefault("DATABASE_MAX_IDLE_CONNECTIONS", 5)
 	viper.SetDefault("JWT_SECRET_KEY", "your-secret-key")
-	viper.SetDefault("JWT_TOKEN_DURATION", 24)
+	viper.SetDefault("JWT_ACCESS_TOKEN_MINUTES", 15)
+	viper.SetDefault("JWT_REFRESH_TOKEN_HOURS", 720)
+	viper.SetDefault("JWT_USER_STATE_CACHE_SECONDS", 30)
+	viper.SetDefault("TWO_FACTOR_ISSUER", "E-Meeting")
+	viper.SetDefault("TWO_FACTOR_CHALLENGE_MINUTES", 5)
+	viper.SetDefault("OIDC_SCOPES", "email profile")
+	viper.SetDefault("OIDC_LOGIN_MINUTES", 10)
+	viper.SetDefault("AUTH_PROVIDERS", "password")
+	viper.SetDefault("LDAP_USER_FILTER", "(uid=%s)")
+	viper.SetDefault("LDAP_USERNAME_ATTR", "uid")
+	viper.SetDefault("LDAP_EMAIL_ATTR", "mail")
+	viper.SetDefault("LDAP_GROUP_FILTER", "(member=%s)")
+	viper.SetDefault("LDAP_SYNC_MINUTES", 60)
+	viper.SetDefault("LDAP_TIMEOUT_SECONDS", 10)
 
 	viper.SetDefault("SMTP_HOST", "smtp.gmail.com")
 	viper.SetDefault("SMTP_PORT", 587)
@@ -113,6 +212,27 @@ func setDefaults() {
 	viper.SetDefault("CLOUDFLARE_R2_TOKEN", "")
 	viper.SetDefault("CLOUDFLARE_R2_ACCOUNT_ID", "")
 	viper.SetDefault("CLOUDFLARE_R2_PUBLIC_URL", "")
+
+	viper.SetDefault("PRICING_TIMEZONE", "Asia/Jakarta")
+	viper.SetDefault("PRICING_MIN_BILLABLE_MINUTES", 0)
+	viper.SetDefault("PRICING_BILLING_INCREMENT_MINUTES", 0)
+	viper.SetDefault("PRICING_QUOTE_VALIDITY_MINUTES", 15)
+
+	viper.SetDefault("TAX_RATE", 0)
+	viper.SetDefault("TAX_LABEL", "PPN")
+
+	viper.SetDefault("PAYMENT_PROVIDER", "")
+	viper.SetDefault("PAYMENT_WEBHOOK_SECRET", "")
+	viper.SetDefault("PAYMENT_CURRENCY", "IDR")
+
+	viper.SetDefault("SNACK_MAX_PER_VISITOR", 5)
+	viper.SetDefault("CATERING_LEAD_TIME_MINUTES", 120)
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_LOCAL_DIR", "./uploads")
+	viper.SetDefault("STORAGE_LOCAL_URL", "/uploads")
+	viper.SetDefault("STORAGE_S3_ENDPOINT", "")
+	viper.SetDefault("STORAGE_S3_USE_SSL", true)
+	viper.SetDefault("STORAGE_MAX_IMAGE_BYTES", 2097152)
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -143,7 +263,40 @@ func LoadConfig(path string) (*Config, error) {
 	config.DBMaxIdleConnections = viper.GetInt("DATABASE_MAX_IDLE_CONNECTIONS")
 
 	config.JWT.SecretKey = viper.GetString("JWT_SECRET_KEY")
-	config.JWT.TokenDuration = viper.GetInt("JWT_TOKEN_DURATION")
+	config.JWT.AccessTokenMinutes = viper.GetInt("JWT_ACCESS_TOKEN_MINUTES")
+	config.JWT.RefreshTokenHours = viper.GetInt("JWT_REFRESH_TOKEN_HOURS")
+	config.JWT.UserStateCacheSeconds = viper.GetInt("JWT_USER_STATE_CACHE_SECONDS")
+
+	config.TwoFactor.Issuer = viper.GetString("TWO_FACTOR_ISSUER")
+	config.TwoFactor.ChallengeMinutes = viper.GetInt("TWO_FACTOR_CHALLENGE_MINUTES")
+
+	config.OIDC.Issuer = viper.GetString("OIDC_ISSUER")
+	config.OIDC.ClientID = viper.GetString("OIDC_CLIENT_ID")
+	config.OIDC.ClientSecret = viper.GetString("OIDC_CLIENT_SECRET")
+	config.OIDC.RedirectURL = viper.GetString("OIDC_REDIRECT_URL")
+	config.OIDC.Scopes = strings.Fields(viper.GetString("OIDC_SCOPES"))
+	config.OIDC.RoleClaim = viper.GetString("OIDC_ROLE_CLAIM")
+	config.OIDC.AdminGroups = splitList(viper.GetString("OIDC_ADMIN_GROUPS"))
+	config.OIDC.CateringGroups = splitList(viper.GetString("OIDC_CATERING_GROUPS"))
+	config.OIDC.LoginMinutes = viper.GetInt("OIDC_LOGIN_MINUTES")
+
+	config.AuthProviders = splitList(viper.GetString("AUTH_PROVIDERS"))
+	config.LDAP.URL = viper.GetString("LDAP_URL")
+	config.LDAP.StartTLS = viper.GetBool("LDAP_START_TLS")
+	config.LDAP.InsecureSkipVerify = viper.GetBool("LDAP_INSECURE_SKIP_VERIFY")
+	config.LDAP.CACert = viper.GetString("LDAP_CA_CERT")
+	config.LDAP.BindDN = viper.GetString("LDAP_BIND_DN")
+	config.LDAP.BindPassword = viper.GetString("LDAP_BIND_PASSWORD")
+	config.LDAP.UserBaseDN = viper.GetString("LDAP_USER_BASE_DN")
+	config.LDAP.UserFilter = viper.GetString("LDAP_USER_FILTER")
+	config.LDAP.UsernameAttr = viper.GetString("LDAP_USERNAME_ATTR")
+	config.LDAP.EmailAttr = viper.GetString("LDAP_EMAIL_ATTR")
+	config.LDAP.GroupBaseDN = viper.GetString("LDAP_GROUP_BASE_DN")
+	config.LDAP.GroupFilter = viper.GetString("LDAP_GROUP_FILTER")
+	config.LDAP.AdminGroups = splitList(viper.GetString("LDAP_ADMIN_GROUPS"))
+	config.LDAP.CateringGroups = splitList(viper.GetString("LDAP_CATERING_GROUPS"))
+	config.LDAP.SyncMinutes = viper.GetInt("LDAP_SYNC_MINUTES")
+	config.LDAP.TimeoutSeconds = viper.GetInt("LDAP_TIMEOUT_SECONDS")
 
 	config.SMTP.Host = viper.GetString("SMTP_HOST")
 	config.SMTP.Port = viper.GetInt("SMTP_PORT")
@@ -163,6 +316,27 @@ func LoadConfig(path string) (*Config, error) {
 	config.CloudflareR2AccountID = viper.GetString("CLOUDFLARE_R2_ACCOUNT_ID")
 	config.CloudflareR2PublicURL = viper.GetString("CLOUDFLARE_R2_PUBLIC_URL")
 
+	config.Pricing.Timezone = viper.GetString("PRICING_TIMEZONE")
+	config.Pricing.MinBillableMinutes = viper.GetInt("PRICING_MIN_BILLABLE_MINUTES")
+	config.Pricing.BillingIncrementMinutes = viper.GetInt("PRICING_BILLING_INCREMENT_MINUTES")
+	config.Pricing.QuoteValidityMinutes = viper.GetInt("PRICING_QUOTE_VALIDITY_MINUTES")
+
+	config.Tax.Rate = viper.GetFloat64("TAX_RATE")
+	config.Tax.Label = viper.GetString("TAX_LABEL")
+
+	config.Payment.Provider = viper.GetString("PAYMENT_PROVIDER")
+	config.Payment.WebhookSecret = viper.GetString("PAYMENT_WEBHOOK_SECRET")
+	config.Payment.Currency = viper.GetString("PAYMENT_CURRENCY")
+
+	config.Snacks.MaxPerVisitor = viper.GetInt("SNACK_MAX_PER_VISITOR")
+	config.Catering.LeadTimeMinutes = viper.GetInt("CATERING_LEAD_TIME_MINUTES")
+	config.Storage.Driver = viper.GetString("STORAGE_DRIVER")
+	config.Storage.LocalDir = viper.GetString("STORAGE_LOCAL_DIR")
+	config.Storage.LocalURL = viper.GetString("STORAGE_LOCAL_URL")
+	config.Storage.S3Endpoint = viper.GetString("STORAGE_S3_ENDPOINT")
+	config.Storage.S3UseSSL = viper.GetBool("STORAGE_S3_USE_SSL")
+	config.Storage.MaxImageBytes = viper.GetInt("STORAGE_MAX_IMAGE_BYTES")
+
 	port, err := strconv.Atoi(strings.TrimSpace(config.AppPort))
 	if err != nil {
 		return nil, fmt.Errorf("invalid APP_PORT: %v", err)
@@ -172,6 +346,17 @@ func LoadConfig(path string) (*Config, error) {
 	return &config, nil
 }
 
+// splitList splits a comma separated setting, group names may contain spaces
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func NewConfig() *Config {
 	rootDir := findRootDir()
 	configPath := filepath.Join(rootDir, ".env")
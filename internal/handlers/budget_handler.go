@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type BudgetHandler struct {
+	service *services.BudgetService
+}
+
+func NewBudgetHandler(service *services.BudgetService) *BudgetHandler {
+	return &BudgetHandler{
+		service: service,
+	}
+}
+
+func (h *BudgetHandler) GetBudgets(c *fiber.Ctx) error {
+	response, err := h.service.GetBudgets(c.Query("month"))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid month") {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch budgets " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *BudgetHandler) SetBudget(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.SetBudgetRequest)
+
+	budget, err := h.service.SetBudget(&req)
+	if err != nil {
+		if err.Error() == "cost center not found" || strings.HasPrefix(err.Error(), "invalid month") {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to save budget " + err.Error(),
+		})
+	}
+
+	return c.JSON(budget)
+}
+
+func (h *BudgetHandler) DeleteBudget(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid budget ID " + err.Error(),
+		})
+	}
+
+	if err := h.service.DeleteBudget(id); err != nil {
+		if err.Error() == "budget not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to delete budget " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.SuccessResponse{
+		Message: "Budget deleted successfully",
+	})
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CancellationPolicyHandler struct {
+	service *services.CancellationPolicyService
+}
+
+func NewCancellationPolicyHandler(service *services.CancellationPolicyService) *CancellationPolicyHandler {
+	return &CancellationPolicyHandler{
+		service: service,
+	}
+}
+
+func (h *CancellationPolicyHandler) GetCancellationPolicies(c *fiber.Ctx) error {
+	response, err := h.service.GetCancellationPolicies()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch cancellation policies " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *CancellationPolicyHandler) CreateCancellationPolicy(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.CreateCancellationPolicyRequest)
+
+	policy, err := h.service.CreateCancellationPolicy(&req)
+	if err != nil {
+		switch err.Error() {
+		case "cancellation policy already exists for this room type":
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "room type not found":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to create cancellation policy " + err.Error(),
+			})
+		}
+	}
+
+	return c.Status(http.StatusCreated).JSON(policy)
+}
+
+func (h *CancellationPolicyHandler) UpdateCancellationPolicy(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid cancellation policy ID " + err.Error(),
+		})
+	}
+
+	req := c.Locals("request").(models.UpdateCancellationPolicyRequest)
+
+	policy, err := h.service.UpdateCancellationPolicy(id, &req)
+	if err != nil {
+		if err.Error() == "cancellation policy not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update cancellation policy " + err.Error(),
+		})
+	}
+
+	return c.JSON(policy)
+}
+
+func (h *CancellationPolicyHandler) DeleteCancellationPolicy(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid cancellation policy ID " + err.Error(),
+		})
+	}
+
+	if err := h.service.DeleteCancellationPolicy(id); err != nil {
+		if err.Error() == "cancellation policy not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to delete cancellation policy " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.SuccessResponse{
+		Message: "Cancellation policy deleted successfully",
+	})
+}
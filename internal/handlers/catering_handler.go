@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CateringHandler struct {
+	service *services.CateringService
+}
+
+func NewCateringHandler(service *services.CateringService) *CateringHandler {
+	return &CateringHandler{
+		service: service,
+	}
+}
+
+// GetQueue lists the snack orders of a day for the kitchen, filtered by location and status
+func (h *CateringHandler) GetQueue(c *fiber.Ctx) error {
+	var query models.CateringQueueQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid query parameters",
+		})
+	}
+
+	response, err := h.service.GetQueue(&query)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch catering queue " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *CateringHandler) UpdateStatus(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid catering order ID",
+		})
+	}
+
+	authUserID, _ := c.Locals("userID").(string)
+	userID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	req := c.Locals("request").(models.UpdateCateringStatusRequest)
+
+	order, err := h.service.UpdateStatus(orderID, userID, req.Status)
+	if err != nil {
+		switch {
+		case err.Error() == "catering order not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case err.Error() == "reservation was cancelled" || strings.HasPrefix(err.Error(), "invalid status transition"):
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to update catering order " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(order)
+}
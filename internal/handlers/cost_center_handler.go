@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CostCenterHandler struct {
+	service *services.CostCenterService
+}
+
+func NewCostCenterHandler(service *services.CostCenterService) *CostCenterHandler {
+	return &CostCenterHandler{
+		service: service,
+	}
+}
+
+func (h *CostCenterHandler) GetDepartments(c *fiber.Ctx) error {
+	response, err := h.service.GetDepartments()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch departments " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *CostCenterHandler) CreateDepartment(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.CreateDepartmentRequest)
+
+	department, err := h.service.CreateDepartment(&req)
+	if err != nil {
+		if err.Error() == "department already exists" {
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to create department " + err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(department)
+}
+
+func (h *CostCenterHandler) GetCostCenters(c *fiber.Ctx) error {
+	response, err := h.service.GetCostCenters()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch cost centers " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *CostCenterHandler) CreateCostCenter(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.CreateCostCenterRequest)
+
+	costCenter, err := h.service.CreateCostCenter(&req)
+	if err != nil {
+		switch err.Error() {
+		case "cost center code already exists":
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "department not found":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to create cost center " + err.Error(),
+			})
+		}
+	}
+
+	return c.Status(http.StatusCreated).JSON(costCenter)
+}
+
+func (h *CostCenterHandler) UpdateCostCenter(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid cost center ID " + err.Error(),
+		})
+	}
+
+	req := c.Locals("request").(models.UpdateCostCenterRequest)
+
+	costCenter, err := h.service.UpdateCostCenter(id, &req)
+	if err != nil {
+		switch err.Error() {
+		case "cost center not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "department not found":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to update cost center " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(costCenter)
+}
+
+func (h *CostCenterHandler) AssignUserCostCenter(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid user ID " + err.Error(),
+		})
+	}
+
+	var req models.AssignCostCenterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	if err := h.service.AssignUserCostCenter(userID, &req); err != nil {
+		switch err.Error() {
+		case "user not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "cost center not found or inactive":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to assign cost center " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(models.SuccessResponse{
+		Message: "Cost center assigned successfully",
+	})
+}
+
+// GetSpendReport returns the monthly spend per cost center as JSON, or as a CSV download with ?format=csv
+func (h *CostCenterHandler) GetSpendReport(c *fiber.Ctx) error {
+	var query models.CostCenterSpendQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid query parameters",
+		})
+	}
+	if query.Format != "" && query.Format != "json" && query.Format != "csv" {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid format: must be json or csv",
+		})
+	}
+
+	report, err := h.service.GetSpendReport(&query)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") || strings.HasPrefix(err.Error(), "end_month") {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch cost center spend " + err.Error(),
+		})
+	}
+
+	if query.Format == "csv" {
+		body, err := services.SpendReportCSV(report)
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to export cost center spend " + err.Error(),
+			})
+		}
+		c.Set(fiber.HeaderContentType, "text/csv")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="cost-center-spend-`+report.StartMonth+`-to-`+report.EndMonth+`.csv"`)
+		return c.Send(body)
+	}
+
+	return c.JSON(report)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type InvoiceHandler struct {
+	service *services.InvoiceService
+}
+
+func NewInvoiceHandler(service *services.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{
+		service: service,
+	}
+}
+
+// GetInvoice returns the invoice of a reservation as JSON, or as a PDF download
+// when requested with ?format=pdf or an Accept: application/pdf header
+func (h *InvoiceHandler) GetInvoice(c *fiber.Ctx) error {
+	reservationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid reservation ID",
+		})
+	}
+
+	format := c.Query("format")
+	if format == "" && c.Accepts(fiber.MIMEApplicationJSON, "application/pdf") == "application/pdf" {
+		format = "pdf"
+	}
+	if format != "" && format != "pdf" && format != "json" {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid format: must be pdf or json",
+		})
+	}
+
+	isAdmin, _ := c.Locals("isAdmin").(bool)
+	authUserID, _ := c.Locals("userID").(string)
+	userID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	invoice, err := h.service.GetInvoiceByReservation(reservationID, userID, isAdmin)
+	if err != nil {
+		switch err.Error() {
+		case "reservation not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "invoice is not available until the reservation is confirmed":
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to fetch invoice " + err.Error(),
+			})
+		}
+	}
+
+	if format == "pdf" {
+		c.Set(fiber.HeaderContentType, "application/pdf")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+invoice.InvoiceNumber+`.pdf"`)
+		return c.Send(h.service.RenderInvoicePDF(invoice))
+	}
+
+	return c.JSON(invoice)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"e_meeting/internal/images"
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type MediaHandler struct {
+	service *services.MediaService
+}
+
+func NewMediaHandler(service *services.MediaService) *MediaHandler {
+	return &MediaHandler{
+		service: service,
+	}
+}
+
+// readImageUpload reads the file of a multipart form field, refusing files above maxBytes
+func readImageUpload(c *fiber.Ctx, field string, maxBytes int) ([]byte, error) {
+	header, err := c.FormFile(field)
+	if err != nil {
+		return nil, fmt.Errorf("%s file is required", field)
+	}
+	if header.Size > int64(maxBytes) {
+		return nil, images.ErrTooLarge
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error reading upload: %v", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, int64(maxBytes)+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading upload: %v", err)
+	}
+	if len(data) > maxBytes {
+		return nil, images.ErrTooLarge
+	}
+	return data, nil
+}
+
+// imageUploadStatus returns the status of an upload validation error, or 0 for
+// errors that are not about the uploaded image
+func imageUploadStatus(err error) int {
+	switch {
+	case errors.Is(err, images.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, images.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, images.ErrInvalidImage), strings.HasSuffix(err.Error(), "file is required"):
+		return http.StatusBadRequest
+	}
+	return 0
+}
+
+func (h *MediaHandler) AddRoomPhoto(c *fiber.Ctx) error {
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid room ID " + err.Error(),
+		})
+	}
+
+	data, err := readImageUpload(c, "image", h.service.MaxImageBytes())
+	if err == nil {
+		var photo *models.RoomPhoto
+		photo, err = h.service.AddRoomPhoto(c.UserContext(), roomID, data)
+		if err == nil {
+			return c.Status(http.StatusCreated).JSON(photo)
+		}
+	}
+
+	if status := imageUploadStatus(err); status != 0 {
+		return c.Status(status).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	}
+	if err.Error() == "room not found" {
+		return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	}
+	if strings.HasPrefix(err.Error(), "a room can have at most") {
+		return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	}
+	return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+		Error: "Failed to upload room photo " + err.Error(),
+	})
+}
+
+func (h *MediaHandler) DeleteRoomPhoto(c *fiber.Ctx) error {
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid room ID " + err.Error(),
+		})
+	}
+	photoID, err := uuid.Parse(c.Params("photoId"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid photo ID " + err.Error(),
+		})
+	}
+
+	if err := h.service.DeleteRoomPhoto(c.UserContext(), roomID, photoID); err != nil {
+		if err.Error() == "room photo not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to delete room photo " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.SuccessResponse{
+		Message: "Room photo deleted successfully",
+	})
+}
+
+func (h *MediaHandler) SetSnackImage(c *fiber.Ctx) error {
+	snackID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid snack ID " + err.Error(),
+		})
+	}
+
+	data, err := readImageUpload(c, "image", h.service.MaxImageBytes())
+	if err == nil {
+		var snack *models.Snack
+		snack, err = h.service.SetSnackImage(c.UserContext(), snackID, data)
+		if err == nil {
+			return c.JSON(snack)
+		}
+	}
+
+	if status := imageUploadStatus(err); status != 0 {
+		return c.Status(status).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	}
+	if err.Error() == "snack not found" {
+		return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	}
+	return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+		Error: "Failed to upload snack image " + err.Error(),
+	})
+}
+
+func (h *MediaHandler) DeleteSnackImage(c *fiber.Ctx) error {
+	snackID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid snack ID " + err.Error(),
+		})
+	}
+
+	snack, err := h.service.DeleteSnackImage(c.UserContext(), snackID)
+	if err != nil {
+		if err.Error() == "snack not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to delete snack image " + err.Error(),
+		})
+	}
+
+	return c.JSON(snack)
+}
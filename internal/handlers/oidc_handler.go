@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"e_meeting/internal/auth"
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type OIDCHandler struct {
+	service *services.OIDCService // nil when single sign-on is not configured
+}
+
+func NewOIDCHandler(service *services.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		service: service,
+	}
+}
+
+// Login starts a single sign-on login and returns the identity provider URL to redirect the user to
+func (h *OIDCHandler) Login(c *fiber.Ctx) error {
+	if h.service == nil {
+		return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "single sign-on is not configured",
+		})
+	}
+
+	response, err := h.service.Begin(c.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to start single sign-on")
+		return c.Status(http.StatusBadGateway).JSON(models.ErrorResponse{
+			Error: "Failed to start single sign-on",
+		})
+	}
+
+	return c.JSON(response)
+}
+
+// Callback completes a single sign-on login when the identity provider redirects back
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	if h.service == nil {
+		return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "single sign-on is not configured",
+		})
+	}
+
+	var query models.OIDCCallbackQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid query parameters",
+		})
+	}
+	if query.Error != "" {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: strings.TrimSpace("single sign-on failed: " + query.Error + " " + query.ErrorDescription),
+		})
+	}
+	if query.Code == "" || query.State == "" {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "code and state are required",
+		})
+	}
+
+	response, err := h.service.Callback(c.Context(), query.State, query.Code, clientInfo(c))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to complete single sign-on")
+		switch {
+		case err.Error() == "invalid or expired login state":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case err == auth.ErrInvalidIDToken, err.Error() == "identity provider did not return an email address",
+			err.Error() == "email address is not verified by the identity provider":
+			return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case err.Error() == "account is deactivated":
+			return c.Status(http.StatusForbidden).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case strings.HasPrefix(err.Error(), "error exchanging authorization code"),
+			strings.HasPrefix(err.Error(), "error discovering OIDC provider"),
+			strings.HasPrefix(err.Error(), "error fetching signing keys"):
+			return c.Status(http.StatusBadGateway).JSON(models.ErrorResponse{
+				Error: "single sign-on failed, the identity provider could not be reached",
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to complete single sign-on",
+		})
+	}
+
+	return c.JSON(response)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/payments"
+	"e_meeting/internal/services"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PaymentHandler struct {
+	service *services.PaymentService
+}
+
+func NewPaymentHandler(service *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		service: service,
+	}
+}
+
+func (h *PaymentHandler) CreatePayment(c *fiber.Ctx) error {
+	reservationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid reservation ID",
+		})
+	}
+
+	authUserID, _ := c.Locals("userID").(string)
+	userID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	payment, err := h.service.CreatePayment(reservationID, userID)
+	if err != nil {
+		switch err.Error() {
+		case "reservation not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "reservation is not awaiting payment", "reservation is awaiting budget approval":
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "payments are not available":
+			return c.Status(http.StatusServiceUnavailable).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to create payment " + err.Error(),
+			})
+		}
+	}
+
+	return c.Status(http.StatusCreated).JSON(payment)
+}
+
+func (h *PaymentHandler) GetPayments(c *fiber.Ctx) error {
+	reservationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid reservation ID",
+		})
+	}
+
+	isAdmin, _ := c.Locals("isAdmin").(bool)
+	authUserID, _ := c.Locals("userID").(string)
+	userID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	response, err := h.service.GetPayments(reservationID, userID, isAdmin)
+	if err != nil {
+		if err.Error() == "reservation not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch payments " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+// Webhook receives payment status callbacks from the payment gateway
+func (h *PaymentHandler) Webhook(c *fiber.Ctx) error {
+	header := http.Header{}
+	for key, values := range c.GetReqHeaders() {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	err := h.service.HandleWebhook(c.Params("provider"), c.Body(), header)
+	if err != nil {
+		switch {
+		case errors.Is(err, payments.ErrInvalidSignature):
+			return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case err.Error() == "unknown payment provider", err.Error() == "payment not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case err.Error() == "payments are not available":
+			return c.Status(http.StatusServiceUnavailable).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case strings.HasPrefix(err.Error(), "invalid webhook payload"):
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			// Server errors make the gateway retry the delivery
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to process webhook " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(models.SuccessResponse{
+		Message: "Webhook processed successfully",
+	})
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PriceHistoryHandler struct {
+	service *services.PriceHistoryService
+}
+
+func NewPriceHistoryHandler(service *services.PriceHistoryService) *PriceHistoryHandler {
+	return &PriceHistoryHandler{
+		service: service,
+	}
+}
+
+func (h *PriceHistoryHandler) GetRoomPriceHistory(c *fiber.Ctx) error {
+	return getPriceHistory(c, "room", h.service.GetRoomPriceHistory)
+}
+
+func (h *PriceHistoryHandler) ScheduleRoomPrice(c *fiber.Ctx) error {
+	return schedulePrice(c, "room", h.service.ScheduleRoomPrice)
+}
+
+func (h *PriceHistoryHandler) CancelRoomPriceChange(c *fiber.Ctx) error {
+	return cancelPriceChange(c, "room", h.service.CancelRoomPriceChange)
+}
+
+func (h *PriceHistoryHandler) GetSnackPriceHistory(c *fiber.Ctx) error {
+	return getPriceHistory(c, "snack", h.service.GetSnackPriceHistory)
+}
+
+func (h *PriceHistoryHandler) ScheduleSnackPrice(c *fiber.Ctx) error {
+	return schedulePrice(c, "snack", h.service.ScheduleSnackPrice)
+}
+
+func (h *PriceHistoryHandler) CancelSnackPriceChange(c *fiber.Ctx) error {
+	return cancelPriceChange(c, "snack", h.service.CancelSnackPriceChange)
+}
+
+func getPriceHistory(c *fiber.Ctx, item string, fetch func(uuid.UUID) (*models.PriceHistoryResponse, error)) error {
+	itemID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid " + item + " ID " + err.Error(),
+		})
+	}
+
+	response, err := fetch(itemID)
+	if err != nil {
+		if err.Error() == item+" not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch price history " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func schedulePrice(c *fiber.Ctx, item string, schedule func(uuid.UUID, *models.SchedulePriceRequest, uuid.UUID) (*models.PriceHistoryResponse, error)) error {
+	itemID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid " + item + " ID " + err.Error(),
+		})
+	}
+
+	authUserID, _ := c.Locals("userID").(string)
+	userID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	req := c.Locals("request").(models.SchedulePriceRequest)
+
+	response, err := schedule(itemID, &req, userID)
+	if err != nil {
+		switch err.Error() {
+		case item + " not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "invalid effective_from, expected YYYY-MM-DD", "effective_from must not be in the past":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to schedule price " + err.Error(),
+			})
+		}
+	}
+
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+func cancelPriceChange(c *fiber.Ctx, item string, cancel func(uuid.UUID, uuid.UUID) error) error {
+	itemID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid " + item + " ID " + err.Error(),
+		})
+	}
+
+	changeID, err := uuid.Parse(c.Params("priceId"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid price change ID " + err.Error(),
+		})
+	}
+
+	if err := cancel(itemID, changeID); err != nil {
+		switch err.Error() {
+		case "price change not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "only scheduled price changes can be cancelled":
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to cancel price change " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(models.SuccessResponse{
+		Message: "Price change cancelled successfully",
+	})
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PricingHandler struct {
+	service *services.PricingService
+}
+
+func NewPricingHandler(service *services.PricingService) *PricingHandler {
+	return &PricingHandler{
+		service: service,
+	}
+}
+
+func (h *PricingHandler) GetPricingRules(c *fiber.Ctx) error {
+	response, err := h.service.GetPricingRules()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch pricing rules " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *PricingHandler) CreatePricingRule(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.CreatePricingRuleRequest)
+
+	rule, err := h.service.CreatePricingRule(&req)
+	if err != nil {
+		switch err.Error() {
+		case "pricing rule cannot target both a room and a room type",
+			"start_time and end_time must be provided together":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to create pricing rule " + err.Error(),
+			})
+		}
+	}
+
+	return c.Status(http.StatusCreated).JSON(rule)
+}
+
+func (h *PricingHandler) UpdatePricingRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid pricing rule ID " + err.Error(),
+		})
+	}
+
+	req := c.Locals("request").(models.UpdatePricingRuleRequest)
+
+	rule, err := h.service.UpdatePricingRule(id, &req)
+	if err != nil {
+		switch err.Error() {
+		case "pricing rule not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "start_time and end_time must be provided together":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to update pricing rule " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(rule)
+}
+
+func (h *PricingHandler) DeletePricingRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid pricing rule ID " + err.Error(),
+		})
+	}
+
+	if err := h.service.DeletePricingRule(id); err != nil {
+		if err.Error() == "pricing rule not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to delete pricing rule " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.SuccessResponse{
+		Message: "Pricing rule deleted successfully",
+	})
+}
+
+func (h *PricingHandler) GetHolidays(c *fiber.Ctx) error {
+	response, err := h.service.GetHolidays()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch holidays " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *PricingHandler) CreateHoliday(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.CreateHolidayRequest)
+
+	holiday, err := h.service.CreateHoliday(&req)
+	if err != nil {
+		if err.Error() == "holiday already exists for this date" {
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to create holiday " + err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(holiday)
+}
+
+func (h *PricingHandler) DeleteHoliday(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid holiday ID " + err.Error(),
+		})
+	}
+
+	if err := h.service.DeleteHoliday(id); err != nil {
+		if err.Error() == "holiday not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to delete holiday " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.SuccessResponse{
+		Message: "Holiday deleted successfully",
+	})
+}
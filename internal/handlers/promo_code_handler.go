@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PromoCodeHandler struct {
+	service *services.PromoCodeService
+}
+
+func NewPromoCodeHandler(service *services.PromoCodeService) *PromoCodeHandler {
+	return &PromoCodeHandler{
+		service: service,
+	}
+}
+
+func (h *PromoCodeHandler) GetPromoCodes(c *fiber.Ctx) error {
+	response, err := h.service.GetPromoCodes()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch promo codes " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *PromoCodeHandler) CreatePromoCode(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.CreatePromoCodeRequest)
+
+	promo, err := h.service.CreatePromoCode(&req)
+	if err != nil {
+		switch err.Error() {
+		case "promo code already exists":
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "percentage discount cannot exceed 100":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to create promo code " + err.Error(),
+			})
+		}
+	}
+
+	return c.Status(http.StatusCreated).JSON(promo)
+}
+
+func (h *PromoCodeHandler) UpdatePromoCode(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid promo code ID " + err.Error(),
+		})
+	}
+
+	req := c.Locals("request").(models.UpdatePromoCodeRequest)
+
+	promo, err := h.service.UpdatePromoCode(id, &req)
+	if err != nil {
+		switch err.Error() {
+		case "promo code not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "valid_until must be after valid_from":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to update promo code " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(promo)
+}
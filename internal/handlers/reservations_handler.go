@@ -3,6 +3,7 @@ package handlers
 import (
 	"e_meeting/internal/models"
 	"e_meeting/internal/services"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
@@ -69,6 +70,12 @@ func (h *ReservationHandler) UpdateReservationStatus(c *fiber.Ctx) error {
 				Error: "invalid status " + err.Error(),
 			})
 		}
+		if err.Error() == "reservation is already cancelled" || err.Error() == "completed reservations cannot be cancelled" ||
+			err.Error() == "reservation is awaiting budget approval" || err.Error() == "cancelled reservations cannot be reactivated" {
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 
 		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error: "Failed to update reservation status",
@@ -93,14 +100,34 @@ func (h *ReservationHandler) CalculateReservationCost(c *fiber.Ctx) error {
 		})
 	}
 
+	authUserID, _ := c.Locals("userID").(string)
+	req.UserID, _ = uuid.Parse(authUserID)
+
 	// Calculate costs
 	response, err := h.service.CalculateReservationCost(&req)
 	if err != nil {
+		var orderErr *services.SnackOrderError
+		if errors.As(err, &orderErr) {
+			return c.Status(http.StatusBadRequest).JSON(models.SnackOrderErrorResponse{
+				Error: "invalid snack order",
+				Lines: orderErr.Lines,
+			})
+		}
+		if strings.HasPrefix(err.Error(), "promo code") {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 		if err.Error() == "room not found or inactive" {
 			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
 				Error: err.Error(),
 			})
 		}
+		if err.Error() == "end time must be after start time" {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error: "Failed to calculate reservation cost",
 		})
@@ -150,9 +177,41 @@ func (h *ReservationHandler) CreateReservation(c *fiber.Ctx) error {
 		})
 	}
 
+	authUserID, _ := c.Locals("userID").(string)
+	req.UserID, _ = uuid.Parse(authUserID)
+
 	// Create reservation
 	response, err := h.service.CreateReservation(&req)
 	if err != nil {
+		if err.Error() == "quote not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if strings.HasPrefix(err.Error(), "quote") {
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		var orderErr *services.SnackOrderError
+		if errors.As(err, &orderErr) {
+			return c.Status(http.StatusBadRequest).JSON(models.SnackOrderErrorResponse{
+				Error: "invalid snack order",
+				Lines: orderErr.Lines,
+			})
+		}
+		if strings.HasPrefix(err.Error(), "insufficient stock") || strings.HasPrefix(err.Error(), "daily capacity") {
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if strings.HasPrefix(err.Error(), "promo code") || strings.HasPrefix(err.Error(), "budget exceeded") ||
+			strings.HasPrefix(err.Error(), "snack delivery time") || strings.HasPrefix(err.Error(), "snack orders must be placed") ||
+			err.Error() == "cost center not found or inactive" {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error: "Failed to create reservation " + err.Error(),
 		})
@@ -160,3 +219,77 @@ func (h *ReservationHandler) CreateReservation(c *fiber.Ctx) error {
 
 	return c.Status(http.StatusCreated).JSON(response)
 }
+
+func (h *ReservationHandler) CancelReservation(c *fiber.Ctx) error {
+	reservationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid reservation ID",
+		})
+	}
+
+	isAdmin, _ := c.Locals("isAdmin").(bool)
+	authUserID, _ := c.Locals("userID").(string)
+	userID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	response, err := h.service.CancelReservation(reservationID, userID, isAdmin)
+	if err != nil {
+		switch err.Error() {
+		case "reservation not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "reservation is already cancelled", "completed reservations cannot be cancelled":
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to cancel reservation " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(response)
+}
+
+func (h *ReservationHandler) DecideBudgetApproval(c *fiber.Ctx) error {
+	reservationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid reservation ID",
+		})
+	}
+
+	var req models.BudgetApprovalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	reservation, err := h.service.DecideBudgetApproval(reservationID, req.Approved)
+	if err != nil {
+		switch err.Error() {
+		case "reservation not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "reservation is not awaiting budget approval":
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to update budget approval " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(reservation)
+}
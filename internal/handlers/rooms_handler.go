@@ -169,3 +169,56 @@ func (h *RoomHandler) GetRoomSchedule(c *fiber.Ctx) error {
 
 	return c.JSON(response)
 }
+
+func (h *RoomHandler) CreateRoomType(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.CreateRoomTypeRequest)
+
+	roomType, err := h.service.CreateRoomType(&req)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to create room type " + err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(roomType)
+}
+
+func (h *RoomHandler) GetRoomTypes(c *fiber.Ctx) error {
+	response, err := h.service.GetRoomTypes()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch room types " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *RoomHandler) CreateLocation(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.CreateLocationRequest)
+
+	location, err := h.service.CreateLocation(&req)
+	if err != nil {
+		if err.Error() == "location already exists" {
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to create location " + err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(location)
+}
+
+func (h *RoomHandler) GetLocations(c *fiber.Ctx) error {
+	response, err := h.service.GetLocations()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch locations " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SessionHandler struct {
+	service *services.SessionService
+}
+
+func NewSessionHandler(service *services.SessionService) *SessionHandler {
+	return &SessionHandler{
+		service: service,
+	}
+}
+
+// GetSessions lists where the authenticated user is signed in
+func (h *SessionHandler) GetSessions(c *fiber.Ctx) error {
+	authUserID, _ := c.Locals("userID").(string)
+	userID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	sessionID, _ := c.Locals("sessionID").(string)
+	response, err := h.service.GetSessions(userID, sessionID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch sessions " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+// RevokeSession signs out one session of the authenticated user
+func (h *SessionHandler) RevokeSession(c *fiber.Ctx) error {
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid session ID",
+		})
+	}
+
+	authUserID, _ := c.Locals("userID").(string)
+	userID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	if err := h.service.RevokeSession(userID, sessionID); err != nil {
+		if err.Error() == "session not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to revoke session " + err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(models.SuccessResponse{
+		Message: "Session revoked successfully",
+	})
+}
+
+// GetUserSessions lists where a user is signed in, for admins
+func (h *SessionHandler) GetUserSessions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid user ID",
+		})
+	}
+
+	response, err := h.service.GetSessions(userID, "")
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch sessions " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+// SignOutUser forces a user out of every session
+func (h *SessionHandler) SignOutUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid user ID",
+		})
+	}
+
+	count, err := h.service.SignOutUser(userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to sign out user " + err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(models.SuccessResponse{
+		Message: fmt.Sprintf("User signed out of %d session(s)", count),
+	})
+}
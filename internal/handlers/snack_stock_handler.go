@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SnackStockHandler struct {
+	service *services.SnackStockService
+}
+
+func NewSnackStockHandler(service *services.SnackStockService) *SnackStockHandler {
+	return &SnackStockHandler{
+		service: service,
+	}
+}
+
+func (h *SnackStockHandler) Restock(c *fiber.Ctx) error {
+	snackID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid snack ID " + err.Error(),
+		})
+	}
+
+	authUserID, _ := c.Locals("userID").(string)
+	userID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	req := c.Locals("request").(models.RestockSnackRequest)
+
+	movement, err := h.service.Restock(snackID, userID, &req)
+	if err != nil {
+		if err.Error() == "snack not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to restock snack " + err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(movement)
+}
+
+func (h *SnackStockHandler) AdjustStock(c *fiber.Ctx) error {
+	snackID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid snack ID " + err.Error(),
+		})
+	}
+
+	authUserID, _ := c.Locals("userID").(string)
+	userID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	req := c.Locals("request").(models.AdjustStockRequest)
+
+	movement, err := h.service.AdjustStock(snackID, userID, &req)
+	if err != nil {
+		if err.Error() == "snack not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to adjust stock " + err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(movement)
+}
+
+func (h *SnackStockHandler) UpdateStockSettings(c *fiber.Ctx) error {
+	snackID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid snack ID " + err.Error(),
+		})
+	}
+
+	req := c.Locals("request").(models.UpdateStockSettingsRequest)
+
+	snack, err := h.service.UpdateStockSettings(snackID, &req)
+	if err != nil {
+		if err.Error() == "snack not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update stock settings " + err.Error(),
+		})
+	}
+
+	return c.JSON(snack)
+}
+
+func (h *SnackStockHandler) GetLowStock(c *fiber.Ctx) error {
+	response, err := h.service.GetLowStock()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch low stock alerts " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *SnackStockHandler) GetStockMovements(c *fiber.Ctx) error {
+	snackID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid snack ID " + err.Error(),
+		})
+	}
+
+	var query models.StockMovementQuery
+	if err := c.QueryParser(&query); err != nil || query.Limit < 0 || query.Limit > 500 {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid query parameters",
+		})
+	}
+
+	response, err := h.service.GetStockMovements(snackID, &query)
+	if err != nil {
+		if err.Error() == "snack not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch stock movements " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
@@ -4,9 +4,11 @@ import (
 	"e_meeting/internal/models"
 	"e_meeting/internal/services"
 	"net/http"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type SnackHandler struct {
@@ -21,15 +23,35 @@ func NewSnackHandler(service *services.SnackService, validator *validator.Valida
 	}
 }
 
+// GetSnacks lists the orderable snacks, optionally filtered by category, dietary tags and allergens.
+// With room_id only the snacks served at the room's location are listed.
 func (h *SnackHandler) GetSnacks(c *fiber.Ctx) error {
+	var query models.SnackListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid query parameters",
+		})
+	}
+	query.IncludeArchived = false
+
 	// Get snacks from service
-	response, err := h.service.GetSnacks()
+	response, err := h.service.GetSnacks(&query)
 	if err != nil {
 		if err.Error() == "snacks not found" {
 			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
 				Error: err.Error(),
 			})
 		}
+		if err.Error() == "room not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if strings.HasPrefix(err.Error(), "invalid ") {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error: "Failed to fetch snacks",
 		})
@@ -63,6 +85,11 @@ func (h *SnackHandler) CreateSnack(c *fiber.Ctx) error {
 	// Create snack
 	response, err := h.service.CreateSnack(&req)
 	if err != nil {
+		if err.Error() == "snack category not found" {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error: err.Error(),
 		})
@@ -70,3 +97,305 @@ func (h *SnackHandler) CreateSnack(c *fiber.Ctx) error {
 
 	return c.Status(http.StatusCreated).JSON(response)
 }
+
+// GetAllSnacks lists the catalogue for admins, including archived snacks when include_archived=true
+func (h *SnackHandler) GetAllSnacks(c *fiber.Ctx) error {
+	var query models.SnackListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid query parameters",
+		})
+	}
+
+	response, err := h.service.GetSnacks(&query)
+	if err != nil {
+		if err.Error() == "snacks not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if err.Error() == "room not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if strings.HasPrefix(err.Error(), "invalid ") {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch snacks",
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *SnackHandler) UpdateSnack(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid snack ID " + err.Error(),
+		})
+	}
+
+	req := c.Locals("request").(models.UpdateSnackRequest)
+
+	snack, err := h.service.UpdateSnack(id, &req)
+	if err != nil {
+		if err.Error() == "snack not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if err.Error() == "snack category not found" {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update snack " + err.Error(),
+		})
+	}
+
+	return c.JSON(snack)
+}
+
+func (h *SnackHandler) ArchiveSnack(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid snack ID " + err.Error(),
+		})
+	}
+
+	snack, err := h.service.ArchiveSnack(id)
+	if err != nil {
+		if err.Error() == "snack not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to archive snack " + err.Error(),
+		})
+	}
+
+	return c.JSON(snack)
+}
+
+func (h *SnackHandler) DeleteSnack(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid snack ID " + err.Error(),
+		})
+	}
+
+	if err := h.service.DeleteSnack(id); err != nil {
+		switch err.Error() {
+		case "snack not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "snack has been ordered and can only be archived":
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to delete snack " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(models.SuccessResponse{
+		Message: "Snack deleted successfully",
+	})
+}
+
+func (h *SnackHandler) GetSnackCategories(c *fiber.Ctx) error {
+	response, err := h.service.GetSnackCategories()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch snack categories " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *SnackHandler) CreateSnackCategory(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.CreateSnackCategoryRequest)
+
+	category, err := h.service.CreateSnackCategory(&req)
+	if err != nil {
+		switch err.Error() {
+		case "snack category name is required":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "snack category already exists":
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to create snack category " + err.Error(),
+			})
+		}
+	}
+
+	return c.Status(http.StatusCreated).JSON(category)
+}
+
+func (h *SnackHandler) UpdateSnackCategory(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid snack category ID " + err.Error(),
+		})
+	}
+
+	req := c.Locals("request").(models.UpdateSnackCategoryRequest)
+
+	category, err := h.service.UpdateSnackCategory(id, &req)
+	if err != nil {
+		switch err.Error() {
+		case "snack category not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "snack category name is required":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "snack category already exists":
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to update snack category " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(category)
+}
+
+func (h *SnackHandler) DeleteSnackCategory(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid snack category ID " + err.Error(),
+		})
+	}
+
+	if err := h.service.DeleteSnackCategory(id); err != nil {
+		switch err.Error() {
+		case "snack category not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "snack category has snacks":
+			return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to delete snack category " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(models.SuccessResponse{
+		Message: "Snack category deleted successfully",
+	})
+}
+
+func (h *SnackHandler) MergeSnackCategory(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid snack category ID " + err.Error(),
+		})
+	}
+
+	req := c.Locals("request").(models.MergeSnackCategoryRequest)
+
+	category, err := h.service.MergeSnackCategory(id, req.TargetID)
+	if err != nil {
+		switch err.Error() {
+		case "snack category not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "cannot merge a snack category into itself":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to merge snack category " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(category)
+}
+
+func (h *SnackHandler) GetLocationMenu(c *fiber.Ctx) error {
+	locationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid location ID " + err.Error(),
+		})
+	}
+
+	menu, err := h.service.GetLocationMenu(locationID)
+	if err != nil {
+		if err.Error() == "location not found" {
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch location menu " + err.Error(),
+		})
+	}
+
+	return c.JSON(menu)
+}
+
+func (h *SnackHandler) SetLocationMenu(c *fiber.Ctx) error {
+	locationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid location ID " + err.Error(),
+		})
+	}
+
+	req := c.Locals("request").(models.SetLocationMenuRequest)
+
+	menu, err := h.service.SetLocationMenu(locationID, req.SnackIDs)
+	if err != nil {
+		switch err.Error() {
+		case "location not found":
+			return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		case "snack not found":
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to update location menu " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(menu)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type TokenHandler struct {
+	service *services.TokenService
+}
+
+func NewTokenHandler(service *services.TokenService) *TokenHandler {
+	return &TokenHandler{
+		service: service,
+	}
+}
+
+// clientInfo describes the device a request comes from
+func clientInfo(c *fiber.Ctx) models.ClientInfo {
+	return models.ClientInfo{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	}
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token
+func (h *TokenHandler) Refresh(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.RefreshTokenRequest)
+
+	response, err := h.service.Refresh(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		switch err.Error() {
+		case "invalid refresh token", "refresh token has been revoked", "refresh token reuse detected",
+			"refresh token expired", "account is deactivated":
+			log.Warn().Err(err).Msg("Refresh token rejected")
+			return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to refresh token " + err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(response)
+}
+
+// Logout revokes the access token of the request and signs its session out
+func (h *TokenHandler) Logout(c *fiber.Ctx) error {
+	authUserID, _ := c.Locals("userID").(string)
+	userID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	var req models.LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: "Invalid request body",
+			})
+		}
+	}
+
+	sessionID, _ := c.Locals("sessionID").(string)
+	jti, _ := c.Locals("tokenID").(string)
+	expiresAt, _ := c.Locals("tokenExpiresAt").(time.Time)
+	if err := h.service.Logout(c.Context(), userID, sessionID, req.RefreshToken, jti, expiresAt); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to log out " + err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(models.SuccessResponse{
+		Message: "Logged out successfully",
+	})
+}
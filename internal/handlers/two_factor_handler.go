@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"e_meeting/internal/auth"
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type TwoFactorHandler struct {
+	service *services.TwoFactorService
+}
+
+func NewTwoFactorHandler(service *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		service: service,
+	}
+}
+
+// twoFactorError maps the errors of a 2FA change or login step to a response
+func twoFactorError(c *fiber.Ctx, err error, action string) error {
+	switch err.Error() {
+	case auth.ErrInvalidChallenge.Error(), "invalid two-factor code":
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	case "too many attempts, try again later":
+		return c.Status(http.StatusTooManyRequests).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	case "account is deactivated", "two-factor authentication is required for your role":
+		return c.Status(http.StatusForbidden).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	case "user not found":
+		return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	case "two-factor authentication is already enabled", "two-factor authentication is not enabled",
+		"two-factor setup has not been started":
+		return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	default:
+		log.Error().Err(err).Msg("Failed to " + action)
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to " + action + " " + err.Error(),
+		})
+	}
+}
+
+// currentUserID returns the ID of the authenticated user
+func currentUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	userID, _ := c.Locals("userID").(string)
+	return uuid.Parse(userID)
+}
+
+// VerifyLogin completes a login with the code of the user's authenticator or a recovery code
+func (h *TwoFactorHandler) VerifyLogin(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.TwoFactorLoginRequest)
+
+	response, err := h.service.VerifyLogin(c.Context(), req.ChallengeToken, req.Code, clientInfo(c))
+	if err != nil {
+		return twoFactorError(c, err, "verify login")
+	}
+
+	return c.JSON(response)
+}
+
+// SetupChallenge starts the enrolment of an authenticator a login asked for,
+// the login is then completed with a code of it
+func (h *TwoFactorHandler) SetupChallenge(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.ChallengeRequest)
+
+	response, err := h.service.SetupChallenge(c.Context(), req.ChallengeToken)
+	if err != nil {
+		return twoFactorError(c, err, "start two-factor setup")
+	}
+
+	return c.JSON(response)
+}
+
+// Setup starts the enrolment of an authenticator for the authenticated user
+func (h *TwoFactorHandler) Setup(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	response, err := h.service.Setup(userID)
+	if err != nil {
+		return twoFactorError(c, err, "start two-factor setup")
+	}
+
+	return c.JSON(response)
+}
+
+// Enable confirms the enrolment with a code and returns the recovery codes
+func (h *TwoFactorHandler) Enable(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.TwoFactorCodeRequest)
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	response, err := h.service.Enable(userID, req.Code)
+	if err != nil {
+		return twoFactorError(c, err, "enable two-factor authentication")
+	}
+
+	return c.JSON(response)
+}
+
+// Disable turns off 2FA, confirmed with a code or a recovery code
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.TwoFactorCodeRequest)
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	if err := h.service.Disable(userID, req.Code); err != nil {
+		return twoFactorError(c, err, "disable two-factor authentication")
+	}
+
+	return c.Status(http.StatusOK).JSON(models.SuccessResponse{
+		Message: "Two-factor authentication disabled successfully",
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the authenticated user
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.TwoFactorCodeRequest)
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	response, err := h.service.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		return twoFactorError(c, err, "regenerate recovery codes")
+	}
+
+	return c.JSON(response)
+}
+
+// ResetTwoFactor removes the authenticator of a user that lost it
+func (h *TwoFactorHandler) ResetTwoFactor(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid user ID",
+		})
+	}
+
+	if err := h.service.ResetTwoFactor(userID); err != nil {
+		return twoFactorError(c, err, "reset two-factor authentication")
+	}
+
+	return c.Status(http.StatusOK).JSON(models.SuccessResponse{
+		Message: "Two-factor authentication reset successfully",
+	})
+}
+
+// GetPolicies lists which roles must sign in with a second factor
+func (h *TwoFactorHandler) GetPolicies(c *fiber.Ctx) error {
+	response, err := h.service.GetPolicies()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch two-factor policies " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+// UpdatePolicy sets whether a role must sign in with a second factor
+func (h *TwoFactorHandler) UpdatePolicy(c *fiber.Ctx) error {
+	req := c.Locals("request").(models.UpdateTwoFactorPolicyRequest)
+
+	adminID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	policy, err := h.service.SetPolicy(c.Params("role"), *req.Required, adminID)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update two-factor policy " + err.Error(),
+		})
+	}
+
+	return c.JSON(policy)
+}
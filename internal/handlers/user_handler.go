@@ -5,6 +5,7 @@ import (
 	"e_meeting/internal/services"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
@@ -12,18 +13,21 @@ import (
 
 // UserHandler handles HTTP requests related to user management
 type UserHandler struct {
-	userService services.UserService // Service layer for user-related operations
+	userService    services.UserService // Service layer for user-related operations
+	maxAvatarBytes int                  // Largest accepted avatar upload
 }
 
 // NewUserHandler creates a new instance of UserHandler
 // Parameters:
 //   - userService: Service implementation for user operations
+//   - maxAvatarBytes: Largest accepted avatar upload in bytes
 //
 // Returns:
 //   - A pointer to the new UserHandler instance
-func NewUserHandler(userService services.UserService) *UserHandler {
+func NewUserHandler(userService services.UserService, maxAvatarBytes int) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:    userService,
+		maxAvatarBytes: maxAvatarBytes,
 	}
 }
 
@@ -61,18 +65,27 @@ func (h *UserHandler) Register(c *fiber.Ctx) error {
 func (h *UserHandler) Login(c *fiber.Ctx) error {
 	req := c.Locals("request").(models.LoginRequest)
 
-	token, userId, err := h.userService.Login(req)
+	response, err := h.userService.Login(req, clientInfo(c))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to login")
+		if err.Error() == "account is deactivated" {
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		if strings.HasPrefix(err.Error(), "error connecting to LDAP server") ||
+			strings.HasPrefix(err.Error(), "error starting TLS with LDAP server") ||
+			strings.HasPrefix(err.Error(), "error binding LDAP service account") {
+			return c.Status(fiber.StatusBadGateway).JSON(models.ErrorResponse{
+				Error: "login failed, the directory could not be reached",
+			})
+		}
 		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
 			Error: err.Error(),
 		})
 	}
 
-	return c.JSON(models.LoginResponse{
-		UserID: userId,
-		Token:  token,
-	})
+	return c.JSON(response)
 }
 
 // GetProfile handles requests to retrieve a user's profile
@@ -156,3 +169,48 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 
 	return c.Status(http.StatusOK).JSON(profile)
 }
+
+// UpdateAvatar handles profile picture uploads
+// It validates the image sent in the avatar form field, crops it to a square
+// and replaces the user's previous profile picture
+// Parameters:
+//   - c: The Fiber context containing the HTTP request and response
+//
+// Returns:
+//   - error: Any error that occurred during the upload
+func (h *UserHandler) UpdateAvatar(c *fiber.Ctx) error {
+	// Get authenticated user ID from context
+	authUserID, _ := c.Locals("userID").(string)
+	requestedID := c.Params("id")
+
+	// Users can only change their own profile picture
+	if authUserID != requestedID {
+		return c.Status(http.StatusForbidden).JSON(models.ErrorResponse{
+			Error: "Forbidden",
+		})
+	}
+
+	data, err := readImageUpload(c, "avatar", h.maxAvatarBytes)
+	if err == nil {
+		var profile *models.UserProfileResponse
+		profile, err = h.userService.UpdateAvatar(c.UserContext(), requestedID, data)
+		if err == nil {
+			return c.Status(http.StatusOK).JSON(profile)
+		}
+	}
+
+	if status := imageUploadStatus(err); status != 0 {
+		return c.Status(status).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	}
+	if err.Error() == "user not found" {
+		return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "user not found",
+		})
+	}
+	log.Error().Err(err).Msg("Failed to update avatar")
+	return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+		Error: "Failed to update profile picture",
+	})
+}
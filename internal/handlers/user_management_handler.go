@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"e_meeting/internal/models"
+	"e_meeting/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type UserManagementHandler struct {
+	service              *services.UserManagementService
+	passwordResetService *services.PasswordResetService
+}
+
+func NewUserManagementHandler(service *services.UserManagementService, passwordResetService *services.PasswordResetService) *UserManagementHandler {
+	return &UserManagementHandler{
+		service:              service,
+		passwordResetService: passwordResetService,
+	}
+}
+
+// userManagementError maps the errors of a user account change to a response
+func userManagementError(c *fiber.Ctx, err error, action string) error {
+	switch err.Error() {
+	case "user not found":
+		return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	case "cannot remove the last active admin", "admins cannot remove their own access":
+		return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	default:
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to " + action + " " + err.Error(),
+		})
+	}
+}
+
+// GetUsers searches user accounts by username or email with role and status filters
+func (h *UserManagementHandler) GetUsers(c *fiber.Ctx) error {
+	var query models.AdminUserQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid query parameters",
+		})
+	}
+
+	response, err := h.service.GetUsers(&query)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch users " + err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+func (h *UserManagementHandler) GetUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid user ID",
+		})
+	}
+
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		return userManagementError(c, err, "fetch user")
+	}
+
+	return c.JSON(user)
+}
+
+func (h *UserManagementHandler) UpdateRole(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid user ID",
+		})
+	}
+
+	authUserID, _ := c.Locals("userID").(string)
+	actorID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	req := c.Locals("request").(models.UpdateUserRoleRequest)
+
+	user, err := h.service.UpdateRole(userID, actorID, req.Role)
+	if err != nil {
+		return userManagementError(c, err, "update user role")
+	}
+
+	return c.JSON(user)
+}
+
+func (h *UserManagementHandler) UpdateStatus(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid user ID",
+		})
+	}
+
+	authUserID, _ := c.Locals("userID").(string)
+	actorID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	req := c.Locals("request").(models.UpdateUserStatusRequest)
+
+	user, err := h.service.UpdateStatus(userID, actorID, *req.Active)
+	if err != nil {
+		return userManagementError(c, err, "update user status")
+	}
+
+	return c.JSON(user)
+}
+
+func (h *UserManagementHandler) DeleteUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid user ID",
+		})
+	}
+
+	authUserID, _ := c.Locals("userID").(string)
+	actorID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	if err := h.service.DeleteUser(userID, actorID); err != nil {
+		return userManagementError(c, err, "delete user")
+	}
+
+	return c.Status(http.StatusOK).JSON(models.SuccessResponse{
+		Message: "User deleted successfully",
+	})
+}
+
+func (h *UserManagementHandler) RestoreUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid user ID",
+		})
+	}
+
+	authUserID, _ := c.Locals("userID").(string)
+	actorID, err := uuid.Parse(authUserID)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error: "Invalid user ID in token",
+		})
+	}
+
+	user, err := h.service.RestoreUser(userID, actorID)
+	if err != nil {
+		return userManagementError(c, err, "restore user")
+	}
+
+	return c.JSON(user)
+}
+
+// ResetPassword emails the user a password reset link. Admins never choose or see the new password.
+func (h *UserManagementHandler) ResetPassword(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid user ID",
+		})
+	}
+
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		return userManagementError(c, err, "fetch user")
+	}
+	if user.DeletedAt != nil {
+		return c.Status(http.StatusConflict).JSON(models.ErrorResponse{
+			Error: "user is deleted",
+		})
+	}
+
+	if _, err := h.passwordResetService.RequestReset(c.Context(), user.Email, c); err != nil {
+		log.Error().Err(err).Msg("Failed to send password reset link")
+		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to send password reset link",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(models.SuccessResponse{
+		Message: "Password reset link sent to " + user.Email,
+	})
+}
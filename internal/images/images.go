@@ -0,0 +1,118 @@
+// Package images validates uploaded images and produces resized copies.
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // Register the PNG decoder
+	"net/http"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Register the WebP decoder
+)
+
+var (
+	// ErrTooLarge is returned for uploads above the size limit
+	ErrTooLarge = errors.New("image is too large")
+	// ErrUnsupportedType is returned for uploads that are not JPEG, PNG or WebP images
+	ErrUnsupportedType = errors.New("unsupported image type, expected JPEG, PNG or WebP")
+	// ErrInvalidImage is returned for uploads that cannot be decoded
+	ErrInvalidImage = errors.New("invalid image")
+)
+
+// maxPixels bounds the decoded size of an image so a small file cannot
+// expand into a huge bitmap
+const maxPixels = 40_000_000
+
+// ContentType is the content type of the images produced by Encode
+const ContentType = "image/jpeg"
+
+// contentTypes lists the accepted formats by sniffed content type
+var contentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// Decode checks the size and type of an uploaded image and decodes it. The type is
+// sniffed from the data; the content type sent by the client is not trusted.
+func Decode(data []byte, maxBytes int) (image.Image, error) {
+	if maxBytes > 0 && len(data) > maxBytes {
+		return nil, ErrTooLarge
+	}
+	if !contentTypes[http.DetectContentType(data)] {
+		return nil, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", ErrTooLarge, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	return img, nil
+}
+
+// Fit scales an image down to fit within width x height, keeping its aspect ratio.
+// Images that already fit are returned unchanged.
+func Fit(img image.Image, width, height int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= width && h <= height {
+		return img
+	}
+	scale := min(float64(width)/float64(w), float64(height)/float64(h))
+	newW := max(1, int(float64(w)*scale+0.5))
+	newH := max(1, int(float64(h)*scale+0.5))
+
+	dst := image.NewRGBA(image.Rect(0, 0, newW, newH))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, xdraw.Over, nil)
+	return dst
+}
+
+// Fill scales and center crops an image to exactly width x height
+func Fill(img image.Image, width, height int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	// Largest centered crop with the target aspect ratio
+	crop := bounds
+	if w*height > h*width {
+		cropW := h * width / height
+		crop.Min.X += (w - cropW) / 2
+		crop.Max.X = crop.Min.X + cropW
+	} else {
+		cropH := w * height / width
+		crop.Min.Y += (h - cropH) / 2
+		crop.Max.Y = crop.Min.Y + cropH
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, xdraw.Over, nil)
+	return dst
+}
+
+// Encode encodes an image as JPEG. Transparent areas become white.
+// Metadata of the upload, such as EXIF location, is not carried over.
+func Encode(img image.Image) ([]byte, error) {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, fmt.Errorf("error encoding image: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,69 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pngOf(t *testing.T, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.NRGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	data := pngOf(t, 40, 20)
+
+	img, err := Decode(data, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 40, img.Bounds().Dx())
+
+	_, err = Decode(data, len(data)-1)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = Decode([]byte("GIF89a not really"), 0)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = Decode([]byte("<html><body>hello</body></html>"), 0)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	// A PNG signature followed by garbage
+	_, err = Decode(append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...), 0)
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestFit(t *testing.T) {
+	img, err := Decode(pngOf(t, 800, 400), 0)
+	require.NoError(t, err)
+
+	fitted := Fit(img, 200, 200)
+	assert.Equal(t, image.Rect(0, 0, 200, 100), fitted.Bounds())
+
+	// Smaller images are not enlarged
+	assert.Equal(t, img, Fit(img, 1000, 1000))
+}
+
+func TestFillAndEncode(t *testing.T) {
+	img, err := Decode(pngOf(t, 300, 100), 0)
+	require.NoError(t, err)
+
+	filled := Fill(img, 64, 64)
+	assert.Equal(t, image.Rect(0, 0, 64, 64), filled.Bounds())
+
+	encoded, err := Encode(filled)
+	require.NoError(t, err)
+	decoded, format, err := image.Decode(bytes.NewReader(encoded))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, filled.Bounds(), decoded.Bounds())
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"e_meeting/internal/auth"
 	"net/http"
 	"strings"
 
@@ -9,8 +10,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// AdminOnlyMiddleware ensures that only users with admin role can access the protected routes
-func AdminOnlyMiddleware(secretKey string) fiber.Handler {
+// AdminOnlyMiddleware ensures that only users with admin role can access the protected routes.
+// The token must also pass every check, such as the account state, the session and the denylist.
+func AdminOnlyMiddleware(secretKey string, checks ...auth.TokenCheck) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get the Authorization header
 		authHeader := c.Get("Authorization")
@@ -58,7 +60,14 @@ func AdminOnlyMiddleware(secretKey string) fiber.Handler {
 			})
 		}
 
+		// Check the token was not revoked
+		if err := runTokenChecks(c, checks, claims); err != nil {
+			return tokenCheckError(c, err)
+		}
+		userID, _ := claims["user_id"].(string)
+
 		// If everything is ok, proceed
+		c.Locals("userID", userID)
 		c.Locals("isAdmin", true)
 		return c.Next()
 	}
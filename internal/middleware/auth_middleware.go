@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"e_meeting/internal/auth"
+	"errors"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,7 +10,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func AuthMiddleware(jwtConfig *auth.JWTConfig) fiber.Handler {
+// AuthMiddleware accepts valid tokens that pass every check, such as the
+// account state, the session and the denylist
+func AuthMiddleware(jwtConfig *auth.JWTConfig, checks ...auth.TokenCheck) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -51,8 +54,62 @@ func AuthMiddleware(jwtConfig *auth.JWTConfig) fiber.Handler {
 			})
 		}
 
+		if err := runTokenChecks(c, checks, claims); err != nil {
+			return tokenCheckError(c, err)
+		}
+
+		role, _ := claims["role"].(string)
+		jti, _ := claims["jti"].(string)
+		sessionID, _ := claims["sid"].(string)
 		c.Locals("userID", userID)
-		c.Locals("isAdmin", claims["role"] == "admin")
+		c.Locals("tokenID", jti)
+		c.Locals("sessionID", sessionID)
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			c.Locals("tokenExpiresAt", exp.Time)
+		}
+		c.Locals("role", role)
+		c.Locals("isAdmin", role == "admin")
 		return c.Next()
 	}
 }
+
+// RequireRoles only lets users with one of the given roles through.
+// It must run after AuthMiddleware.
+func RequireRoles(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+		for _, allowed := range roles {
+			if role == allowed {
+				return c.Next()
+			}
+		}
+		log.Warn().Str("role", role).Msg("Role not allowed")
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "access forbidden",
+		})
+	}
+}
+
+// runTokenChecks runs the checks of a token in order and returns the first failure
+func runTokenChecks(c *fiber.Ctx, checks []auth.TokenCheck, claims jwt.MapClaims) error {
+	for _, check := range checks {
+		if err := check(c.Context(), claims); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tokenCheckError answers a request whose token failed a check
+func tokenCheckError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, auth.ErrAccountInactive) || errors.Is(err, auth.ErrTokenRevoked) || errors.Is(err, auth.ErrSessionRevoked) {
+		log.Warn().Err(err).Msg("Token rejected")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	log.Error().Err(err).Msg("Failed to check token")
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "failed to verify token",
+	})
+}
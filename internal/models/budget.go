@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BudgetEnforcement string
+
+const (
+	BudgetEnforcementBlock    BudgetEnforcement = "block"    // Bookings over budget are rejected
+	BudgetEnforcementApproval BudgetEnforcement = "approval" // Bookings over budget wait for admin approval
+)
+
+type ApprovalStatus string
+
+const (
+	ApprovalRequired ApprovalStatus = "required"
+	ApprovalApproved ApprovalStatus = "approved"
+	ApprovalRejected ApprovalStatus = "rejected"
+)
+
+type Budget struct {
+	ID             uuid.UUID         `json:"id"`
+	CostCenterID   uuid.UUID         `json:"cost_center_id"`
+	CostCenterCode string            `json:"cost_center_code"`
+	CostCenterName string            `json:"cost_center_name"`
+	Month          string            `json:"month"` // YYYY-MM
+	Amount         float64           `json:"amount"`
+	Enforcement    BudgetEnforcement `json:"enforcement"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// SetBudgetRequest creates or replaces the budget of a cost center for a month
+type SetBudgetRequest struct {
+	CostCenterID uuid.UUID         `json:"cost_center_id" validate:"required"`
+	Month        string            `json:"month" validate:"required,datetime=2006-01"`
+	Amount       float64           `json:"amount" validate:"required,gt=0"`
+	Enforcement  BudgetEnforcement `json:"enforcement" validate:"required,oneof=block approval"`
+}
+
+type BudgetListResponse struct {
+	Budgets []Budget `json:"budgets"`
+}
+
+// BudgetConsumption shows how much of a monthly budget is used.
+// Spent counts reservations that already took place and cancellation fees;
+// the forecast adds confirmed reservations later in the month.
+type BudgetConsumption struct {
+	BudgetID           uuid.UUID `json:"budget_id"`
+	CostCenterID       uuid.UUID `json:"cost_center_id"`
+	CostCenterCode     string    `json:"cost_center_code"`
+	CostCenterName     string    `json:"cost_center_name"`
+	Month              string    `json:"month"`
+	Budget             float64   `json:"budget"`
+	Spent              float64   `json:"spent"`
+	ConfirmedUpcoming  float64   `json:"confirmed_upcoming"`
+	Forecast           float64   `json:"forecast"`
+	Remaining          float64   `json:"remaining"`
+	ConsumptionPercent float64   `json:"consumption_percent"`
+	ForecastPercent    float64   `json:"forecast_percent"`
+}
+
+type BudgetApprovalRequest struct {
+	Approved bool `json:"approved"`
+}
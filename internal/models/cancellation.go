@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LedgerEntryType string
+
+const (
+	LedgerCancellationFee LedgerEntryType = "cancellation_fee"
+	LedgerRefund          LedgerEntryType = "refund"
+)
+
+// CancellationPolicy sets the fee charged when a reservation is cancelled.
+// A policy without a room type applies to rooms whose type has no policy.
+type CancellationPolicy struct {
+	ID                         uuid.UUID  `json:"id"`
+	RoomTypeID                 *uuid.UUID `json:"room_type_id,omitempty"`
+	Name                       string     `json:"name"`
+	FreeCancellationHours      int        `json:"free_cancellation_hours"`       // Free until this many hours before start
+	LateCancellationFeePercent float64    `json:"late_cancellation_fee_percent"` // Fee after the free window, in percent of the price
+	NoShowFeePercent           float64    `json:"no_show_fee_percent"`           // Fee once the reservation has started
+	CreatedAt                  time.Time  `json:"created_at"`
+	UpdatedAt                  time.Time  `json:"updated_at"`
+}
+
+type CreateCancellationPolicyRequest struct {
+	RoomTypeID                 *uuid.UUID `json:"room_type_id,omitempty"`
+	Name                       string     `json:"name" validate:"required,max=100"`
+	FreeCancellationHours      int        `json:"free_cancellation_hours" validate:"gte=0"`
+	LateCancellationFeePercent float64    `json:"late_cancellation_fee_percent" validate:"gte=0,lte=100"`
+	NoShowFeePercent           *float64   `json:"no_show_fee_percent,omitempty" validate:"omitempty,gte=0,lte=100"` // Defaults to 100
+}
+
+type UpdateCancellationPolicyRequest struct {
+	Name                       *string  `json:"name,omitempty" validate:"omitempty,max=100"`
+	FreeCancellationHours      *int     `json:"free_cancellation_hours,omitempty" validate:"omitempty,gte=0"`
+	LateCancellationFeePercent *float64 `json:"late_cancellation_fee_percent,omitempty" validate:"omitempty,gte=0,lte=100"`
+	NoShowFeePercent           *float64 `json:"no_show_fee_percent,omitempty" validate:"omitempty,gte=0,lte=100"`
+}
+
+type CancellationPolicyListResponse struct {
+	Policies []CancellationPolicy `json:"policies"`
+}
+
+type LedgerEntry struct {
+	ID          uuid.UUID       `json:"id"`
+	EntryType   LedgerEntryType `json:"entry_type"`
+	Amount      float64         `json:"amount"`
+	Description string          `json:"description"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type CancelReservationResponse struct {
+	ReservationID   uuid.UUID     `json:"reservation_id"`
+	Status          string        `json:"status"`
+	PolicyName      string        `json:"policy_name,omitempty"`
+	CancellationFee float64       `json:"cancellation_fee"`
+	AmountPaid      float64       `json:"amount_paid"`
+	RefundAmount    float64       `json:"refund_amount"`
+	Ledger          []LedgerEntry `json:"ledger"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const RoleCatering = "catering"
+
+type CateringStatus string
+
+const (
+	CateringQueued    CateringStatus = "queued"
+	CateringPreparing CateringStatus = "preparing"
+	CateringDelivered CateringStatus = "delivered"
+)
+
+// CateringQueueQuery selects the kitchen queue of one day (YYYY-MM-DD, today by default)
+type CateringQueueQuery struct {
+	Date       string `query:"date"`
+	LocationID string `query:"location_id"`
+	Status     string `query:"status"`
+}
+
+// CateringItem is one snack to prepare for a catering order
+type CateringItem struct {
+	SnackID     uuid.UUID `json:"snack_id"`
+	Name        string    `json:"name"`
+	Category    string    `json:"category"`
+	Quantity    int       `json:"quantity"`
+	DietaryTags []string  `json:"dietary_tags"`
+	Allergens   []string  `json:"allergens"`
+}
+
+// CateringOrder is the kitchen view of the snacks of a reservation
+type CateringOrder struct {
+	ID              uuid.UUID      `json:"id"`
+	ReservationID   uuid.UUID      `json:"reservation_id"`
+	DeliverAt       time.Time      `json:"deliver_at"`
+	MeetingStart    time.Time      `json:"meeting_start"`
+	Status          CateringStatus `json:"status"`
+	Notes           *string        `json:"notes,omitempty"`
+	RoomID          uuid.UUID      `json:"room_id"`
+	RoomName        string         `json:"room_name"`
+	LocationID      *uuid.UUID     `json:"location_id,omitempty"`
+	LocationName    *string        `json:"location_name,omitempty"`
+	VisitorCount    int            `json:"visitor_count"`
+	Items           []CateringItem `json:"items"`
+	StatusUpdatedAt *time.Time     `json:"status_updated_at,omitempty"`
+}
+
+type CateringQueueResponse struct {
+	Date   string          `json:"date"`
+	Orders []CateringOrder `json:"orders"`
+}
+
+type UpdateCateringStatusRequest struct {
+	Status CateringStatus `json:"status" validate:"required,oneof=queued preparing delivered"`
+}
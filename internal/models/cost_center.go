@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Department struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateDepartmentRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type DepartmentListResponse struct {
+	Departments []Department `json:"departments"`
+}
+
+type CostCenter struct {
+	ID             uuid.UUID `json:"id"`
+	DepartmentID   uuid.UUID `json:"department_id"`
+	DepartmentName string    `json:"department_name"`
+	Code           string    `json:"code"`
+	Name           string    `json:"name"`
+	Active         bool      `json:"active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type CreateCostCenterRequest struct {
+	DepartmentID uuid.UUID `json:"department_id" validate:"required"`
+	Code         string    `json:"code" validate:"required,max=30"`
+	Name         string    `json:"name" validate:"required,max=100"`
+}
+
+type UpdateCostCenterRequest struct {
+	DepartmentID *uuid.UUID `json:"department_id,omitempty"`
+	Name         *string    `json:"name,omitempty" validate:"omitempty,max=100"`
+	Active       *bool      `json:"active,omitempty"`
+}
+
+type CostCenterListResponse struct {
+	CostCenters []CostCenter `json:"cost_centers"`
+}
+
+// AssignCostCenterRequest sets the default cost center of a user, null clears it
+type AssignCostCenterRequest struct {
+	CostCenterID *uuid.UUID `json:"cost_center_id"`
+}
+
+// CostCenterRef identifies the cost center charged for a reservation
+type CostCenterRef struct {
+	ID   uuid.UUID `json:"id"`
+	Code string    `json:"code"`
+	Name string    `json:"name"`
+}
+
+type CostCenterSpendQuery struct {
+	StartMonth string `query:"start_month"` // Format: YYYY-MM
+	EndMonth   string `query:"end_month"`   // Format: YYYY-MM
+	Format     string `query:"format"`      // json or csv
+}
+
+// CostCenterSpend is the spend of one cost center in one month.
+// Reservations without a cost center are reported with an empty code.
+type CostCenterSpend struct {
+	Month            string     `json:"month"` // YYYY-MM
+	CostCenterID     *uuid.UUID `json:"cost_center_id,omitempty"`
+	CostCenterCode   string     `json:"cost_center_code"`
+	CostCenterName   string     `json:"cost_center_name"`
+	Department       string     `json:"department"`
+	Reservations     int        `json:"reservations"`
+	RoomSpend        float64    `json:"room_spend"`
+	SnackSpend       float64    `json:"snack_spend"`
+	Discounts        float64    `json:"discounts"`
+	Tax              float64    `json:"tax"`
+	CancellationFees float64    `json:"cancellation_fees"`
+	Total            float64    `json:"total"`
+}
+
+type CostCenterSpendResponse struct {
+	StartMonth string            `json:"start_month"`
+	EndMonth   string            `json:"end_month"`
+	Rows       []CostCenterSpend `json:"rows"`
+}
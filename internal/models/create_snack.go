@@ -7,15 +7,32 @@ import (
 )
 
 type CreateSnackRequest struct {
-	Name     string  `json:"name" validate:"required"`
-	Category string  `json:"category" validate:"required"`
-	Price    float64 `json:"price" validate:"required,gt=0"`
+	Name        string    `json:"name" validate:"required"`
+	CategoryID  uuid.UUID `json:"category_id" validate:"required"`
+	Price       float64   `json:"price" validate:"required,gt=0"`
+	DietaryTags []string  `json:"dietary_tags,omitempty" validate:"omitempty,dive,oneof=vegetarian vegan halal gluten_free"`
+	Allergens   []string  `json:"allergens,omitempty" validate:"omitempty,dive,min=1,max=50"`
 }
 
 type CreateSnackResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Category  string    `json:"category"`
-	Price     float64   `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	CategoryID  uuid.UUID `json:"category_id"`
+	Category    string    `json:"category"`
+	Price       float64   `json:"price"`
+	DietaryTags []string  `json:"dietary_tags"`
+	Allergens   []string  `json:"allergens"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UpdateSnackRequest changes the provided fields of a snack. Setting active to
+// false archives the snack, setting it to true restores it.
+// Dietary tags and allergens replace the stored lists when provided.
+type UpdateSnackRequest struct {
+	Name        *string    `json:"name,omitempty" validate:"omitempty,min=1"`
+	CategoryID  *uuid.UUID `json:"category_id,omitempty"`
+	Price       *float64   `json:"price,omitempty" validate:"omitempty,gt=0"`
+	Active      *bool      `json:"active,omitempty"`
+	DietaryTags *[]string  `json:"dietary_tags,omitempty" validate:"omitempty,dive,oneof=vegetarian vegan halal gluten_free"`
+	Allergens   *[]string  `json:"allergens,omitempty" validate:"omitempty,dive,min=1,max=50"`
 }
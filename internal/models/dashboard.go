@@ -3,22 +3,34 @@ package models
 import "time"
 
 type RoomStats struct {
-	RoomID        string  `json:"room_id"`
-	RoomName      string  `json:"room_name"`
-	TotalBookings int     `json:"total_bookings"`
-	TotalHours    float64 `json:"total_hours"`
-	Occupancy     float64 `json:"occupancy_rate"` // Percentage of time room was occupied
-	Revenue       float64 `json:"revenue"`
+	RoomID           string  `json:"room_id"`
+	RoomName         string  `json:"room_name"`
+	TotalBookings    int     `json:"total_bookings"`
+	TotalHours       float64 `json:"total_hours"`
+	Occupancy        float64 `json:"occupancy_rate"` // Percentage of time room was occupied
+	RoomRevenue      float64 `json:"room_revenue"`
+	SnackRevenue     float64 `json:"snack_revenue"`
+	Discounts        float64 `json:"discounts"`
+	Tax              float64 `json:"tax"`
+	CancellationFees float64 `json:"cancellation_fees"`
+	Revenue          float64 `json:"revenue"` // Grand total including tax and cancellation fees
 }
 
 type DashboardResponse struct {
-	StartDate    time.Time   `json:"start_date"`
-	EndDate      time.Time   `json:"end_date"`
-	TotalOmzet   float64     `json:"total_omzet"`
-	Reservations int         `json:"total_reservations"`
-	Visitors     int         `json:"total_visitors"`
-	TotalRooms   int         `json:"total_rooms"`
-	RoomStats    []RoomStats `json:"room_stats"`
+	StartDate        time.Time           `json:"start_date"`
+	EndDate          time.Time           `json:"end_date"`
+	TotalOmzet       float64             `json:"total_omzet"` // Includes cancellation fees
+	RoomRevenue      float64             `json:"room_revenue"`
+	SnackRevenue     float64             `json:"snack_revenue"`
+	Discounts        float64             `json:"discounts"`
+	TotalTax         float64             `json:"total_tax"`
+	CancellationFees float64             `json:"cancellation_fees"`
+	Refunds          float64             `json:"refunds"`
+	Reservations     int                 `json:"total_reservations"`
+	Visitors         int                 `json:"total_visitors"`
+	TotalRooms       int                 `json:"total_rooms"`
+	RoomStats        []RoomStats         `json:"room_stats"`
+	Budgets          []BudgetConsumption `json:"budgets"`
 }
 
 type DashboardQuery struct {
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InvoiceLineKind string
+
+const (
+	InvoiceLineRoom     InvoiceLineKind = "room"
+	InvoiceLineSnack    InvoiceLineKind = "snack"
+	InvoiceLineDiscount InvoiceLineKind = "discount"
+)
+
+type InvoiceLine struct {
+	Kind        InvoiceLineKind `json:"kind"`
+	Description string          `json:"description"`
+	Quantity    float64         `json:"quantity"`
+	UnitPrice   float64         `json:"unit_price"`
+	Amount      float64         `json:"amount"`
+}
+
+// Invoice is issued once per reservation when it is confirmed or completed
+type Invoice struct {
+	ID            uuid.UUID `json:"id"`
+	InvoiceNumber string    `json:"invoice_number"` // INV-YYYY-NNNNNN, sequential per year
+	ReservationID uuid.UUID `json:"reservation_id"`
+	IssuedAt      time.Time `json:"issued_at"`
+
+	BilledTo struct {
+		UserID   uuid.UUID `json:"user_id"`
+		Username string    `json:"username"`
+		Email    string    `json:"email"`
+	} `json:"billed_to"`
+
+	Reservation struct {
+		RoomName     string    `json:"room_name"`
+		StartTime    time.Time `json:"start_time"`
+		EndTime      time.Time `json:"end_time"`
+		VisitorCount int       `json:"visitor_count"`
+		Status       string    `json:"status"`
+	} `json:"reservation"`
+
+	Lines         []InvoiceLine `json:"lines"`
+	Subtotal      float64       `json:"subtotal"`
+	DiscountTotal float64       `json:"discount_total"`
+	TaxLabel      string        `json:"tax_label"`
+	TaxRate       float64       `json:"tax_rate"`
+	TaxAmount     float64       `json:"tax_amount"`
+	Total         float64       `json:"total"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// OIDCLoginResponse holds the identity provider URL a single sign-on login continues at
+type OIDCLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// OIDCCallbackQuery is the redirect of the identity provider back to the application
+type OIDCCallbackQuery struct {
+	Code             string `query:"code"`
+	State            string `query:"state"`
+	Error            string `query:"error"`
+	ErrorDescription string `query:"error_description"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Payment struct {
+	ID                uuid.UUID `json:"id"`
+	ReservationID     uuid.UUID `json:"reservation_id"`
+	UserID            uuid.UUID `json:"user_id"`
+	Provider          string    `json:"provider"`
+	ProviderReference *string   `json:"provider_reference,omitempty"`
+	Amount            float64   `json:"amount"`
+	Currency          string    `json:"currency"`
+	Status            string    `json:"status"` // pending, succeeded or failed
+	CheckoutURL       *string   `json:"checkout_url,omitempty"`
+	FailureReason     *string   `json:"failure_reason,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type PaymentListResponse struct {
+	Payments []Payment `json:"payments"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PriceChange is a rate of a room or snack that applies from EffectiveFrom
+// (YYYY-MM-DD) until the next change. Changes dated after today are scheduled.
+type PriceChange struct {
+	ID            uuid.UUID  `json:"id"`
+	Price         float64    `json:"price"`
+	EffectiveFrom string     `json:"effective_from"`
+	Scheduled     bool       `json:"scheduled"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// PriceHistoryResponse lists the price changes of a room or snack, newest first
+type PriceHistoryResponse struct {
+	ItemID       uuid.UUID     `json:"item_id"`
+	CurrentPrice float64       `json:"current_price"`
+	Changes      []PriceChange `json:"changes"`
+}
+
+// SchedulePriceRequest sets the price from a date onwards. A change dated today
+// applies immediately; a change for the same date replaces the earlier one.
+type SchedulePriceRequest struct {
+	Price         float64 `json:"price" validate:"min=0"`
+	EffectiveFrom string  `json:"effective_from" validate:"required,datetime=2006-01-02"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PricingRule is a rate multiplier applied to a room's hourly price for the
+// matching days of week and time-of-day window
+type PricingRule struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	RoomID     *uuid.UUID `json:"room_id,omitempty"`
+	RoomTypeID *uuid.UUID `json:"room_type_id,omitempty"`
+	DaysOfWeek []int      `json:"days_of_week"`         // 0 = Sunday ... 6 = Saturday, empty matches every day
+	StartTime  *string    `json:"start_time,omitempty"` // HH:MM, nil matches the whole day
+	EndTime    *string    `json:"end_time,omitempty"`   // HH:MM, before start_time wraps past midnight
+	Multiplier float64    `json:"multiplier"`
+	Priority   int        `json:"priority"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type CreatePricingRuleRequest struct {
+	Name       string     `json:"name" validate:"required"`
+	RoomID     *uuid.UUID `json:"room_id,omitempty"`
+	RoomTypeID *uuid.UUID `json:"room_type_id,omitempty"`
+	DaysOfWeek []int      `json:"days_of_week" validate:"omitempty,dive,min=0,max=6"`
+	StartTime  *string    `json:"start_time,omitempty" validate:"omitempty,datetime=15:04"`
+	EndTime    *string    `json:"end_time,omitempty" validate:"omitempty,datetime=15:04"`
+	Multiplier float64    `json:"multiplier" validate:"required,gt=0"`
+	Priority   int        `json:"priority"`
+	Active     *bool      `json:"active,omitempty"`
+}
+
+type UpdatePricingRuleRequest struct {
+	Name        *string  `json:"name,omitempty"`
+	DaysOfWeek  []int    `json:"days_of_week,omitempty" validate:"omitempty,dive,min=0,max=6"`
+	StartTime   *string  `json:"start_time,omitempty" validate:"omitempty,datetime=15:04"`
+	EndTime     *string  `json:"end_time,omitempty" validate:"omitempty,datetime=15:04"`
+	ClearWindow bool     `json:"clear_window,omitempty"` // Match every day and the whole day again, before applying the fields above
+	Multiplier  *float64 `json:"multiplier,omitempty" validate:"omitempty,gt=0"`
+	Priority    *int     `json:"priority,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+type PricingRuleListResponse struct {
+	Rules []PricingRule `json:"rules"`
+}
+
+// Holiday adds a surcharge on top of the regular rate for a whole calendar day
+type Holiday struct {
+	ID                  uuid.UUID `json:"id"`
+	Date                string    `json:"date"` // Format: YYYY-MM-DD
+	Name                string    `json:"name"`
+	SurchargeMultiplier float64   `json:"surcharge_multiplier"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type CreateHolidayRequest struct {
+	Date                string  `json:"date" validate:"required,datetime=2006-01-02"`
+	Name                string  `json:"name" validate:"required"`
+	SurchargeMultiplier float64 `json:"surcharge_multiplier" validate:"required,gt=0"`
+}
+
+type HolidayListResponse struct {
+	Holidays []Holiday `json:"holidays"`
+}
+
+// PriceLineItem is one billed segment of a reservation and the rule that priced it
+type PriceLineItem struct {
+	Description string     `json:"description"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	Hours       float64    `json:"hours"`
+	BaseRate    float64    `json:"base_rate"`
+	Multiplier  float64    `json:"multiplier"`
+	RuleID      *uuid.UUID `json:"rule_id,omitempty"`
+	RuleName    string     `json:"rule_name,omitempty"`
+	Holiday     string     `json:"holiday,omitempty"`
+	Amount      float64    `json:"amount"`
+}
+
+// RoomPriceBreakdown is the result of pricing a room for a time range
+type RoomPriceBreakdown struct {
+	ActualHours float64         `json:"actual_hours"`
+	BilledHours float64         `json:"billed_hours"`
+	LineItems   []PriceLineItem `json:"line_items"`
+	Total       float64         `json:"total"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DiscountType string
+
+const (
+	DiscountTypePercentage DiscountType = "percentage"
+	DiscountTypeFixed      DiscountType = "fixed"
+)
+
+type PromoScope string
+
+const (
+	PromoScopeRoom   PromoScope = "room"
+	PromoScopeSnacks PromoScope = "snacks"
+	PromoScopeAll    PromoScope = "all"
+)
+
+type PromoCode struct {
+	ID             uuid.UUID    `json:"id"`
+	Code           string       `json:"code"`
+	Description    *string      `json:"description,omitempty"`
+	DiscountType   DiscountType `json:"discount_type"`
+	DiscountValue  float64      `json:"discount_value"`
+	AppliesTo      PromoScope   `json:"applies_to"`
+	RoomTypeID     *uuid.UUID   `json:"room_type_id,omitempty"` // Restricts the code to rooms of this type
+	ValidFrom      time.Time    `json:"valid_from"`
+	ValidUntil     time.Time    `json:"valid_until"`
+	MaxUses        *int         `json:"max_uses,omitempty"`          // Global limit, nil means unlimited
+	MaxUsesPerUser *int         `json:"max_uses_per_user,omitempty"` // Per user limit, nil means unlimited
+	TimesUsed      int          `json:"times_used"`
+	Active         bool         `json:"active"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+type CreatePromoCodeRequest struct {
+	Code           string       `json:"code" validate:"required,min=3,max=50,alphanum"`
+	Description    *string      `json:"description,omitempty"`
+	DiscountType   DiscountType `json:"discount_type" validate:"required,oneof=percentage fixed"`
+	DiscountValue  float64      `json:"discount_value" validate:"required,gt=0"`
+	AppliesTo      PromoScope   `json:"applies_to" validate:"required,oneof=room snacks all"`
+	RoomTypeID     *uuid.UUID   `json:"room_type_id,omitempty"`
+	ValidFrom      time.Time    `json:"valid_from" validate:"required"`
+	ValidUntil     time.Time    `json:"valid_until" validate:"required,gtfield=ValidFrom"`
+	MaxUses        *int         `json:"max_uses,omitempty" validate:"omitempty,min=1"`
+	MaxUsesPerUser *int         `json:"max_uses_per_user,omitempty" validate:"omitempty,min=1"`
+}
+
+type UpdatePromoCodeRequest struct {
+	Description         *string    `json:"description,omitempty"`
+	ValidFrom           *time.Time `json:"valid_from,omitempty"`
+	ValidUntil          *time.Time `json:"valid_until,omitempty"`
+	MaxUses             *int       `json:"max_uses,omitempty" validate:"omitempty,min=1"`
+	MaxUsesPerUser      *int       `json:"max_uses_per_user,omitempty" validate:"omitempty,min=1"`
+	ClearMaxUses        bool       `json:"clear_max_uses,omitempty"`          // Remove the usage limit, before applying max_uses
+	ClearMaxUsesPerUser bool       `json:"clear_max_uses_per_user,omitempty"` // Remove the per user limit, before applying max_uses_per_user
+	Active              *bool      `json:"active,omitempty"`
+}
+
+type PromoCodeListResponse struct {
+	PromoCodes []PromoCode `json:"promo_codes"`
+}
+
+// DiscountLine is a discount applied to a reservation price
+type DiscountLine struct {
+	PromoCodeID uuid.UUID  `json:"promo_code_id"`
+	Code        string     `json:"code"`
+	AppliesTo   PromoScope `json:"applies_to"`
+	Description string     `json:"description"`
+	Amount      float64    `json:"amount"`
+}
@@ -31,13 +31,30 @@ type ReservationDetailResponse struct {
 	} `json:"user"`
 
 	Snacks []struct {
-		ID       uuid.UUID `json:"id"`
-		Name     string    `json:"name"`
-		Category string    `json:"category"`
-		Price    float64   `json:"price"`
-		Quantity int       `json:"quantity"`
-		Subtotal float64   `json:"subtotal"`
+		ID          uuid.UUID `json:"id"`
+		Name        string    `json:"name"`
+		Category    string    `json:"category"`
+		Price       float64   `json:"price"`
+		Quantity    int       `json:"quantity"`
+		Subtotal    float64   `json:"subtotal"`
+		Active      bool      `json:"active"` // False when the snack has since been archived
+		DietaryTags []string  `json:"dietary_tags"`
+		Allergens   []string  `json:"allergens"`
 	} `json:"snacks"`
+	DietarySummary DietarySummary `json:"dietary_summary"`
+
+	RoomSubtotal  float64        `json:"room_subtotal"`
+	SnackSubtotal float64        `json:"snack_subtotal"`
+	Discounts     []DiscountLine `json:"discounts,omitempty"`
+	DiscountTotal float64        `json:"discount_total"`
+	TaxRate       float64        `json:"tax_rate"`
+	TaxAmount     float64        `json:"tax_amount"`
+	TotalCost     float64        `json:"total_cost"`
+
+	CostCenter     *CostCenterRef  `json:"cost_center,omitempty"`
+	ApprovalStatus *ApprovalStatus `json:"approval_status,omitempty"`
 
-	TotalCost float64 `json:"total_cost"`
+	CancellationFee float64       `json:"cancellation_fee"`
+	RefundAmount    float64       `json:"refund_amount"`
+	Ledger          []LedgerEntry `json:"ledger,omitempty"`
 }
@@ -62,50 +62,112 @@ type UpdateReservationStatusRequest struct {
 	Status        ReservationStatus `json:"status" validate:"required"`
 }
 
+// SnackOrderItem is one snack line requested with a reservation
+type SnackOrderItem struct {
+	SnackID  uuid.UUID `json:"snack_id" validate:"required"`
+	Quantity int       `json:"quantity"` // Checked per line with the rest of the order, see SnackLineInvalidQuantity
+}
+
+type SnackLineErrorCode string
+
+const (
+	SnackLineInvalidQuantity  SnackLineErrorCode = "invalid_quantity"  // The quantity of the line is not positive
+	SnackLineUnknown          SnackLineErrorCode = "unknown_snack"     // No snack with this ID exists
+	SnackLineArchived         SnackLineErrorCode = "archived_snack"    // The snack was archived and cannot be ordered
+	SnackLineQuantityExceeded SnackLineErrorCode = "quantity_exceeded" // More than allowed for the visitor count
+	SnackLineNotOnMenu        SnackLineErrorCode = "not_on_menu"       // Not served at the location of the room
+)
+
+// SnackLineError reports why a snack of an order was rejected. Lines holds the
+// positions in the request's snacks array, several when duplicate lines were merged.
+type SnackLineError struct {
+	Lines   []int              `json:"lines"`
+	SnackID uuid.UUID          `json:"snack_id"`
+	Code    SnackLineErrorCode `json:"code"`
+	Message string             `json:"message"`
+}
+
+type SnackOrderErrorResponse struct {
+	Error string           `json:"error"`
+	Lines []SnackLineError `json:"lines"`
+}
+
 type ReservationCalculationRequest struct {
-	RoomID uuid.UUID `json:"room_id" validate:"required"`
-	Snacks []struct {
-		SnackID  uuid.UUID `json:"snack_id" validate:"required"`
-		Quantity int       `json:"quantity" validate:"required,min=1"`
-	} `json:"snacks" validate:"required"`
-	StartTime time.Time `json:"start_time" validate:"required"`
-	EndTime   time.Time `json:"end_time" validate:"required"`
+	RoomID       uuid.UUID        `json:"room_id" validate:"required"`
+	Snacks       []SnackOrderItem `json:"snacks" validate:"required,dive"`
+	StartTime    time.Time        `json:"start_time" validate:"required"`
+	EndTime      time.Time        `json:"end_time" validate:"required"`
+	VisitorCount int              `json:"visitor_count,omitempty" validate:"omitempty,min=1"` // Enables the per-line quantity limit
+	PromoCode    string           `json:"promo_code,omitempty" validate:"omitempty,max=50"`
+	SaveQuote    bool             `json:"save_quote,omitempty"` // Persist the result as a quote that locks the prices
+	UserID       uuid.UUID        `json:"-"`
+}
+
+// RoomCostDetail is the priced room part of a reservation with the
+// line items produced by the pricing engine
+type RoomCostDetail struct {
+	ID           uuid.UUID       `json:"id"`
+	Name         string          `json:"name"`
+	PricePerHour float64         `json:"price_per_hour"`
+	TotalHours   float64         `json:"total_hours"`
+	BilledHours  float64         `json:"billed_hours"`
+	LineItems    []PriceLineItem `json:"line_items"`
+	TotalCost    float64         `json:"total_cost"`
+}
+
+// SnackCostDetail is one priced snack line of a reservation calculation
+type SnackCostDetail struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Category string    `json:"category"`
+	Price    float64   `json:"price"`
+	Quantity int       `json:"quantity"`
+	Subtotal float64   `json:"subtotal"`
 }
 
 type ReservationCalculationResponse struct {
-	Room struct {
-		ID           uuid.UUID `json:"id"`
-		Name         string    `json:"name"`
-		PricePerHour float64   `json:"price_per_hour"`
-		TotalHours   float64   `json:"total_hours"`
-		TotalCost    float64   `json:"total_cost"`
-	} `json:"room"`
-	Snacks []struct {
-		ID       uuid.UUID `json:"id"`
-		Name     string    `json:"name"`
-		Category string    `json:"category"`
-		Price    float64   `json:"price"`
-		Quantity int       `json:"quantity"`
-		Subtotal float64   `json:"subtotal"`
-	} `json:"snacks"`
-	TotalCost float64 `json:"total_cost"`
+	Room           RoomCostDetail    `json:"room"`
+	Snacks         []SnackCostDetail `json:"snacks"`
+	Subtotal       float64           `json:"subtotal"`
+	Discounts      []DiscountLine    `json:"discounts,omitempty"`
+	DiscountTotal  float64           `json:"discount_total"`
+	TaxLabel       string            `json:"tax_label"`
+	TaxRate        float64           `json:"tax_rate"`
+	TaxAmount      float64           `json:"tax_amount"`
+	TotalCost      float64           `json:"total_cost"`
+	QuoteID        *uuid.UUID        `json:"quote_id,omitempty"`
+	QuoteExpiresAt *time.Time        `json:"quote_expires_at,omitempty"`
 }
 
 type CreateReservationRequest struct {
-	RoomID       uuid.UUID `json:"room_id" validate:"required"`
-	UserID       uuid.UUID `json:"user_id" validate:"required"`
-	StartTime    time.Time `json:"start_time" validate:"required"`
-	EndTime      time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
-	VisitorCount int       `json:"visitor_count" validate:"required,min=1"`
-	Snacks       []struct {
-		SnackID  uuid.UUID `json:"snack_id" validate:"required"`
-		Quantity int       `json:"quantity" validate:"required,min=1"`
-	} `json:"snacks" validate:"required,dive"`
+	RoomID       uuid.UUID        `json:"room_id" validate:"required"`
+	UserID       uuid.UUID        `json:"-"` // The signed-in user, never taken from the body
+	StartTime    time.Time        `json:"start_time" validate:"required"`
+	EndTime      time.Time        `json:"end_time" validate:"required,gtfield=StartTime"`
+	VisitorCount int              `json:"visitor_count" validate:"required,min=1"`
+	Snacks       []SnackOrderItem `json:"snacks" validate:"required,dive"`
+	PromoCode    string           `json:"promo_code,omitempty" validate:"omitempty,max=50"`
+	QuoteID      *uuid.UUID       `json:"quote_id,omitempty"`       // Honour the prices of a saved quote
+	CostCenterID *uuid.UUID       `json:"cost_center_id,omitempty"` // Overrides the user's default cost center
+	// When the snacks should be delivered, at or before the start time (defaults to the start time)
+	SnackDeliveryTime *time.Time `json:"snack_delivery_time,omitempty"`
+	CateringNotes     string     `json:"catering_notes,omitempty" validate:"omitempty,max=500"`
 }
 
 type CreateReservationResponse struct {
-	ReservationID uuid.UUID `json:"reservation_id"`
-	Status        string    `json:"status"`
-	TotalCost     float64   `json:"total_cost"`
-	CreatedAt     time.Time `json:"created_at"`
+	ReservationID uuid.UUID      `json:"reservation_id"`
+	Status        string         `json:"status"`
+	Room          RoomCostDetail `json:"room"`
+	SnackCost     float64        `json:"snack_cost"`
+	Discounts     []DiscountLine `json:"discounts,omitempty"`
+	DiscountTotal float64        `json:"discount_total"`
+	TaxLabel      string         `json:"tax_label"`
+	TaxRate       float64        `json:"tax_rate"`
+	TaxAmount     float64        `json:"tax_amount"`
+	TotalCost     float64        `json:"total_cost"`
+	QuoteID       *uuid.UUID     `json:"quote_id,omitempty"`
+	CostCenterID  *uuid.UUID     `json:"cost_center_id,omitempty"`
+	// Set to "required" when the booking exceeds the cost center budget and waits for approval
+	ApprovalStatus *ApprovalStatus `json:"approval_status,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
 }
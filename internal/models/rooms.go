@@ -7,18 +7,61 @@ import (
 )
 
 type Room struct {
+	ID           uuid.UUID   `json:"id"`
+	Name         string      `json:"name" validate:"required"`
+	Capacity     int         `json:"capacity" validate:"required,min=1"`
+	PricePerHour float64     `json:"price_per_hour" validate:"required,min=0"`
+	Status       string      `json:"status" validate:"required,oneof=active inactive"`
+	RoomTypeID   *uuid.UUID  `json:"room_type_id,omitempty"`
+	LocationID   *uuid.UUID  `json:"location_id,omitempty"`
+	Photos       []RoomPhoto `json:"photos"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// RoomPhoto is an uploaded photo of a room with its thumbnail
+type RoomPhoto struct {
 	ID           uuid.UUID `json:"id"`
-	Name         string    `json:"name" validate:"required"`
-	Capacity     int       `json:"capacity" validate:"required,min=1"`
-	PricePerHour float64   `json:"price_per_hour" validate:"required,min=0"`
-	Status       string    `json:"status" validate:"required,oneof=active inactive"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
 	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type RoomType struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateRoomTypeRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type RoomTypeListResponse struct {
+	RoomTypes []RoomType `json:"room_types"`
+}
+
+// Location is a building or site whose rooms are served by one kitchen
+type Location struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateLocationRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type LocationListResponse struct {
+	Locations []Location `json:"locations"`
 }
 
 type RoomFilter struct {
 	Search      *string    `json:"search,omitempty"` // Search by name
 	RoomTypeID  *uuid.UUID `json:"room_type_id,omitempty"`
+	LocationID  *uuid.UUID `json:"location_id,omitempty"`
 	MinCapacity *int       `json:"min_capacity,omitempty"`
 	MaxCapacity *int       `json:"max_capacity,omitempty"`
 	Status      *string    `json:"status,omitempty"` // active, inactive
@@ -38,17 +81,21 @@ type RoomListResponse struct {
 }
 
 type CreateRoomRequest struct {
-	Name         string  `json:"name" validate:"required"`
-	Capacity     int     `json:"capacity" validate:"required,min=1"`
-	PricePerHour float64 `json:"price_per_hour" validate:"required,min=0"`
-	Status       string  `json:"status" validate:"required,oneof=active inactive"`
+	Name         string     `json:"name" validate:"required"`
+	Capacity     int        `json:"capacity" validate:"required,min=1"`
+	PricePerHour float64    `json:"price_per_hour" validate:"required,min=0"`
+	Status       string     `json:"status" validate:"required,oneof=active inactive"`
+	RoomTypeID   *uuid.UUID `json:"room_type_id,omitempty"`
+	LocationID   *uuid.UUID `json:"location_id,omitempty"`
 }
 
 type UpdateRoomRequest struct {
-	Name         *string  `json:"name,omitempty"`
-	Capacity     *int     `json:"capacity,omitempty" validate:"omitempty,min=1"`
-	PricePerHour *float64 `json:"price_per_hour,omitempty" validate:"omitempty,min=0"`
-	Status       *string  `json:"status,omitempty" validate:"omitempty,oneof=active inactive"`
+	Name         *string    `json:"name,omitempty"`
+	Capacity     *int       `json:"capacity,omitempty" validate:"omitempty,min=1"`
+	PricePerHour *float64   `json:"price_per_hour,omitempty" validate:"omitempty,min=0"`
+	Status       *string    `json:"status,omitempty" validate:"omitempty,oneof=active inactive"`
+	RoomTypeID   *uuid.UUID `json:"room_type_id,omitempty"`
+	LocationID   *uuid.UUID `json:"location_id,omitempty"`
 }
 
 type RoomScheduleQuery struct {
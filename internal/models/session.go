@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClientInfo describes the device a login or refresh comes from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// Session is one login of a user, it lasts as long as its refresh tokens
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  *string   `json:"user_agent"`
+	IPAddress  *string   `json:"ip_address"` // Address of the latest login or refresh
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // The session of the token used for the request
+}
+
+type SessionListResponse struct {
+	Sessions []Session `json:"sessions"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StockMovementType string
+
+const (
+	StockMovementRestock    StockMovementType = "restock"    // Delivery added to the stock
+	StockMovementAdjustment StockMovementType = "adjustment" // Correction after a stock count
+	StockMovementReserve    StockMovementType = "reserve"    // Consumed by a reservation
+	StockMovementRelease    StockMovementType = "release"    // Returned by a cancelled reservation
+)
+
+type StockMovement struct {
+	ID            uuid.UUID         `json:"id"`
+	SnackID       uuid.UUID         `json:"snack_id"`
+	SnackName     string            `json:"snack_name"`
+	ReservationID *uuid.UUID        `json:"reservation_id,omitempty"`
+	Type          StockMovementType `json:"movement_type"`
+	Quantity      int               `json:"quantity"` // Negative when stock is taken out
+	StockAfter    int               `json:"stock_after"`
+	Note          *string           `json:"note,omitempty"`
+	CreatedBy     *uuid.UUID        `json:"created_by,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+type StockMovementListResponse struct {
+	Movements []StockMovement `json:"movements"`
+}
+
+type StockMovementQuery struct {
+	Limit int `query:"limit"` // Defaults to 100, at most 500
+}
+
+// RestockSnackRequest adds delivered items to the stock of a snack
+type RestockSnackRequest struct {
+	Quantity int    `json:"quantity" validate:"required,min=1"`
+	Note     string `json:"note"`
+}
+
+// AdjustStockRequest sets the counted stock of a snack, starting stock tracking
+// for snacks that were not tracked before
+type AdjustStockRequest struct {
+	StockQuantity int    `json:"stock_quantity" validate:"min=0"`
+	Note          string `json:"note" validate:"required"`
+}
+
+// UpdateStockSettingsRequest changes the daily capacity and low stock threshold of a snack.
+// A daily capacity of 0 removes the daily limit.
+type UpdateStockSettingsRequest struct {
+	DailyCapacity     *int `json:"daily_capacity,omitempty" validate:"omitempty,min=0"`
+	LowStockThreshold *int `json:"low_stock_threshold,omitempty" validate:"omitempty,min=0"`
+}
+
+// LowStockAlert is an active snack whose tracked stock is at or below its threshold
+type LowStockAlert struct {
+	SnackID           uuid.UUID `json:"snack_id"`
+	Name              string    `json:"name"`
+	Category          string    `json:"category"`
+	StockQuantity     int       `json:"stock_quantity"`
+	LowStockThreshold int       `json:"low_stock_threshold"`
+	OutOfStock        bool      `json:"out_of_stock"`
+}
+
+type LowStockResponse struct {
+	Alerts []LowStockAlert `json:"alerts"`
+}
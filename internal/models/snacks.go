@@ -7,14 +7,102 @@ import (
 )
 
 type Snack struct {
-	ID        uuid.UUID `json:"id" gorm:"primaryKey"`
-	Name      string    `json:"name"`
-	Category  string    `json:"category"`
-	Price     float64   `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                uuid.UUID  `json:"id" gorm:"primaryKey"`
+	Name              string     `json:"name"`
+	CategoryID        uuid.UUID  `json:"category_id"`
+	Category          string     `json:"category"`
+	Price             float64    `json:"price"`
+	Active            bool       `json:"active"`
+	ArchivedAt        *time.Time `json:"archived_at,omitempty"`
+	StockQuantity     *int       `json:"stock_quantity"` // nil when stock is not tracked
+	DailyCapacity     *int       `json:"daily_capacity"` // nil when there is no daily limit
+	LowStockThreshold int        `json:"low_stock_threshold"`
+	DietaryTags       []string   `json:"dietary_tags"`
+	Allergens         []string   `json:"allergens"`
+	ImageURL          *string    `json:"image_url"`
+	ThumbnailURL      *string    `json:"thumbnail_url"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
 }
 
 type SnackListResponse struct {
 	Snacks []Snack `json:"snacks"`
 }
+
+// Dietary tags a snack can carry
+const (
+	DietaryVegetarian = "vegetarian"
+	DietaryVegan      = "vegan" // Vegan snacks are also tagged vegetarian
+	DietaryHalal      = "halal"
+	DietaryGlutenFree = "gluten_free"
+)
+
+// SnackListQuery filters the snack list. Dietary and ExcludeAllergens are comma separated;
+// snacks must carry every dietary tag and none of the allergens. With a room ID only
+// the snacks on the menu of the room's location are listed.
+type SnackListQuery struct {
+	IncludeArchived  bool   `query:"include_archived"`
+	CategoryID       string `query:"category_id"`
+	RoomID           string `query:"room_id"`
+	Dietary          string `query:"dietary"`
+	ExcludeAllergens string `query:"exclude_allergens"`
+}
+
+// SnackCategory groups snacks on the menu. Categories are listed by sort order, then name.
+type SnackCategory struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	SortOrder  int       `json:"sort_order"`
+	SnackCount int       `json:"snack_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type SnackCategoryListResponse struct {
+	Categories []SnackCategory `json:"categories"`
+}
+
+type CreateSnackCategoryRequest struct {
+	Name      string `json:"name" validate:"required,max=100"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type UpdateSnackCategoryRequest struct {
+	Name      *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	SortOrder *int    `json:"sort_order,omitempty"`
+}
+
+// MergeSnackCategoryRequest moves every snack of a category into the target
+// category and deletes the merged category
+type MergeSnackCategoryRequest struct {
+	TargetID uuid.UUID `json:"target_id" validate:"required"`
+}
+
+// LocationMenu lists the snacks served at a location. A location without a
+// menu (Restricted false) serves the whole catalogue.
+type LocationMenu struct {
+	LocationID uuid.UUID `json:"location_id"`
+	Restricted bool      `json:"restricted"`
+	Snacks     []Snack   `json:"snacks"`
+}
+
+// SetLocationMenuRequest replaces the menu of a location. An empty list removes
+// the menu so the location serves the whole catalogue again.
+type SetLocationMenuRequest struct {
+	SnackIDs []uuid.UUID `json:"snack_ids" validate:"dive,required"`
+}
+
+// AllergenSummary lists the ordered snacks containing an allergen
+type AllergenSummary struct {
+	Allergen string   `json:"allergen"`
+	Items    int      `json:"items"`
+	Snacks   []string `json:"snacks"`
+}
+
+// DietarySummary tells catering how many ordered items fit each dietary
+// requirement and which items contain allergens
+type DietarySummary struct {
+	TotalItems int               `json:"total_items"`
+	Tags       map[string]int    `json:"tags"`
+	Allergens  []AllergenSummary `json:"allergens"`
+}
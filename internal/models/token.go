@@ -0,0 +1,18 @@
+package models
+
+// TokenResponse is a short-lived access token with the refresh token that renews it
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // Seconds until the access token expires
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutRequest identifies the session to sign out by a refresh token, for access
+// tokens issued before sessions existed. The access token used is revoked either way.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
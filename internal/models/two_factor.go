@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// TwoFactorSetupResponse is a pending authenticator enrolment. The provisioning
+// URI is rendered as a QR code for authenticator apps to scan.
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorCodeRequest carries a code of the authenticator, or a recovery code
+// where one is accepted
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TwoFactorLoginRequest completes a login that returned a challenge token
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+// ChallengeRequest starts the enrolment a login challenge asked for
+type ChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+// RecoveryCodesResponse lists single-use recovery codes, they are only shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorPolicy tells whether members of a role must sign in with a second factor
+type TwoFactorPolicy struct {
+	Role      string     `json:"role"`
+	Required  bool       `json:"required"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type TwoFactorPolicyListResponse struct {
+	Policies []TwoFactorPolicy `json:"policies"`
+}
+
+type UpdateTwoFactorPolicyRequest struct {
+	Required *bool `json:"required" validate:"required"`
+}
@@ -9,17 +9,23 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	Username  string         `gorm:"size:50;not null;unique" json:"username" validate:"required,min=3,max=50,alphanum"`
-	Email     string         `gorm:"size:100;not null;unique" json:"email" validate:"required,email"`
-	Password  string         `gorm:"size:255;not null" json:"-" validate:"required,min=6"`
-	Role      string         `gorm:"size:20;not null;default:'user'" json:"role" validate:"required,oneof=user admin"`
-	ProfPic   *string        `gorm:"size:255" json:"prof_pic" validate:"omitempty,url"`
-	Language  string         `gorm:"size:10;not null;default:'id'" json:"language" validate:"required,oneof=id en"`
-	Status    bool           `gorm:"default:true" json:"status"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                  uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Username            string         `gorm:"size:50;not null;unique" json:"username" validate:"required,min=3,max=50,alphanum"`
+	Email               string         `gorm:"size:100;not null;unique" json:"email" validate:"required,email"`
+	Password            string         `gorm:"size:255;not null" json:"-" validate:"required,min=6"`
+	Role                string         `gorm:"size:20;not null;default:'user'" json:"role" validate:"required,oneof=user admin catering"`
+	ProfPic             *string        `gorm:"size:255" json:"prof_pic" validate:"omitempty,url"`
+	ProfPicKey          *string        `gorm:"size:255" json:"-"` // Storage key of an uploaded avatar
+	Language            string         `gorm:"size:10;not null;default:'id'" json:"language" validate:"required,oneof=id en"`
+	Status              bool           `gorm:"default:true" json:"status"`
+	TokenVersion        int            `gorm:"not null;default:0" json:"-"` // Tokens carrying an older version are rejected
+	TOTPSecret          *string        `gorm:"column:totp_secret;size:64" json:"-"`
+	TwoFactorEnabled    bool           `gorm:"not null;default:false" json:"two_factor_enabled"`
+	TOTPLastStep        *int64         `gorm:"column:totp_last_step" json:"-"` // Time step of the last accepted code
+	DefaultCostCenterID *uuid.UUID     `gorm:"type:uuid" json:"default_cost_center_id,omitempty"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type LoginRequest struct {
@@ -27,9 +33,17 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required,min=6"`
 }
 
+// LoginResponse holds the tokens of a login, or the challenge to complete it when
+// a second factor is needed
 type LoginResponse struct {
-	UserID string `json:"user_id"`
-	Token  string `json:"token"`
+	UserID                 string   `json:"user_id"`
+	Token                  string   `json:"token,omitempty"`
+	RefreshToken           string   `json:"refresh_token,omitempty"`
+	ExpiresIn              int      `json:"expires_in,omitempty"` // Seconds until the access token expires
+	TwoFactorRequired      bool     `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool     `json:"two_factor_setup_required,omitempty"` // The role requires 2FA and none is enrolled
+	ChallengeToken         string   `json:"challenge_token,omitempty"`
+	RecoveryCodes          []string `json:"recovery_codes,omitempty"` // Issued when 2FA was enrolled during the login
 }
 
 type RegisterRequest struct {
@@ -46,21 +60,25 @@ type RegisterResponse struct {
 }
 
 type UserProfileResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	ProfPic   *string   `json:"prof_pic"`
-	Language  string    `json:"language"`
-	Status    bool      `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                  uuid.UUID  `json:"id"`
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	Role                string     `json:"role"`
+	ProfPic             *string    `json:"prof_pic"`
+	Language            string     `json:"language"`
+	Status              bool       `json:"status"`
+	TwoFactorEnabled    bool       `json:"two_factor_enabled"`
+	DefaultCostCenterID *uuid.UUID `json:"default_cost_center_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 type Claims struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Username string    `json:"username"`
-	Role     string    `json:"role"`
+	UserID       uuid.UUID `json:"user_id"`
+	Username     string    `json:"username"`
+	Role         string    `json:"role"`
+	TokenVersion int       `json:"token_version"`
+	SessionID    string    `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const RoleAdmin = "admin"
+
+// AdminUserQuery filters the user list of the admin panel
+type AdminUserQuery struct {
+	Search         string `query:"search"` // Matches username or email
+	Role           string `query:"role"`
+	Status         string `query:"status"` // active, inactive
+	IncludeDeleted bool   `query:"include_deleted"`
+	Page           int    `query:"page"`
+	PageSize       int    `query:"page_size"`
+}
+
+// AdminUser is a user account as seen by admins
+type AdminUser struct {
+	ID                  uuid.UUID  `json:"id"`
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	Role                string     `json:"role"`
+	Status              bool       `json:"status"`
+	TwoFactorEnabled    bool       `json:"two_factor_enabled"`
+	Language            string     `json:"language"`
+	ProfPic             *string    `json:"prof_pic"`
+	DefaultCostCenterID *uuid.UUID `json:"default_cost_center_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	DeletedAt           *time.Time `json:"deleted_at,omitempty"`
+}
+
+type AdminUserListResponse struct {
+	Users      []AdminUser `json:"users"`
+	TotalCount int         `json:"total_count"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"page_size"`
+	TotalPages int         `json:"total_pages"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user admin catering"`
+}
+
+// UpdateUserStatusRequest activates or deactivates an account
+type UpdateUserStatusRequest struct {
+	Active *bool `json:"active" validate:"required"`
+}
@@ -0,0 +1,92 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// FakeSignatureHeader carries the hex HMAC-SHA256 of the webhook body
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// FakeGateway is a local provider for development and tests. It never moves
+// money: references are derived from the payment ID, and payments change status
+// only through webhooks signed with the shared secret (see FakeGateway.Webhook).
+type FakeGateway struct {
+	secret []byte
+}
+
+func NewFakeGateway(secret string) *FakeGateway {
+	return &FakeGateway{secret: []byte(secret)}
+}
+
+type fakeWebhookPayload struct {
+	EventID       string `json:"event_id"`
+	Reference     string `json:"reference"`
+	Status        Status `json:"status"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) CreateIntent(req IntentRequest) (*Intent, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("payment amount must be positive")
+	}
+	reference := "fake_" + req.PaymentID.String()
+	return &Intent{
+		Reference:   reference,
+		CheckoutURL: "https://fake-payments.local/checkout/" + reference,
+		Status:      StatusPending,
+	}, nil
+}
+
+func (g *FakeGateway) ParseWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	signature, err := hex.DecodeString(header.Get(FakeSignatureHeader))
+	if err != nil || !hmac.Equal(signature, g.sign(payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var body fakeWebhookPayload
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %v", err)
+	}
+	if body.EventID == "" || body.Reference == "" {
+		return nil, fmt.Errorf("invalid webhook payload: event_id and reference are required")
+	}
+	if body.Status != StatusSucceeded && body.Status != StatusFailed {
+		return nil, fmt.Errorf("invalid webhook payload: unsupported status %q", body.Status)
+	}
+
+	return &WebhookEvent{
+		EventID:       body.EventID,
+		Reference:     body.Reference,
+		Status:        body.Status,
+		FailureReason: body.FailureReason,
+		Payload:       payload,
+	}, nil
+}
+
+// Webhook builds a signed callback as the fake provider would send it
+func (g *FakeGateway) Webhook(eventID, reference string, status Status, failureReason string) ([]byte, http.Header) {
+	payload, _ := json.Marshal(fakeWebhookPayload{
+		EventID:       eventID,
+		Reference:     reference,
+		Status:        status,
+		FailureReason: failureReason,
+	})
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, hex.EncodeToString(g.sign(payload)))
+	return payload, header
+}
+
+func (g *FakeGateway) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
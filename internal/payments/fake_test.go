@@ -0,0 +1,47 @@
+package payments
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeGateway_CreateIntentIsDeterministic(t *testing.T) {
+	gateway := NewFakeGateway("secret")
+	req := IntentRequest{PaymentID: uuid.New(), Amount: 150000, Currency: "IDR"}
+
+	first, err := gateway.CreateIntent(req)
+	require.NoError(t, err)
+	second, err := gateway.CreateIntent(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, StatusPending, first.Status)
+
+	_, err = gateway.CreateIntent(IntentRequest{PaymentID: uuid.New()})
+	assert.Error(t, err)
+}
+
+func TestFakeGateway_Webhook(t *testing.T) {
+	gateway := NewFakeGateway("secret")
+
+	payload, header := gateway.Webhook("evt_1", "fake_ref", StatusFailed, "card declined")
+	event, err := gateway.ParseWebhook(payload, header)
+	require.NoError(t, err)
+	assert.Equal(t, "evt_1", event.EventID)
+	assert.Equal(t, "fake_ref", event.Reference)
+	assert.Equal(t, StatusFailed, event.Status)
+	assert.Equal(t, "card declined", event.FailureReason)
+
+	// Signed with another secret
+	payload, header = NewFakeGateway("other").Webhook("evt_2", "fake_ref", StatusSucceeded, "")
+	_, err = gateway.ParseWebhook(payload, header)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Tampered body
+	_, header = gateway.Webhook("evt_3", "fake_ref", StatusFailed, "")
+	_, err = gateway.ParseWebhook([]byte(`{"event_id":"evt_3","reference":"fake_ref","status":"succeeded"}`), header)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
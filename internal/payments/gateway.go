@@ -0,0 +1,89 @@
+// Package payments defines the payment gateway interface and its providers.
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidSignature is returned when a webhook signature does not verify
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrNotConfigured is returned by NewGateway when no provider and no secret are set
+	ErrNotConfigured = errors.New("no payment gateway configured")
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// IntentRequest describes a payment to be collected by a gateway
+type IntentRequest struct {
+	PaymentID     uuid.UUID
+	ReservationID uuid.UUID
+	Amount        float64
+	Currency      string
+	Description   string
+}
+
+// Intent is the gateway side of a payment
+type Intent struct {
+	Reference   string // Gateway identifier of the payment, used to match webhooks
+	CheckoutURL string // Where the user completes the payment
+	Status      Status
+}
+
+// WebhookEvent is a verified payment status change reported by a gateway
+type WebhookEvent struct {
+	EventID       string // Unique per delivery, used to ignore redelivered events
+	Reference     string
+	Status        Status
+	FailureReason string
+	Payload       []byte
+}
+
+// Gateway is implemented by payment providers
+type Gateway interface {
+	// Name identifies the provider, it is stored with each payment
+	Name() string
+	// CreateIntent registers a payment with the provider
+	CreateIntent(req IntentRequest) (*Intent, error)
+	// ParseWebhook verifies the signature of a callback and decodes it.
+	// Returns ErrInvalidSignature when the callback was not sent by the provider.
+	ParseWebhook(payload []byte, header http.Header) (*WebhookEvent, error)
+}
+
+// placeholderWebhookSecret is the example secret once shipped as a default, it is public
+const placeholderWebhookSecret = "your-webhook-secret"
+
+// NewGateway returns the gateway configured by name, or ErrNotConfigured when
+// payments are not set up at all. Webhooks confirm payments, so a gateway is only
+// created with a real secret to verify them with. The fake provider confirms
+// payments nobody made and is refused in production.
+func NewGateway(provider, webhookSecret string, production bool) (Gateway, error) {
+	if provider == "" && webhookSecret == "" {
+		return nil, ErrNotConfigured
+	}
+	if webhookSecret == "" || webhookSecret == placeholderWebhookSecret {
+		return nil, fmt.Errorf("a payment webhook secret is required")
+	}
+
+	switch provider {
+	case "":
+		return nil, fmt.Errorf("no payment provider configured")
+	case "fake":
+		if production {
+			return nil, fmt.Errorf("the fake payment provider cannot be used in production")
+		}
+		return NewFakeGateway(webhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", provider)
+	}
+}
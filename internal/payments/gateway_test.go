@@ -0,0 +1,33 @@
+package payments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGateway(t *testing.T) {
+	gateway, err := NewGateway("fake", "secret", false)
+	require.NoError(t, err)
+	assert.Equal(t, "fake", gateway.Name())
+
+	// Payments are optional
+	_, err = NewGateway("", "", true)
+	assert.ErrorIs(t, err, ErrNotConfigured)
+
+	// Anyone could sign webhooks with a missing or published secret
+	_, err = NewGateway("fake", "", false)
+	assert.Error(t, err)
+	_, err = NewGateway("fake", placeholderWebhookSecret, false)
+	assert.Error(t, err)
+
+	// The fake provider confirms payments nobody made
+	_, err = NewGateway("fake", "secret", true)
+	assert.Error(t, err)
+
+	_, err = NewGateway("", "secret", false)
+	assert.Error(t, err)
+	_, err = NewGateway("stripe", "secret", false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_Structure(t *testing.T) {
+	doc := NewDocument()
+	doc.Text(50, 800, 12, true, "Invoice (draft)")
+	doc.Line(50, 790, 545, 790, 0.5)
+	doc.AddPage()
+	doc.Text(50, 800, 10, false, "Page two")
+
+	out := doc.Bytes()
+	require.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), `(Invoice \(draft\)) Tj`)
+	assert.Contains(t, string(out), "/Count 2")
+
+	// startxref must point at the xref table
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	require.NotNil(t, match)
+	xref, err := strconv.Atoi(string(match[1]))
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(out[xref:], []byte("xref\n")))
+
+	// Every xref entry must point at its object
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	require.NotEmpty(t, entries)
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(string(entry[1]))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte(fmt.Sprintf("%d 0 obj", i+1))))
+	}
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\\b \(c\)`, escape(`a\b (c)`))
+	assert.Equal(t, `caf\351`, escape("café"))
+	assert.Equal(t, "?", escape("€"))
+}
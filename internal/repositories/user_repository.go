@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
@@ -19,6 +20,7 @@ type UserRepository interface {
 	UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error
 	GetProfile(ctx context.Context, userID uuid.UUID) (*models.UserProfileResponse, error)
 	UpdateProfile(ctx context.Context, userID string, req *models.UpdateProfileRequest) (*models.UserProfileResponse, error)
+	UpdateAvatar(ctx context.Context, userID uuid.UUID, profPic, key string) (*models.UserProfileResponse, *string, error)
 }
 
 type userRepository struct {
@@ -70,6 +72,22 @@ func (r *userRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, h
 		Update("password", hashedPassword).Error
 }
 
+func toProfileResponse(user *models.User) *models.UserProfileResponse {
+	return &models.UserProfileResponse{
+		ID:                  user.ID,
+		Username:            user.Username,
+		Email:               user.Email,
+		Role:                user.Role,
+		ProfPic:             user.ProfPic,
+		Language:            user.Language,
+		Status:              user.Status,
+		TwoFactorEnabled:    user.TwoFactorEnabled,
+		DefaultCostCenterID: user.DefaultCostCenterID,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+	}
+}
+
 func (r *userRepository) GetProfile(ctx context.Context, userID uuid.UUID) (*models.UserProfileResponse, error) {
 	var user models.User
 	err := r.db.WithContext(ctx).
@@ -82,17 +100,7 @@ func (r *userRepository) GetProfile(ctx context.Context, userID uuid.UUID) (*mod
 		return nil, err
 	}
 
-	return &models.UserProfileResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Role:      user.Role,
-		ProfPic:   user.ProfPic,
-		Language:  user.Language,
-		Status:    user.Status,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}, nil
+	return toProfileResponse(&user), nil
 }
 
 func (r *userRepository) UpdateProfile(ctx context.Context, userID string, req *models.UpdateProfileRequest) (*models.UserProfileResponse, error) {
@@ -162,7 +170,7 @@ func (r *userRepository) UpdateProfile(ctx context.Context, userID string, req *
 	}
 
 	// Add WHERE clause
-	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, username, email, role, status, language, prof_pic, created_at, updated_at", argCount)
+	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, username, email, role, status, two_factor_enabled, language, prof_pic, default_cost_center_id, created_at, updated_at", argCount)
 	args = append(args, id)
 
 	// Execute update and scan result
@@ -182,3 +190,41 @@ func (r *userRepository) UpdateProfile(ctx context.Context, userID string, req *
 	}
 	return &profile, nil
 }
+
+// UpdateAvatar points the profile picture of a user at an uploaded file and
+// returns the storage key of the file it replaces, if any
+func (r *userRepository) UpdateAvatar(ctx context.Context, userID uuid.UUID, profPic, key string) (*models.UserProfileResponse, *string, error) {
+	// Start transaction
+	tx := r.db.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	var user models.User
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", userID).
+		First(&user).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, errors.New("user not found")
+		}
+		return nil, nil, err
+	}
+	oldKey := user.ProfPicKey
+
+	err = tx.Model(&user).Updates(map[string]interface{}{
+		"prof_pic":     profPic,
+		"prof_pic_key": key,
+		"updated_at":   time.Now(),
+	}).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("error updating avatar: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit().Error; err != nil {
+		return nil, nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	user.ProfPic = &profPic
+	user.ProfPicKey = &key
+	return toProfileResponse(&user), oldKey, nil
+}
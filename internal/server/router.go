@@ -16,10 +16,27 @@ func SetupRouter(
 	passwordResetHandler *handlers.PasswordResetHandler,
 	rateLimiter *middleware.RateLimiter,
 	jwtConfig *auth.JWTConfig,
+	tokenChecks []auth.TokenCheck,
 	dashboardHandler *handlers.DashboardHandler,
 	reservatonsHanlder *handlers.ReservationHandler,
 	roomsHandler *handlers.RoomHandler,
 	snacksHandler *handlers.SnackHandler,
+	pricingHandler *handlers.PricingHandler,
+	promoCodeHandler *handlers.PromoCodeHandler,
+	invoiceHandler *handlers.InvoiceHandler,
+	paymentHandler *handlers.PaymentHandler,
+	cancellationPolicyHandler *handlers.CancellationPolicyHandler,
+	costCenterHandler *handlers.CostCenterHandler,
+	budgetHandler *handlers.BudgetHandler,
+	snackStockHandler *handlers.SnackStockHandler,
+	cateringHandler *handlers.CateringHandler,
+	priceHistoryHandler *handlers.PriceHistoryHandler,
+	mediaHandler *handlers.MediaHandler,
+	userManagementHandler *handlers.UserManagementHandler,
+	tokenHandler *handlers.TokenHandler,
+	sessionHandler *handlers.SessionHandler,
+	twoFactorHandler *handlers.TwoFactorHandler,
+	oidcHandler *handlers.OIDCHandler,
 ) *fiber.App {
 	app := fiber.New()
 
@@ -37,31 +54,59 @@ func SetupRouter(
 	public.Get("/swagger.json", handlers.SwaggerUI)
 	public.Post("/auth/register", middleware.ValidateRequest[models.RegisterRequest](), userHandler.Register)
 	public.Post("/auth/login", middleware.ValidateRequest[models.LoginRequest](), userHandler.Login)
+	public.Post("/auth/login/2fa", middleware.ValidateRequest[models.TwoFactorLoginRequest](), twoFactorHandler.VerifyLogin)
+	public.Post("/auth/2fa/setup", middleware.ValidateRequest[models.ChallengeRequest](), twoFactorHandler.SetupChallenge)
+	public.Get("/auth/oidc/login", oidcHandler.Login)
+	public.Get("/auth/oidc/callback", oidcHandler.Callback)
+	public.Post("/auth/refresh", middleware.ValidateRequest[models.RefreshTokenRequest](), tokenHandler.Refresh)
 	public.Post("/password/reset/request", middleware.ValidateRequest[models.ResetPasswordRequest](), passwordResetHandler.RequestReset)
 	public.Post("/password/reset", passwordResetHandler.ResetPassword)
 	public.Get("/download/collection", handlers.DownloadFile) // Download Postman collection
 	public.Get("/recover-password", handlers.RecoverPassword) // Serve the password recovery page
 	public.Get("/login", handlers.Login)                      // Serve the login page
+	// Payment gateway callbacks, authenticated by their signature
+	public.Post("/payments/webhook/:provider", paymentHandler.Webhook)
 
 	// Protected routes
 	protected := app.Group("/api/v1")
-	protected.Use(middleware.AuthMiddleware(jwtConfig))
+	protected.Use(middleware.AuthMiddleware(jwtConfig, tokenChecks...))
 	{
 		// Add protected routes here
+		protected.Post("/auth/logout", tokenHandler.Logout)
+		protected.Get("/sessions", sessionHandler.GetSessions)
+		protected.Delete("/sessions/:id", sessionHandler.RevokeSession)
+		protected.Post("/2fa/setup", twoFactorHandler.Setup)
+		protected.Post("/2fa/enable", middleware.ValidateRequest[models.TwoFactorCodeRequest](), twoFactorHandler.Enable)
+		protected.Post("/2fa/disable", middleware.ValidateRequest[models.TwoFactorCodeRequest](), twoFactorHandler.Disable)
+		protected.Post("/2fa/recovery-codes", middleware.ValidateRequest[models.TwoFactorCodeRequest](), twoFactorHandler.RegenerateRecoveryCodes)
 		protected.Get("/profile/:id", userHandler.GetProfile)
 		protected.Put("/profile/:id", middleware.ValidateRequest[models.UpdateProfileRequest](), userHandler.UpdateProfile)
+		protected.Put("/profile/:id/avatar", userHandler.UpdateAvatar)
 		protected.Get("/rooms", roomsHandler.GetRooms)
 		protected.Get("/rooms/:id/schedule", middleware.ValidateRequest[models.RoomScheduleQuery](), roomsHandler.GetRoomSchedule)
 		protected.Get("/snacks", snacksHandler.GetSnacks)
+		protected.Get("/snack-categories", snacksHandler.GetSnackCategories)
 		protected.Post("/reservation/calculation", middleware.ValidateRequest[models.ReservationCalculationRequest](), reservatonsHanlder.CalculateReservationCost)
 		protected.Post("/reservation", middleware.ValidateRequest[models.CreateReservationRequest](), reservatonsHanlder.CreateReservation)
 		protected.Get("/reservation/:id", reservatonsHanlder.GetReservationByID)
+		protected.Get("/reservation/:id/invoice", invoiceHandler.GetInvoice)
+		protected.Post("/reservation/:id/cancel", reservatonsHanlder.CancelReservation)
+		protected.Post("/reservation/:id/payment", paymentHandler.CreatePayment)
+		protected.Get("/reservation/:id/payments", paymentHandler.GetPayments)
 		protected.Get("/reservations/history", reservatonsHanlder.GetReservationHistory)
 
 	}
 
+	// Kitchen routes for the catering team
+	catering := app.Group("/api/v1/catering")
+	catering.Use(middleware.AuthMiddleware(jwtConfig, tokenChecks...), middleware.RequireRoles(models.RoleCatering, "admin"))
+	{
+		catering.Get("/queue", cateringHandler.GetQueue)
+		catering.Put("/orders/:id/status", middleware.ValidateRequest[models.UpdateCateringStatusRequest](), cateringHandler.UpdateStatus)
+	}
+
 	adminOnly := app.Group("/api/v1/admin")
-	adminOnly.Use(middleware.AdminOnlyMiddleware(jwtConfig.SecretKey))
+	adminOnly.Use(middleware.AdminOnlyMiddleware(jwtConfig.SecretKey, tokenChecks...))
 	{
 		// Add admin-only routes here
 		adminOnly.Get("/dashboard", dashboardHandler.GetDashboardStats)
@@ -70,8 +115,83 @@ func SetupRouter(
 		adminOnly.Post("/rooms", middleware.ValidateRequest[models.CreateRoomRequest](), roomsHandler.CreateRoom)    // Create room
 		adminOnly.Put("/rooms/:id", middleware.ValidateRequest[models.UpdateRoomRequest](), roomsHandler.UpdateRoom) // Update room
 		adminOnly.Delete("/rooms/:id", roomsHandler.DeleteRoom)
+		adminOnly.Get("/rooms/:id/prices", priceHistoryHandler.GetRoomPriceHistory)
+		adminOnly.Post("/rooms/:id/prices", middleware.ValidateRequest[models.SchedulePriceRequest](), priceHistoryHandler.ScheduleRoomPrice)
+		adminOnly.Delete("/rooms/:id/prices/:priceId", priceHistoryHandler.CancelRoomPriceChange)
+		adminOnly.Post("/rooms/:id/photos", mediaHandler.AddRoomPhoto)
+		adminOnly.Delete("/rooms/:id/photos/:photoId", mediaHandler.DeleteRoomPhoto)
+		adminOnly.Get("/room-types", roomsHandler.GetRoomTypes)
+		adminOnly.Post("/room-types", middleware.ValidateRequest[models.CreateRoomTypeRequest](), roomsHandler.CreateRoomType)
+		adminOnly.Get("/locations", roomsHandler.GetLocations)
+		adminOnly.Post("/locations", middleware.ValidateRequest[models.CreateLocationRequest](), roomsHandler.CreateLocation)
+		adminOnly.Get("/locations/:id/menu", snacksHandler.GetLocationMenu)
+		adminOnly.Put("/locations/:id/menu", middleware.ValidateRequest[models.SetLocationMenuRequest](), snacksHandler.SetLocationMenu)
+		// User management
+		adminOnly.Get("/users", userManagementHandler.GetUsers)
+		adminOnly.Get("/users/:id", userManagementHandler.GetUser)
+		adminOnly.Put("/users/:id/role", middleware.ValidateRequest[models.UpdateUserRoleRequest](), userManagementHandler.UpdateRole)
+		adminOnly.Put("/users/:id/status", middleware.ValidateRequest[models.UpdateUserStatusRequest](), userManagementHandler.UpdateStatus)
+		adminOnly.Delete("/users/:id", userManagementHandler.DeleteUser)
+		adminOnly.Post("/users/:id/restore", userManagementHandler.RestoreUser)
+		adminOnly.Post("/users/:id/reset-password", userManagementHandler.ResetPassword)
+		adminOnly.Get("/users/:id/sessions", sessionHandler.GetUserSessions)
+		adminOnly.Post("/users/:id/sign-out", sessionHandler.SignOutUser)
+		adminOnly.Delete("/users/:id/2fa", twoFactorHandler.ResetTwoFactor)
+		adminOnly.Get("/two-factor-policies", twoFactorHandler.GetPolicies)
+		adminOnly.Put("/two-factor-policies/:role", middleware.ValidateRequest[models.UpdateTwoFactorPolicyRequest](), twoFactorHandler.UpdatePolicy)
+		// Pricing management
+		adminOnly.Get("/pricing-rules", pricingHandler.GetPricingRules)
+		adminOnly.Post("/pricing-rules", middleware.ValidateRequest[models.CreatePricingRuleRequest](), pricingHandler.CreatePricingRule)
+		adminOnly.Put("/pricing-rules/:id", middleware.ValidateRequest[models.UpdatePricingRuleRequest](), pricingHandler.UpdatePricingRule)
+		adminOnly.Delete("/pricing-rules/:id", pricingHandler.DeletePricingRule)
+		adminOnly.Get("/holidays", pricingHandler.GetHolidays)
+		adminOnly.Post("/holidays", middleware.ValidateRequest[models.CreateHolidayRequest](), pricingHandler.CreateHoliday)
+		adminOnly.Delete("/holidays/:id", pricingHandler.DeleteHoliday)
+		// Promo code management
+		adminOnly.Get("/promo-codes", promoCodeHandler.GetPromoCodes)
+		adminOnly.Post("/promo-codes", middleware.ValidateRequest[models.CreatePromoCodeRequest](), promoCodeHandler.CreatePromoCode)
+		adminOnly.Put("/promo-codes/:id", middleware.ValidateRequest[models.UpdatePromoCodeRequest](), promoCodeHandler.UpdatePromoCode)
+		// Cancellation policies
+		adminOnly.Get("/cancellation-policies", cancellationPolicyHandler.GetCancellationPolicies)
+		adminOnly.Post("/cancellation-policies", middleware.ValidateRequest[models.CreateCancellationPolicyRequest](), cancellationPolicyHandler.CreateCancellationPolicy)
+		adminOnly.Put("/cancellation-policies/:id", middleware.ValidateRequest[models.UpdateCancellationPolicyRequest](), cancellationPolicyHandler.UpdateCancellationPolicy)
+		adminOnly.Delete("/cancellation-policies/:id", cancellationPolicyHandler.DeleteCancellationPolicy)
+		// Departments and cost centers
+		adminOnly.Get("/departments", costCenterHandler.GetDepartments)
+		adminOnly.Post("/departments", middleware.ValidateRequest[models.CreateDepartmentRequest](), costCenterHandler.CreateDepartment)
+		adminOnly.Get("/cost-centers", costCenterHandler.GetCostCenters)
+		adminOnly.Post("/cost-centers", middleware.ValidateRequest[models.CreateCostCenterRequest](), costCenterHandler.CreateCostCenter)
+		adminOnly.Put("/cost-centers/:id", middleware.ValidateRequest[models.UpdateCostCenterRequest](), costCenterHandler.UpdateCostCenter)
+		adminOnly.Put("/users/:id/cost-center", costCenterHandler.AssignUserCostCenter)
+		adminOnly.Get("/reports/cost-center-spend", costCenterHandler.GetSpendReport)
+		// Budgets
+		adminOnly.Get("/budgets", budgetHandler.GetBudgets)
+		adminOnly.Put("/budgets", middleware.ValidateRequest[models.SetBudgetRequest](), budgetHandler.SetBudget)
+		adminOnly.Delete("/budgets/:id", budgetHandler.DeleteBudget)
+		adminOnly.Post("/reservation/:id/budget-approval", reservatonsHanlder.DecideBudgetApproval)
 		// Snack management
+		adminOnly.Get("/snacks", snacksHandler.GetAllSnacks)
 		adminOnly.Post("/snacks", snacksHandler.CreateSnack) // Create snack
+		adminOnly.Put("/snacks/:id", middleware.ValidateRequest[models.UpdateSnackRequest](), snacksHandler.UpdateSnack)
+		adminOnly.Post("/snacks/:id/archive", snacksHandler.ArchiveSnack)
+		adminOnly.Delete("/snacks/:id", snacksHandler.DeleteSnack)
+		adminOnly.Get("/snacks/:id/prices", priceHistoryHandler.GetSnackPriceHistory)
+		adminOnly.Post("/snacks/:id/prices", middleware.ValidateRequest[models.SchedulePriceRequest](), priceHistoryHandler.ScheduleSnackPrice)
+		adminOnly.Delete("/snacks/:id/prices/:priceId", priceHistoryHandler.CancelSnackPriceChange)
+		adminOnly.Put("/snacks/:id/image", mediaHandler.SetSnackImage)
+		adminOnly.Delete("/snacks/:id/image", mediaHandler.DeleteSnackImage)
+		// Snack categories
+		adminOnly.Get("/snack-categories", snacksHandler.GetSnackCategories)
+		adminOnly.Post("/snack-categories", middleware.ValidateRequest[models.CreateSnackCategoryRequest](), snacksHandler.CreateSnackCategory)
+		adminOnly.Put("/snack-categories/:id", middleware.ValidateRequest[models.UpdateSnackCategoryRequest](), snacksHandler.UpdateSnackCategory)
+		adminOnly.Delete("/snack-categories/:id", snacksHandler.DeleteSnackCategory)
+		adminOnly.Post("/snack-categories/:id/merge", middleware.ValidateRequest[models.MergeSnackCategoryRequest](), snacksHandler.MergeSnackCategory)
+		// Snack stock
+		adminOnly.Get("/snacks/low-stock", snackStockHandler.GetLowStock)
+		adminOnly.Get("/snacks/:id/stock-movements", snackStockHandler.GetStockMovements)
+		adminOnly.Post("/snacks/:id/restock", middleware.ValidateRequest[models.RestockSnackRequest](), snackStockHandler.Restock)
+		adminOnly.Post("/snacks/:id/stock-adjustment", middleware.ValidateRequest[models.AdjustStockRequest](), snackStockHandler.AdjustStock)
+		adminOnly.Put("/snacks/:id/stock-settings", middleware.ValidateRequest[models.UpdateStockSettingsRequest](), snackStockHandler.UpdateStockSettings)
 
 	}
 
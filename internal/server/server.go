@@ -1,15 +1,22 @@
 package server
 
 import (
+	"context"
+	"crypto/x509"
+	"database/sql"
 	"e_meeting/internal/auth"
 	"e_meeting/internal/config"
 	"e_meeting/internal/database"
 	"e_meeting/internal/handlers"
 	"e_meeting/internal/middleware"
+	"e_meeting/internal/payments"
 	"e_meeting/internal/repositories"
 	"e_meeting/internal/services"
+	"e_meeting/internal/storage"
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -17,8 +24,9 @@ import (
 )
 
 type Server struct {
-	app *fiber.App
-	cfg *config.Config
+	app      *fiber.App
+	cfg      *config.Config
+	stopJobs context.CancelFunc // Stops the background jobs
 }
 
 func NewServer(cfg *config.Config) *Server {
@@ -35,11 +43,20 @@ func NewServer(cfg *config.Config) *Server {
 	passwordResetRepo := repositories.NewPasswordResetRepository(db.GormDB())
 
 	// Initialize JWT config
-	fmt.Println(cfg.JWT.TokenDuration, "======================")
 	jwtConfig := auth.NewJWTConfig(
 		cfg.JWT.SecretKey,
-		time.Duration(cfg.JWT.TokenDuration)*time.Hour,
+		time.Duration(cfg.JWT.AccessTokenMinutes)*time.Minute,
 	)
+	userStates := auth.NewUserStateCache(
+		auth.DBUserStateLoader(db.DB()),
+		time.Duration(cfg.JWT.UserStateCacheSeconds)*time.Second,
+	)
+	sessionCache := auth.NewSessionCache(
+		auth.DBSessionLoader(db.DB()),
+		time.Duration(cfg.JWT.UserStateCacheSeconds)*time.Second,
+	)
+	denylist := auth.NewDenylist(db.DB())
+	tokenChecks := []auth.TokenCheck{userStates.TokenCheck(), sessionCache.TokenCheck(), denylist.TokenCheck()}
 
 	// Initialize services
 	emailService := services.NewEmailService(
@@ -60,22 +77,92 @@ func NewServer(cfg *config.Config) *Server {
 		emailService,
 		cfg,
 	)
-	userService := services.NewUserService(userRepo, jwtConfig)
-	dashboardDb := services.NewDashboardService(db.DB())
-	reservationService := services.NewReservationService(db.DB())
+	store, err := newStorage(cfg)
+	if err != nil {
+		log.Fatalf("Invalid storage configuration: %v", err)
+	}
+	mediaService := services.NewMediaService(db.DB(), store, cfg.Storage.MaxImageBytes)
+	tokenService := services.NewTokenService(db.DB(), jwtConfig, denylist, sessionCache, time.Duration(cfg.JWT.RefreshTokenHours)*time.Hour)
+	twoFactorService := services.NewTwoFactorService(db.DB(), jwtConfig, tokenService, denylist,
+		cfg.TwoFactor.Issuer, time.Duration(cfg.TwoFactor.ChallengeMinutes)*time.Minute)
+	authenticator, ldapAuthenticator, err := newAuthenticator(cfg, db.DB(), userRepo, userStates)
+	if err != nil {
+		log.Fatalf("Invalid login provider configuration: %v", err)
+	}
+	userService := services.NewUserService(userRepo, authenticator, tokenService, twoFactorService, mediaService)
+	var oidcService *services.OIDCService
+	if cfg.OIDC.Issuer != "" {
+		oidcService = services.NewOIDCService(
+			db.DB(),
+			auth.NewOIDCProvider(cfg.OIDC.Issuer, cfg.OIDC.ClientID, cfg.OIDC.ClientSecret, cfg.OIDC.RedirectURL, cfg.OIDC.Scopes, nil),
+			tokenService,
+			twoFactorService,
+			userStates,
+			cfg.OIDC.RoleClaim,
+			services.RoleMapping{Admin: cfg.OIDC.AdminGroups, Catering: cfg.OIDC.CateringGroups},
+			time.Duration(cfg.OIDC.LoginMinutes)*time.Minute,
+		)
+	}
+	pricingLocation, err := time.LoadLocation(cfg.Pricing.Timezone)
+	if err != nil {
+		log.Fatalf("Invalid pricing timezone %q: %v", cfg.Pricing.Timezone, err)
+	}
+	pricingService := services.NewPricingService(db.DB(), &services.PricingEngine{
+		Location:         pricingLocation,
+		MinBillable:      time.Duration(cfg.Pricing.MinBillableMinutes) * time.Minute,
+		BillingIncrement: time.Duration(cfg.Pricing.BillingIncrementMinutes) * time.Minute,
+		TaxRate:          cfg.Tax.Rate,
+		TaxLabel:         cfg.Tax.Label,
+	})
+	promoCodeService := services.NewPromoCodeService(db.DB())
+	priceQuoteService := services.NewPriceQuoteService(db.DB(), time.Duration(cfg.Pricing.QuoteValidityMinutes)*time.Minute)
+	invoiceService := services.NewInvoiceService(db.DB(), cfg.Tax.Label, pricingLocation)
+	cancellationPolicyService := services.NewCancellationPolicyService(db.DB(), pricingLocation)
+	costCenterService := services.NewCostCenterService(db.DB())
+	budgetService := services.NewBudgetService(db.DB(), pricingLocation)
+	snackStockService := services.NewSnackStockService(db.DB())
+	cateringService := services.NewCateringService(db.DB(), pricingLocation, time.Duration(cfg.Catering.LeadTimeMinutes)*time.Minute)
+	dashboardDb := services.NewDashboardService(db.DB(), budgetService)
+	reservationService := services.NewReservationService(db.DB(), pricingService, promoCodeService, priceQuoteService, invoiceService, cancellationPolicyService, costCenterService, budgetService, snackStockService, cateringService, cfg.Snacks.MaxPerVisitor)
+	paymentGateway, err := payments.NewGateway(cfg.Payment.Provider, cfg.Payment.WebhookSecret, cfg.AppEnv == "production")
+	if errors.Is(err, payments.ErrNotConfigured) {
+		log.Printf("Warning: payments are disabled, set PAYMENT_PROVIDER and PAYMENT_WEBHOOK_SECRET to enable them")
+	} else if err != nil {
+		log.Fatalf("Invalid payment configuration: %v", err)
+	}
+	paymentService := services.NewPaymentService(db.DB(), paymentGateway, cfg.Payment.Currency, reservationService)
 	roomService := services.NewRoomService(db.DB())
 	snackService := services.NewSnackService(db.DB())
+	priceHistoryService := services.NewPriceHistoryService(db.DB())
+	userManagementService := services.NewUserManagementService(db.DB(), userStates)
+	sessionService := services.NewSessionService(db.DB(), sessionCache, userStates)
 
 	validator := validator.New()
 
 	// Initialize handlers
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, mediaService.MaxImageBytes())
 	healthHandler := handlers.NewHealthHandler("1.0.0")
 	passwordResetHandler := handlers.NewPasswordResetHandler(passwordResetService)
 	dashboardHandler := handlers.NewDashboardHandler(dashboardDb)
 	reservationHandler := handlers.NewReservationHandler(reservationService)
 	roomHandler := handlers.NewRoomHandler(roomService)
 	snackHandler := handlers.NewSnackHandler(snackService, validator)
+	pricingHandler := handlers.NewPricingHandler(pricingService)
+	promoCodeHandler := handlers.NewPromoCodeHandler(promoCodeService)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+	cancellationPolicyHandler := handlers.NewCancellationPolicyHandler(cancellationPolicyService)
+	costCenterHandler := handlers.NewCostCenterHandler(costCenterService)
+	budgetHandler := handlers.NewBudgetHandler(budgetService)
+	snackStockHandler := handlers.NewSnackStockHandler(snackStockService)
+	cateringHandler := handlers.NewCateringHandler(cateringService)
+	priceHistoryHandler := handlers.NewPriceHistoryHandler(priceHistoryService)
+	mediaHandler := handlers.NewMediaHandler(mediaService)
+	userManagementHandler := handlers.NewUserManagementHandler(userManagementService, passwordResetService)
+	tokenHandler := handlers.NewTokenHandler(tokenService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	oidcHandler := handlers.NewOIDCHandler(oidcService)
 
 	// Initialize rate limiter
 	rateLimiter := middleware.NewRateLimiter(100, time.Hour)
@@ -87,15 +174,123 @@ func NewServer(cfg *config.Config) *Server {
 		passwordResetHandler,
 		rateLimiter,
 		jwtConfig,
+		tokenChecks,
 		dashboardHandler,
 		reservationHandler,
 		roomHandler,
 		snackHandler,
+		pricingHandler,
+		promoCodeHandler,
+		invoiceHandler,
+		paymentHandler,
+		cancellationPolicyHandler,
+		costCenterHandler,
+		budgetHandler,
+		snackStockHandler,
+		cateringHandler,
+		priceHistoryHandler,
+		mediaHandler,
+		userManagementHandler,
+		tokenHandler,
+		sessionHandler,
+		twoFactorHandler,
+		oidcHandler,
 	)
 
+	// Serve uploads stored on local disk
+	if local, ok := store.(*storage.LocalStorage); ok {
+		router.Static(cfg.Storage.LocalURL, local.Dir())
+	}
+
+	// Keep the accounts of directory users in step with the directory
+	jobs, stopJobs := context.WithCancel(context.Background())
+	if ldapAuthenticator != nil && cfg.LDAP.SyncMinutes > 0 {
+		go ldapAuthenticator.Run(jobs, time.Duration(cfg.LDAP.SyncMinutes)*time.Minute)
+	}
+
 	return &Server{
-		app: router,
-		cfg: cfg,
+		app:      router,
+		cfg:      cfg,
+		stopJobs: stopJobs,
+	}
+}
+
+// newAuthenticator returns the login providers configured in AUTH_PROVIDERS, and
+// the LDAP one separately when it is among them so its sync can be scheduled
+func newAuthenticator(cfg *config.Config, db *sql.DB, userRepo repositories.UserRepository, userStates *auth.UserStateCache) (services.Authenticator, *services.LDAPAuthenticator, error) {
+	var authenticators []services.Authenticator
+	var ldapAuthenticator *services.LDAPAuthenticator
+	for _, provider := range cfg.AuthProviders {
+		switch provider {
+		case "password":
+			authenticators = append(authenticators, services.NewPasswordAuthenticator(userRepo))
+		case "ldap":
+			directory, err := newLDAPDirectory(cfg)
+			if err != nil {
+				return nil, nil, err
+			}
+			ldapAuthenticator = services.NewLDAPAuthenticator(db, directory, userStates,
+				services.RoleMapping{Admin: cfg.LDAP.AdminGroups, Catering: cfg.LDAP.CateringGroups})
+			authenticators = append(authenticators, ldapAuthenticator)
+		default:
+			return nil, nil, fmt.Errorf("unknown login provider %q", provider)
+		}
+	}
+	if len(authenticators) == 0 {
+		return nil, nil, fmt.Errorf("no login provider configured")
+	}
+	return services.NewChainAuthenticator(authenticators...), ldapAuthenticator, nil
+}
+
+// newLDAPDirectory returns the directory configured for LDAP logins
+func newLDAPDirectory(cfg *config.Config) (*auth.LDAPDirectory, error) {
+	if cfg.LDAP.URL == "" || cfg.LDAP.UserBaseDN == "" {
+		return nil, fmt.Errorf("LDAP_URL and LDAP_USER_BASE_DN are required for ldap logins")
+	}
+
+	var rootCAs *x509.CertPool
+	if cfg.LDAP.CACert != "" {
+		pem, err := os.ReadFile(cfg.LDAP.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading LDAP CA certificate: %v", err)
+		}
+		rootCAs = x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", cfg.LDAP.CACert)
+		}
+	}
+
+	return auth.NewLDAPDirectory(auth.LDAPConfig{
+		URL:                cfg.LDAP.URL,
+		StartTLS:           cfg.LDAP.StartTLS,
+		InsecureSkipVerify: cfg.LDAP.InsecureSkipVerify,
+		RootCAs:            rootCAs,
+		BindDN:             cfg.LDAP.BindDN,
+		BindPassword:       cfg.LDAP.BindPassword,
+		UserBaseDN:         cfg.LDAP.UserBaseDN,
+		UserFilter:         cfg.LDAP.UserFilter,
+		UsernameAttr:       cfg.LDAP.UsernameAttr,
+		EmailAttr:          cfg.LDAP.EmailAttr,
+		GroupBaseDN:        cfg.LDAP.GroupBaseDN,
+		GroupFilter:        cfg.LDAP.GroupFilter,
+		Timeout:            time.Duration(cfg.LDAP.TimeoutSeconds) * time.Second,
+	}), nil
+}
+
+// newStorage returns the object store configured for uploads
+func newStorage(cfg *config.Config) (storage.Storage, error) {
+	switch cfg.Storage.Driver {
+	case "local":
+		return storage.NewLocalStorage(cfg.Storage.LocalDir, cfg.Storage.LocalURL), nil
+	case "s3":
+		endpoint := cfg.Storage.S3Endpoint
+		if endpoint == "" {
+			endpoint = storage.R2Endpoint(cfg.CloudflareR2AccountID)
+		}
+		return storage.NewS3Storage(endpoint, cfg.CloudflareR2APIKey, cfg.CloudflareR2APISecret,
+			cfg.CloudflareR2BucketName, cfg.CloudflareR2PublicURL, cfg.Storage.S3UseSSL)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
 	}
 }
 
@@ -106,5 +301,6 @@ func (s *Server) Start() error {
 }
 
 func (s *Server) Shutdown() error {
+	s.stopJobs()
 	return s.app.Shutdown()
 }
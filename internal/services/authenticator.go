@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"e_meeting/internal/models"
+	"e_meeting/internal/repositories"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrUserNotFound is returned by an Authenticator that does not know the username
+	ErrUserNotFound = errors.New("invalid credentials, user not found")
+	// ErrPasswordMismatch is returned by an Authenticator that knows the user but not the password
+	ErrPasswordMismatch = errors.New("invalid credentials, password doesn't match")
+)
+
+// Authenticator checks the username and password of a login and returns the
+// account they belong to. Whether the account may sign in is up to the caller.
+type Authenticator interface {
+	Authenticate(ctx context.Context, username, password string) (*models.User, error)
+}
+
+// passwordAuthenticator checks passwords against the bcrypt hashes stored with accounts
+type passwordAuthenticator struct {
+	userRepo repositories.UserRepository
+}
+
+func NewPasswordAuthenticator(userRepo repositories.UserRepository) Authenticator {
+	return &passwordAuthenticator{
+		userRepo: userRepo,
+	}
+}
+
+func (a *passwordAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	// Get user by username
+	user, err := a.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get user by username")
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	// Check password, accounts from an identity provider have none
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		log.Error().Err(err).Msg("Failed to compare password")
+		return nil, ErrPasswordMismatch
+	}
+
+	return user, nil
+}
+
+// chainAuthenticator tries its authenticators in order
+type chainAuthenticator []Authenticator
+
+// NewChainAuthenticator returns an authenticator accepting a login any of
+// authenticators accepts, asking them in order. When all refuse it, the first
+// error other than ErrUserNotFound is returned.
+func NewChainAuthenticator(authenticators ...Authenticator) Authenticator {
+	if len(authenticators) == 1 {
+		return authenticators[0]
+	}
+	return chainAuthenticator(authenticators)
+}
+
+func (c chainAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	var failure error
+	for _, authenticator := range c {
+		user, err := authenticator.Authenticate(ctx, username, password)
+		if err == nil {
+			return user, nil
+		}
+		if failure == nil && !errors.Is(err, ErrUserNotFound) {
+			failure = err
+		}
+	}
+	if failure != nil {
+		return nil, failure
+	}
+	return nil, ErrUserNotFound
+}
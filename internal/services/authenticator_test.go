@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"e_meeting/internal/auth"
+	"e_meeting/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jimlambrt/gldap/testdirectory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeAuthenticator accepts one username and password
+type fakeAuthenticator struct {
+	user     *models.User
+	password string
+	err      error // Returned for every login when set
+	calls    int
+}
+
+func (f *fakeAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	f.calls++
+	switch {
+	case f.err != nil:
+		return nil, f.err
+	case username != f.user.Username:
+		return nil, ErrUserNotFound
+	case password != f.password:
+		return nil, ErrPasswordMismatch
+	}
+	return f.user, nil
+}
+
+func TestPasswordAuthenticator(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	repo := &loginRepository{users: map[string]*models.User{
+		"alice": {ID: uuid.New(), Username: "alice", Password: string(hash), Status: true},
+		"sso":   {ID: uuid.New(), Username: "sso", Password: "", Status: true},
+	}}
+	authenticator := NewPasswordAuthenticator(repo)
+	ctx := context.Background()
+
+	user, err := authenticator.Authenticate(ctx, "alice", "secret123")
+	require.NoError(t, err)
+	assert.Equal(t, repo.users["alice"], user)
+
+	_, err = authenticator.Authenticate(ctx, "alice", "wrong123")
+	assert.ErrorIs(t, err, ErrPasswordMismatch)
+	_, err = authenticator.Authenticate(ctx, "bob", "secret123")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	// Accounts from an identity provider have no password to sign in with
+	_, err = authenticator.Authenticate(ctx, "sso", "")
+	assert.ErrorIs(t, err, ErrPasswordMismatch)
+}
+
+func TestChainAuthenticator(t *testing.T) {
+	local := &fakeAuthenticator{user: &models.User{Username: "alice"}, password: "local"}
+	directory := &fakeAuthenticator{user: &models.User{Username: "bob"}, password: "directory"}
+	chain := NewChainAuthenticator(local, directory)
+	ctx := context.Background()
+
+	user, err := chain.Authenticate(ctx, "alice", "local")
+	require.NoError(t, err)
+	assert.Equal(t, local.user, user)
+	assert.Equal(t, 0, directory.calls, "the first provider accepting a login decides")
+
+	user, err = chain.Authenticate(ctx, "bob", "directory")
+	require.NoError(t, err)
+	assert.Equal(t, directory.user, user)
+
+	// A provider that knows the user explains the failure better than one that does not
+	_, err = chain.Authenticate(ctx, "bob", "wrong")
+	assert.ErrorIs(t, err, ErrPasswordMismatch)
+	_, err = chain.Authenticate(ctx, "carol", "local")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	// An unreachable provider does not keep the others from signing users in
+	unreachable := errors.New("error connecting to LDAP server")
+	directory.err = unreachable
+	_, err = chain.Authenticate(ctx, "alice", "local")
+	assert.NoError(t, err)
+	_, err = chain.Authenticate(ctx, "bob", "directory")
+	assert.ErrorIs(t, err, unreachable)
+
+	// A single provider needs no chain
+	assert.Same(t, local, NewChainAuthenticator(local))
+}
+
+func TestLDAPIdentity(t *testing.T) {
+	entry := &auth.LDAPEntry{
+		DN:       "uid=Alice,ou=people,dc=example,dc=org",
+		Username: "Alice",
+		Email:    "alice@example.com",
+		Groups:   []string{"cn=admins,ou=groups,dc=example,dc=org"},
+	}
+	identity := ldapIdentity("ldap:ldap://localhost/ou=people,dc=example,dc=org", entry)
+
+	assert.Equal(t, "alice", identity.Subject)
+	assert.Equal(t, "Alice", identity.PreferredUsername)
+	assert.True(t, identity.EmailVerified)
+	// The directory always lists the groups, so users in none lose their mapped role
+	assert.True(t, identity.HasGroups)
+	assert.Equal(t, "admin", RoleMapping{Admin: []string{"admins"}}.Role(identity.Groups))
+}
+
+func TestLDAPAuthenticatorRejectsBadCredentials(t *testing.T) {
+	td := testdirectory.Start(t, testdirectory.WithNoTLS(t))
+	td.SetUsers(testdirectory.NewUsers(t, []string{"alice", "svc"})...)
+	config := auth.LDAPConfig{
+		URL:          fmt.Sprintf("ldap://%s:%d", td.Host(), td.Port()),
+		BindDN:       "cn=svc,ou=people,dc=example,dc=org",
+		BindPassword: "password",
+		UserBaseDN:   "ou=people,dc=example,dc=org",
+		UserFilter:   "(cn=%s)",
+		EmailAttr:    "email",
+	}
+	// Refused logins never reach the database
+	authenticator := NewLDAPAuthenticator(nil, auth.NewLDAPDirectory(config), nil, RoleMapping{})
+	ctx := context.Background()
+
+	_, err := authenticator.Authenticate(ctx, "alice", "wrong")
+	assert.ErrorIs(t, err, ErrPasswordMismatch)
+	_, err = authenticator.Authenticate(ctx, "bob", "password")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	// A service account the directory refuses is a configuration error, not a wrong password
+	config.BindPassword = "wrong"
+	authenticator = NewLDAPAuthenticator(nil, auth.NewLDAPDirectory(config), nil, RoleMapping{})
+	_, err = authenticator.Authenticate(ctx, "alice", "password")
+	assert.ErrorContains(t, err, "error binding LDAP service account")
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/models"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const budgetColumns = `
+	b.id, b.cost_center_id, cc.code, cc.name, to_char(b.month, 'YYYY-MM'), b.amount, b.enforcement, b.created_at, b.updated_at`
+
+// BudgetService manages monthly cost center budgets and enforces them at booking time
+type BudgetService struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+func NewBudgetService(db *sql.DB, location *time.Location) *BudgetService {
+	return &BudgetService{
+		db:       db,
+		location: location,
+	}
+}
+
+func scanBudget(row interface{ Scan(...interface{}) error }) (*models.Budget, error) {
+	var budget models.Budget
+	err := row.Scan(
+		&budget.ID, &budget.CostCenterID, &budget.CostCenterCode, &budget.CostCenterName, &budget.Month,
+		&budget.Amount, &budget.Enforcement, &budget.CreatedAt, &budget.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// monthBounds returns the first day of the month of t and of the following month,
+// as wall clock times comparable with reservation times
+func monthBounds(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// committedSpend sums what a cost center is already committed to in a month:
+// every reservation that is not cancelled, plus fees of cancelled ones
+func committedSpend(q queryer, costCenterID uuid.UUID, from, to time.Time) (float64, error) {
+	var committed float64
+	err := q.QueryRow(`
+		SELECT
+			COALESCE((SELECT SUM(r.price)
+				FROM reservations r
+				WHERE r.cost_center_id = $1 AND r.start_time >= $2 AND r.start_time < $3
+					AND r.status != 'cancelled'), 0)
+			+ COALESCE((SELECT SUM(l.amount)
+				FROM reservation_ledger l
+				JOIN reservations r ON r.id = l.reservation_id
+				WHERE r.cost_center_id = $1 AND r.start_time >= $2 AND r.start_time < $3
+					AND l.entry_type = 'cancellation_fee'), 0)
+	`, costCenterID, from, to).Scan(&committed)
+	if err != nil {
+		return 0, fmt.Errorf("error computing committed spend: %v", err)
+	}
+	return committed, nil
+}
+
+// CheckBooking checks a booking total against the budget of its cost center for the
+// month the reservation starts. The budget row is locked so concurrent bookings are
+// checked one after the other.
+// Returns:
+//   - Whether the booking needs approval, or an error starting with "budget exceeded" when it is blocked
+func (s *BudgetService) CheckBooking(tx *sql.Tx, costCenterID uuid.UUID, start time.Time, total float64) (bool, error) {
+	from, to := monthBounds(start)
+
+	var budgetAmount float64
+	var enforcement models.BudgetEnforcement
+	err := tx.QueryRow(`
+		SELECT amount, enforcement
+		FROM cost_center_budgets
+		WHERE cost_center_id = $1 AND month = $2::date
+		FOR UPDATE
+	`, costCenterID, from.Format("2006-01-02")).Scan(&budgetAmount, &enforcement)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("error fetching budget: %v", err)
+	}
+
+	committed, err := committedSpend(tx, costCenterID, from, to)
+	if err != nil {
+		return false, err
+	}
+	return budgetDecision(from, budgetAmount, enforcement, committed, total)
+}
+
+// budgetDecision decides on a booking of total against the budget of month, of
+// which committed is already spent. Bookings within the budget pass, those over
+// it need approval or are blocked depending on enforcement.
+func budgetDecision(month time.Time, budget float64, enforcement models.BudgetEnforcement, committed, total float64) (bool, error) {
+	if roundMoney(committed+total) <= budget {
+		return false, nil
+	}
+
+	if enforcement == models.BudgetEnforcementApproval {
+		return true, nil
+	}
+	return false, fmt.Errorf("budget exceeded: the remaining %s budget of this cost center is %.2f",
+		month.Format("January 2006"), math.Max(roundMoney(budget-committed), 0))
+}
+
+// GetConsumption reports the budgets of the months between from and to (inclusive)
+func (s *BudgetService) GetConsumption(q queryer, from, to time.Time) ([]models.BudgetConsumption, error) {
+	firstMonth, _ := monthBounds(from)
+	_, afterLastMonth := monthBounds(to)
+	now := time.Now().In(s.location)
+
+	rows, err := q.Query(`
+		WITH fees AS (
+			SELECT reservation_id, SUM(amount) as amount
+			FROM reservation_ledger
+			WHERE entry_type = 'cancellation_fee'
+			GROUP BY reservation_id
+		)
+		SELECT
+			b.id, b.cost_center_id, cc.code, cc.name, to_char(b.month, 'YYYY-MM'), b.amount,
+			COALESCE(SUM(r.price) FILTER (WHERE r.status IN ('confirmed', 'completed') AND r.start_time < $3), 0)
+				+ COALESCE(SUM(f.amount), 0) as spent,
+			COALESCE(SUM(r.price) FILTER (WHERE r.status = 'confirmed' AND r.start_time >= $3), 0) as confirmed_upcoming
+		FROM cost_center_budgets b
+		JOIN cost_centers cc ON cc.id = b.cost_center_id
+		LEFT JOIN reservations r ON r.cost_center_id = b.cost_center_id
+			AND r.start_time >= b.month AND r.start_time < b.month + INTERVAL '1 month'
+		LEFT JOIN fees f ON f.reservation_id = r.id
+		WHERE b.month >= $1::date AND b.month < $2::date
+		GROUP BY b.id, b.cost_center_id, cc.code, cc.name, b.month, b.amount
+		ORDER BY b.month ASC, cc.code ASC`,
+		firstMonth.Format("2006-01-02"), afterLastMonth.Format("2006-01-02"), now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying budget consumption: %v", err)
+	}
+	defer rows.Close()
+
+	consumption := []models.BudgetConsumption{}
+	for rows.Next() {
+		var c models.BudgetConsumption
+		err := rows.Scan(
+			&c.BudgetID, &c.CostCenterID, &c.CostCenterCode, &c.CostCenterName, &c.Month, &c.Budget,
+			&c.Spent, &c.ConfirmedUpcoming,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning budget consumption: %v", err)
+		}
+		c.Forecast = roundMoney(c.Spent + c.ConfirmedUpcoming)
+		c.Remaining = roundMoney(c.Budget - c.Forecast)
+		c.ConsumptionPercent = math.Round(c.Spent/c.Budget*10000) / 100
+		c.ForecastPercent = math.Round(c.Forecast/c.Budget*10000) / 100
+		consumption = append(consumption, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating budget consumption: %v", err)
+	}
+	return consumption, nil
+}
+
+// GetBudgets lists budgets, optionally for a single month (YYYY-MM)
+func (s *BudgetService) GetBudgets(month string) (*models.BudgetListResponse, error) {
+	query := `
+		SELECT ` + budgetColumns + `
+		FROM cost_center_budgets b
+		JOIN cost_centers cc ON cc.id = b.cost_center_id`
+	var args []interface{}
+	if month != "" {
+		start, err := time.Parse("2006-01", month)
+		if err != nil {
+			return nil, fmt.Errorf("invalid month format (required: YYYY-MM)")
+		}
+		query += ` WHERE b.month = $1::date`
+		args = append(args, start.Format("2006-01-02"))
+	}
+	query += ` ORDER BY b.month DESC, cc.code ASC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying budgets: %v", err)
+	}
+	defer rows.Close()
+
+	budgets := []models.Budget{}
+	for rows.Next() {
+		budget, err := scanBudget(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning budget: %v", err)
+		}
+		budgets = append(budgets, *budget)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating budgets: %v", err)
+	}
+
+	return &models.BudgetListResponse{
+		Budgets: budgets,
+	}, nil
+}
+
+// SetBudget creates the budget of a cost center for a month or replaces the existing one
+func (s *BudgetService) SetBudget(req *models.SetBudgetRequest) (*models.Budget, error) {
+	month, err := time.Parse("2006-01", req.Month)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month format (required: YYYY-MM)")
+	}
+
+	var id uuid.UUID
+	err = s.db.QueryRow(`
+		INSERT INTO cost_center_budgets (cost_center_id, month, amount, enforcement)
+		VALUES ($1, $2::date, $3, $4)
+		ON CONFLICT (cost_center_id, month)
+		DO UPDATE SET amount = EXCLUDED.amount, enforcement = EXCLUDED.enforcement, updated_at = NOW()
+		RETURNING id
+	`, req.CostCenterID, month.Format("2006-01-02"), req.Amount, req.Enforcement).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, fmt.Errorf("cost center not found")
+		}
+		return nil, fmt.Errorf("error saving budget: %v", err)
+	}
+
+	budget, err := scanBudget(s.db.QueryRow(`
+		SELECT `+budgetColumns+`
+		FROM cost_center_budgets b
+		JOIN cost_centers cc ON cc.id = b.cost_center_id
+		WHERE b.id = $1`, id))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching budget: %v", err)
+	}
+	return budget, nil
+}
+
+func (s *BudgetService) DeleteBudget(id uuid.UUID) error {
+	result, err := s.db.Exec(`DELETE FROM cost_center_budgets WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting budget: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("budget not found")
+	}
+	return nil
+}
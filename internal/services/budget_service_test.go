@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"e_meeting/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonthBounds(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*3600)
+
+	// Wall clock month is kept even when the instant falls in another month in UTC
+	from, to := monthBounds(time.Date(2026, 3, 1, 2, 0, 0, 0, jakarta))
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), to)
+
+	from, to = monthBounds(time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), to)
+}
+
+func TestBudgetDecision(t *testing.T) {
+	month := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Bookings using up exactly the rest of the budget pass either way
+	for _, enforcement := range []models.BudgetEnforcement{models.BudgetEnforcementBlock, models.BudgetEnforcementApproval} {
+		needsApproval, err := budgetDecision(month, 1000, enforcement, 700, 300)
+		assert.NoError(t, err)
+		assert.False(t, needsApproval)
+	}
+
+	// Cents lost to floating point do not tip a booking over the budget
+	needsApproval, err := budgetDecision(month, 0.3, models.BudgetEnforcementBlock, 0.1, 0.2)
+	assert.NoError(t, err)
+	assert.False(t, needsApproval)
+
+	// Over budget, approval enforcement lets the booking wait for an admin
+	needsApproval, err = budgetDecision(month, 1000, models.BudgetEnforcementApproval, 700, 300.01)
+	assert.NoError(t, err)
+	assert.True(t, needsApproval)
+
+	// Block enforcement rejects it and reports what is left
+	needsApproval, err = budgetDecision(month, 1000, models.BudgetEnforcementBlock, 700, 300.01)
+	assert.EqualError(t, err, "budget exceeded: the remaining March 2026 budget of this cost center is 300.00")
+	assert.False(t, needsApproval)
+
+	// Spending already past the budget leaves nothing rather than a negative amount
+	_, err = budgetDecision(month, 1000, models.BudgetEnforcementBlock, 1200, 50)
+	assert.EqualError(t, err, "budget exceeded: the remaining March 2026 budget of this cost center is 0.00")
+}
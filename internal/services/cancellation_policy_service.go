@@ -0,0 +1,297 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const cancellationPolicyColumns = `
+	id, room_type_id, name, free_cancellation_hours, late_cancellation_fee_percent, no_show_fee_percent, created_at, updated_at`
+
+// CancellationPolicyService manages cancellation policies and settles the fee
+// and refund of cancelled reservations in the reservation ledger
+type CancellationPolicyService struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+func NewCancellationPolicyService(db *sql.DB, location *time.Location) *CancellationPolicyService {
+	return &CancellationPolicyService{
+		db:       db,
+		location: location,
+	}
+}
+
+func scanCancellationPolicy(row interface{ Scan(...interface{}) error }) (*models.CancellationPolicy, error) {
+	var policy models.CancellationPolicy
+	err := row.Scan(
+		&policy.ID, &policy.RoomTypeID, &policy.Name, &policy.FreeCancellationHours,
+		&policy.LateCancellationFeePercent, &policy.NoShowFeePercent, &policy.CreatedAt, &policy.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// cancellationFee computes the fee for cancelling a reservation at now.
+// Reservation times are wall clock times, so now must be a wall clock time too.
+func cancellationFee(policy *models.CancellationPolicy, price float64, start, now time.Time) (float64, string) {
+	if policy == nil {
+		return 0, ""
+	}
+
+	if !now.Before(start) {
+		return roundMoney(price * policy.NoShowFeePercent / 100),
+			fmt.Sprintf("No-show fee (%g%% of %.2f)", policy.NoShowFeePercent, price)
+	}
+	if start.Sub(now) >= time.Duration(policy.FreeCancellationHours)*time.Hour {
+		return 0, ""
+	}
+	return roundMoney(price * policy.LateCancellationFeePercent / 100),
+		fmt.Sprintf("Late cancellation fee, less than %d hours before start (%g%% of %.2f)",
+			policy.FreeCancellationHours, policy.LateCancellationFeePercent, price)
+}
+
+// wallClock returns the current time in the configured location as a wall clock
+// time in UTC, comparable with reservation start and end times
+func (s *CancellationPolicyService) wallClock() time.Time {
+	now := time.Now().In(s.location)
+	return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), time.UTC)
+}
+
+// policyForRoomType returns the policy of a room type, falling back to the default policy.
+// Returns nil when no policy applies, in which case cancellation is free.
+func (s *CancellationPolicyService) policyForRoomType(q queryer, roomTypeID *uuid.UUID) (*models.CancellationPolicy, error) {
+	policy, err := scanCancellationPolicy(q.QueryRow(`
+		SELECT `+cancellationPolicyColumns+`
+		FROM cancellation_policies
+		WHERE room_type_id = $1 OR room_type_id IS NULL
+		ORDER BY room_type_id NULLS LAST
+		LIMIT 1
+	`, roomTypeID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error fetching cancellation policy: %v", err)
+	}
+	return policy, nil
+}
+
+// Settle computes the cancellation fee of a reservation and records the fee and
+// the refund of any amount paid beyond it in the ledger
+func (s *CancellationPolicyService) Settle(tx *sql.Tx, reservationID uuid.UUID, roomTypeID *uuid.UUID, price float64, start time.Time) (*models.CancelReservationResponse, error) {
+	policy, err := s.policyForRoomType(tx, roomTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var paid float64
+	err = tx.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0)
+		FROM payments
+		WHERE reservation_id = $1 AND status = 'succeeded'
+	`, reservationID).Scan(&paid)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reservation payments: %v", err)
+	}
+
+	fee, feeDescription := cancellationFee(policy, price, start, s.wallClock())
+	response := &models.CancelReservationResponse{
+		ReservationID:   reservationID,
+		Status:          string(models.ReservationStatusCancelled),
+		CancellationFee: fee,
+		AmountPaid:      paid,
+		RefundAmount:    roundMoney(max(paid-fee, 0)),
+		Ledger:          []models.LedgerEntry{},
+	}
+	var policyID *uuid.UUID
+	if policy != nil {
+		response.PolicyName = policy.Name
+		policyID = &policy.ID
+	}
+
+	record := func(entryType models.LedgerEntryType, amount float64, description string) error {
+		entry := models.LedgerEntry{EntryType: entryType, Amount: amount, Description: description}
+		err := tx.QueryRow(`
+			INSERT INTO reservation_ledger (reservation_id, entry_type, amount, description, cancellation_policy_id)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at
+		`, reservationID, entryType, amount, description, policyID).Scan(&entry.ID, &entry.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("error recording ledger entry: %v", err)
+		}
+		response.Ledger = append(response.Ledger, entry)
+		return nil
+	}
+
+	if fee > 0 {
+		if err := record(models.LedgerCancellationFee, fee, feeDescription); err != nil {
+			return nil, err
+		}
+	}
+	if response.RefundAmount > 0 {
+		if err := record(models.LedgerRefund, response.RefundAmount, "Refund of the amount paid minus the cancellation fee"); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// ledgerEntries lists the ledger entries of a reservation in the order they were recorded
+func (s *CancellationPolicyService) ledgerEntries(q queryer, reservationID uuid.UUID) ([]models.LedgerEntry, error) {
+	rows, err := q.Query(`
+		SELECT id, entry_type, amount, description, created_at
+		FROM reservation_ledger
+		WHERE reservation_id = $1
+		ORDER BY created_at ASC
+	`, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying ledger entries: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []models.LedgerEntry
+	for rows.Next() {
+		var entry models.LedgerEntry
+		if err := rows.Scan(&entry.ID, &entry.EntryType, &entry.Amount, &entry.Description, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning ledger entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ledger entries: %v", err)
+	}
+	return entries, nil
+}
+
+func (s *CancellationPolicyService) GetCancellationPolicies() (*models.CancellationPolicyListResponse, error) {
+	rows, err := s.db.Query(`
+		SELECT ` + cancellationPolicyColumns + `
+		FROM cancellation_policies
+		ORDER BY room_type_id NULLS FIRST, name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying cancellation policies: %v", err)
+	}
+	defer rows.Close()
+
+	policies := []models.CancellationPolicy{}
+	for rows.Next() {
+		policy, err := scanCancellationPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning cancellation policy: %v", err)
+		}
+		policies = append(policies, *policy)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cancellation policies: %v", err)
+	}
+
+	return &models.CancellationPolicyListResponse{
+		Policies: policies,
+	}, nil
+}
+
+func (s *CancellationPolicyService) CreateCancellationPolicy(req *models.CreateCancellationPolicyRequest) (*models.CancellationPolicy, error) {
+	noShowFee := 100.0
+	if req.NoShowFeePercent != nil {
+		noShowFee = *req.NoShowFeePercent
+	}
+
+	policy, err := scanCancellationPolicy(s.db.QueryRow(`
+		INSERT INTO cancellation_policies (
+			room_type_id, name, free_cancellation_hours, late_cancellation_fee_percent, no_show_fee_percent
+		) VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+cancellationPolicyColumns,
+		req.RoomTypeID, req.Name, req.FreeCancellationHours, req.LateCancellationFeePercent, noShowFee,
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return nil, fmt.Errorf("cancellation policy already exists for this room type")
+			case "23503":
+				return nil, fmt.Errorf("room type not found")
+			}
+		}
+		return nil, fmt.Errorf("error creating cancellation policy: %v", err)
+	}
+
+	return policy, nil
+}
+
+func (s *CancellationPolicyService) UpdateCancellationPolicy(id uuid.UUID, req *models.UpdateCancellationPolicyRequest) (*models.CancellationPolicy, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	policy, err := scanCancellationPolicy(tx.QueryRow(`SELECT `+cancellationPolicyColumns+` FROM cancellation_policies WHERE id = $1 FOR UPDATE`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("cancellation policy not found")
+		}
+		return nil, fmt.Errorf("error fetching cancellation policy: %v", err)
+	}
+
+	// Update only provided fields
+	if req.Name != nil {
+		policy.Name = *req.Name
+	}
+	if req.FreeCancellationHours != nil {
+		policy.FreeCancellationHours = *req.FreeCancellationHours
+	}
+	if req.LateCancellationFeePercent != nil {
+		policy.LateCancellationFeePercent = *req.LateCancellationFeePercent
+	}
+	if req.NoShowFeePercent != nil {
+		policy.NoShowFeePercent = *req.NoShowFeePercent
+	}
+
+	updated, err := scanCancellationPolicy(tx.QueryRow(`
+		UPDATE cancellation_policies
+		SET name = $1, free_cancellation_hours = $2, late_cancellation_fee_percent = $3, no_show_fee_percent = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING `+cancellationPolicyColumns,
+		policy.Name, policy.FreeCancellationHours, policy.LateCancellationFeePercent, policy.NoShowFeePercent, id,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error updating cancellation policy: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return updated, nil
+}
+
+func (s *CancellationPolicyService) DeleteCancellationPolicy(id uuid.UUID) error {
+	result, err := s.db.Exec(`DELETE FROM cancellation_policies WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting cancellation policy: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("cancellation policy not found")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"e_meeting/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancellationFee(t *testing.T) {
+	policy := &models.CancellationPolicy{
+		FreeCancellationHours:      24,
+		LateCancellationFeePercent: 50,
+		NoShowFeePercent:           100,
+	}
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	fee, _ := cancellationFee(policy, 200000, start, start.Add(-48*time.Hour))
+	assert.Equal(t, 0.0, fee, "free before the window")
+
+	fee, _ = cancellationFee(policy, 200000, start, start.Add(-24*time.Hour))
+	assert.Equal(t, 0.0, fee, "free exactly at the window boundary")
+
+	fee, description := cancellationFee(policy, 200000, start, start.Add(-2*time.Hour))
+	assert.Equal(t, 100000.0, fee)
+	assert.Contains(t, description, "Late cancellation")
+
+	fee, description = cancellationFee(policy, 200000, start, start.Add(30*time.Minute))
+	assert.Equal(t, 200000.0, fee)
+	assert.Contains(t, description, "No-show")
+
+	fee, _ = cancellationFee(nil, 200000, start, start)
+	assert.Equal(t, 0.0, fee, "no policy means free cancellation")
+}
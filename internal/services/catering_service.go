@@ -0,0 +1,270 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// CateringService runs the kitchen side of snack orders: delivery times,
+// the lead time rule and the preparation queue
+type CateringService struct {
+	db       *sql.DB
+	location *time.Location
+	leadTime time.Duration
+}
+
+func NewCateringService(db *sql.DB, location *time.Location, leadTime time.Duration) *CateringService {
+	return &CateringService{
+		db:       db,
+		location: location,
+		leadTime: leadTime,
+	}
+}
+
+// checkDeliveryTime resolves when the snacks of a reservation starting at start are delivered
+// and checks that the kitchen gets at least leadTime to prepare them
+func checkDeliveryTime(start time.Time, requested *time.Time, now time.Time, leadTime time.Duration) (time.Time, error) {
+	deliverAt := start
+	if requested != nil {
+		deliverAt = *requested
+	}
+	if deliverAt.After(start) {
+		return time.Time{}, fmt.Errorf("snack delivery time must not be after the reservation start time")
+	}
+	if deliverAt.Sub(now) < leadTime {
+		return time.Time{}, fmt.Errorf("snack orders must be placed at least %d minutes before delivery", int(leadTime.Minutes()))
+	}
+	return deliverAt, nil
+}
+
+// cateringTransitionAllowed reports whether an order may move from one status to another.
+// Orders only move forward; a step may be skipped.
+func cateringTransitionAllowed(from, to models.CateringStatus) bool {
+	rank := map[models.CateringStatus]int{
+		models.CateringQueued:    0,
+		models.CateringPreparing: 1,
+		models.CateringDelivered: 2,
+	}
+	fromRank, ok := rank[from]
+	if !ok {
+		return false
+	}
+	toRank, ok := rank[to]
+	return ok && toRank > fromRank
+}
+
+// DeliveryTime checks the requested delivery time of a snack order against the lead time rule
+func (s *CateringService) DeliveryTime(start time.Time, requested *time.Time) (time.Time, error) {
+	return checkDeliveryTime(start, requested, time.Now(), s.leadTime)
+}
+
+// CreateOrder queues the snacks of a reservation for the kitchen inside the caller's transaction
+func (s *CateringService) CreateOrder(tx *sql.Tx, reservationID uuid.UUID, deliverAt time.Time, notes string) error {
+	var notesPtr *string
+	if notes != "" {
+		notesPtr = &notes
+	}
+	_, err := tx.Exec(`
+		INSERT INTO catering_orders (reservation_id, deliver_at, notes)
+		VALUES ($1, $2, $3)
+	`, reservationID, deliverAt, notesPtr)
+	if err != nil {
+		return fmt.Errorf("error creating catering order: %v", err)
+	}
+	return nil
+}
+
+const cateringOrderQuery = `
+	SELECT
+		co.id, co.reservation_id, co.deliver_at, r.start_time, co.status, co.notes,
+		rm.id, rm.name, l.id, l.name, r.visitor_count, co.status_updated_at
+	FROM catering_orders co
+	JOIN reservations r ON r.id = co.reservation_id
+	JOIN rooms rm ON rm.id = r.room_id
+	LEFT JOIN locations l ON l.id = rm.location_id`
+
+func scanCateringOrder(row interface{ Scan(...interface{}) error }) (*models.CateringOrder, error) {
+	var order models.CateringOrder
+	err := row.Scan(
+		&order.ID, &order.ReservationID, &order.DeliverAt, &order.MeetingStart, &order.Status, &order.Notes,
+		&order.RoomID, &order.RoomName, &order.LocationID, &order.LocationName, &order.VisitorCount, &order.StatusUpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	order.Items = []models.CateringItem{}
+	return &order, nil
+}
+
+// loadItems fills in the snacks to prepare for each order
+func loadItems(q queryer, orders []*models.CateringOrder) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	byReservation := make(map[uuid.UUID]*models.CateringOrder)
+	reservationIDs := make([]uuid.UUID, 0, len(orders))
+	for _, order := range orders {
+		byReservation[order.ReservationID] = order
+		reservationIDs = append(reservationIDs, order.ReservationID)
+	}
+
+	rows, err := q.Query(`
+		SELECT rs.reservation_id, s.id, s.name, c.name, rs.quantity, s.dietary_tags, s.allergens
+		FROM reservation_snacks rs
+		JOIN snacks s ON s.id = rs.snack_id
+		JOIN snack_categories c ON c.id = s.category_id
+		WHERE rs.reservation_id = ANY($1)
+		ORDER BY c.sort_order, c.name, s.name
+	`, pq.Array(reservationIDs))
+	if err != nil {
+		return fmt.Errorf("error querying catering items: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reservationID uuid.UUID
+		var item models.CateringItem
+		err := rows.Scan(&reservationID, &item.SnackID, &item.Name, &item.Category, &item.Quantity,
+			pq.Array(&item.DietaryTags), pq.Array(&item.Allergens))
+		if err != nil {
+			return fmt.Errorf("error scanning catering item: %v", err)
+		}
+		order := byReservation[reservationID]
+		order.Items = append(order.Items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating catering items: %v", err)
+	}
+	return nil
+}
+
+// GetQueue lists the catering orders to deliver on a day in delivery order,
+// skipping orders of cancelled reservations
+func (s *CateringService) GetQueue(query *models.CateringQueueQuery) (*models.CateringQueueResponse, error) {
+	var day time.Time
+	if query.Date == "" {
+		now := time.Now().In(s.location)
+		day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	} else {
+		parsed, err := time.Parse("2006-01-02", query.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date, expected YYYY-MM-DD")
+		}
+		day = parsed
+	}
+
+	var locationID *uuid.UUID
+	if query.LocationID != "" {
+		id, err := uuid.Parse(query.LocationID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid location ID")
+		}
+		locationID = &id
+	}
+
+	switch models.CateringStatus(query.Status) {
+	case "", models.CateringQueued, models.CateringPreparing, models.CateringDelivered:
+	default:
+		return nil, fmt.Errorf("invalid status, expected queued, preparing or delivered")
+	}
+
+	rows, err := s.db.Query(cateringOrderQuery+`
+		WHERE co.deliver_at >= $1 AND co.deliver_at < $2
+			AND r.status != 'cancelled'
+			AND ($3::uuid IS NULL OR rm.location_id = $3)
+			AND ($4 = '' OR co.status = $4)
+		ORDER BY co.deliver_at ASC, rm.name ASC
+	`, day, day.AddDate(0, 0, 1), locationID, query.Status)
+	if err != nil {
+		return nil, fmt.Errorf("error querying catering queue: %v", err)
+	}
+	defer rows.Close()
+
+	var orders []*models.CateringOrder
+	for rows.Next() {
+		order, err := scanCateringOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning catering order: %v", err)
+		}
+		orders = append(orders, order)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating catering queue: %v", err)
+	}
+	rows.Close()
+
+	if err := loadItems(s.db, orders); err != nil {
+		return nil, err
+	}
+
+	response := &models.CateringQueueResponse{
+		Date:   day.Format("2006-01-02"),
+		Orders: []models.CateringOrder{},
+	}
+	for _, order := range orders {
+		response.Orders = append(response.Orders, *order)
+	}
+	return response, nil
+}
+
+// UpdateStatus moves a catering order forward in the kitchen workflow
+func (s *CateringService) UpdateStatus(orderID, userID uuid.UUID, status models.CateringStatus) (*models.CateringOrder, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var current models.CateringStatus
+	var reservationStatus string
+	err = tx.QueryRow(`
+		SELECT co.status, r.status
+		FROM catering_orders co
+		JOIN reservations r ON r.id = co.reservation_id
+		WHERE co.id = $1
+		FOR UPDATE OF co
+	`, orderID).Scan(&current, &reservationStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("catering order not found")
+		}
+		return nil, fmt.Errorf("error fetching catering order: %v", err)
+	}
+	if models.ReservationStatus(reservationStatus) == models.ReservationStatusCancelled {
+		return nil, fmt.Errorf("reservation was cancelled")
+	}
+	if !cateringTransitionAllowed(current, status) {
+		return nil, fmt.Errorf("invalid status transition from %s to %s", current, status)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE catering_orders
+		SET status = $1, status_updated_by = $2, status_updated_at = NOW(), updated_at = NOW()
+		WHERE id = $3
+	`, status, userID, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("error updating catering order: %v", err)
+	}
+
+	order, err := scanCateringOrder(tx.QueryRow(cateringOrderQuery+` WHERE co.id = $1`, orderID))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching catering order: %v", err)
+	}
+	if err := loadItems(tx, []*models.CateringOrder{order}); err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return order, nil
+}
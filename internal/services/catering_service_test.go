@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"e_meeting/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDeliveryTime(t *testing.T) {
+	now := time.Date(2026, 5, 4, 8, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 5, 4, 13, 0, 0, 0, time.UTC)
+	lead := 2 * time.Hour
+
+	// Delivery defaults to the start of the meeting
+	deliverAt, err := checkDeliveryTime(start, nil, now, lead)
+	assert.NoError(t, err)
+	assert.Equal(t, start, deliverAt)
+
+	// An earlier delivery is kept
+	early := start.Add(-30 * time.Minute)
+	deliverAt, err = checkDeliveryTime(start, &early, now, lead)
+	assert.NoError(t, err)
+	assert.Equal(t, early, deliverAt)
+
+	// Delivery after the meeting started is rejected
+	late := start.Add(time.Minute)
+	_, err = checkDeliveryTime(start, &late, now, lead)
+	assert.EqualError(t, err, "snack delivery time must not be after the reservation start time")
+
+	// The lead time is measured up to the delivery, exactly the lead time is enough
+	exact := now.Add(lead)
+	_, err = checkDeliveryTime(start, &exact, now, lead)
+	assert.NoError(t, err)
+	_, err = checkDeliveryTime(start, nil, start.Add(-lead+time.Minute), lead)
+	assert.EqualError(t, err, "snack orders must be placed at least 120 minutes before delivery")
+}
+
+func TestCateringTransitionAllowed(t *testing.T) {
+	assert.True(t, cateringTransitionAllowed(models.CateringQueued, models.CateringPreparing))
+	assert.True(t, cateringTransitionAllowed(models.CateringPreparing, models.CateringDelivered))
+	assert.True(t, cateringTransitionAllowed(models.CateringQueued, models.CateringDelivered))
+
+	assert.False(t, cateringTransitionAllowed(models.CateringQueued, models.CateringQueued))
+	assert.False(t, cateringTransitionAllowed(models.CateringDelivered, models.CateringPreparing))
+	assert.False(t, cateringTransitionAllowed(models.CateringPreparing, "cancelled"))
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"e_meeting/internal/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const costCenterColumns = `
+	cc.id, cc.department_id, d.name, cc.code, cc.name, cc.active, cc.created_at, cc.updated_at`
+
+// CostCenterService manages departments and cost centers and reports their spend
+type CostCenterService struct {
+	db *sql.DB
+}
+
+func NewCostCenterService(db *sql.DB) *CostCenterService {
+	return &CostCenterService{
+		db: db,
+	}
+}
+
+func scanCostCenter(row interface{ Scan(...interface{}) error }) (*models.CostCenter, error) {
+	var costCenter models.CostCenter
+	err := row.Scan(
+		&costCenter.ID, &costCenter.DepartmentID, &costCenter.DepartmentName, &costCenter.Code,
+		&costCenter.Name, &costCenter.Active, &costCenter.CreatedAt, &costCenter.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &costCenter, nil
+}
+
+// ResolveForBooking returns the cost center charged for a booking: the override
+// when given, otherwise the user's default. Returns nil when neither is set.
+func (s *CostCenterService) ResolveForBooking(q queryer, userID uuid.UUID, override *uuid.UUID) (*uuid.UUID, error) {
+	if override == nil {
+		var defaultID *uuid.UUID
+		err := q.QueryRow(`SELECT default_cost_center_id FROM users WHERE id = $1`, userID).Scan(&defaultID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("error fetching user cost center: %v", err)
+		}
+		return defaultID, nil
+	}
+
+	var active bool
+	err := q.QueryRow(`SELECT active FROM cost_centers WHERE id = $1`, *override).Scan(&active)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error fetching cost center: %v", err)
+	}
+	if err == sql.ErrNoRows || !active {
+		return nil, fmt.Errorf("cost center not found or inactive")
+	}
+	return override, nil
+}
+
+func (s *CostCenterService) GetDepartments() (*models.DepartmentListResponse, error) {
+	rows, err := s.db.Query(`SELECT id, name, created_at, updated_at FROM departments ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying departments: %v", err)
+	}
+	defer rows.Close()
+
+	departments := []models.Department{}
+	for rows.Next() {
+		var department models.Department
+		if err := rows.Scan(&department.ID, &department.Name, &department.CreatedAt, &department.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning department: %v", err)
+		}
+		departments = append(departments, department)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating departments: %v", err)
+	}
+
+	return &models.DepartmentListResponse{
+		Departments: departments,
+	}, nil
+}
+
+func (s *CostCenterService) CreateDepartment(req *models.CreateDepartmentRequest) (*models.Department, error) {
+	var department models.Department
+	err := s.db.QueryRow(`
+		INSERT INTO departments (name) VALUES ($1)
+		RETURNING id, name, created_at, updated_at
+	`, req.Name).Scan(&department.ID, &department.Name, &department.CreatedAt, &department.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, fmt.Errorf("department already exists")
+		}
+		return nil, fmt.Errorf("error creating department: %v", err)
+	}
+	return &department, nil
+}
+
+func (s *CostCenterService) GetCostCenters() (*models.CostCenterListResponse, error) {
+	rows, err := s.db.Query(`
+		SELECT ` + costCenterColumns + `
+		FROM cost_centers cc
+		JOIN departments d ON d.id = cc.department_id
+		ORDER BY d.name ASC, cc.code ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying cost centers: %v", err)
+	}
+	defer rows.Close()
+
+	costCenters := []models.CostCenter{}
+	for rows.Next() {
+		costCenter, err := scanCostCenter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning cost center: %v", err)
+		}
+		costCenters = append(costCenters, *costCenter)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cost centers: %v", err)
+	}
+
+	return &models.CostCenterListResponse{
+		CostCenters: costCenters,
+	}, nil
+}
+
+func (s *CostCenterService) getCostCenter(q queryer, id uuid.UUID) (*models.CostCenter, error) {
+	costCenter, err := scanCostCenter(q.QueryRow(`
+		SELECT `+costCenterColumns+`
+		FROM cost_centers cc
+		JOIN departments d ON d.id = cc.department_id
+		WHERE cc.id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("cost center not found")
+		}
+		return nil, fmt.Errorf("error fetching cost center: %v", err)
+	}
+	return costCenter, nil
+}
+
+func (s *CostCenterService) CreateCostCenter(req *models.CreateCostCenterRequest) (*models.CostCenter, error) {
+	var id uuid.UUID
+	err := s.db.QueryRow(`
+		INSERT INTO cost_centers (department_id, code, name) VALUES ($1, $2, $3)
+		RETURNING id
+	`, req.DepartmentID, req.Code, req.Name).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return nil, fmt.Errorf("cost center code already exists")
+			case "23503":
+				return nil, fmt.Errorf("department not found")
+			}
+		}
+		return nil, fmt.Errorf("error creating cost center: %v", err)
+	}
+
+	return s.getCostCenter(s.db, id)
+}
+
+func (s *CostCenterService) UpdateCostCenter(id uuid.UUID, req *models.UpdateCostCenterRequest) (*models.CostCenter, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	costCenter, err := s.getCostCenter(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update only provided fields
+	if req.DepartmentID != nil {
+		costCenter.DepartmentID = *req.DepartmentID
+	}
+	if req.Name != nil {
+		costCenter.Name = *req.Name
+	}
+	if req.Active != nil {
+		costCenter.Active = *req.Active
+	}
+
+	_, err = tx.Exec(`
+		UPDATE cost_centers
+		SET department_id = $1, name = $2, active = $3, updated_at = NOW()
+		WHERE id = $4
+	`, costCenter.DepartmentID, costCenter.Name, costCenter.Active, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, fmt.Errorf("department not found")
+		}
+		return nil, fmt.Errorf("error updating cost center: %v", err)
+	}
+
+	updated, err := s.getCostCenter(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return updated, nil
+}
+
+// AssignUserCostCenter sets or clears the default cost center of a user
+func (s *CostCenterService) AssignUserCostCenter(userID uuid.UUID, req *models.AssignCostCenterRequest) error {
+	if req.CostCenterID != nil {
+		if _, err := s.ResolveForBooking(s.db, userID, req.CostCenterID); err != nil {
+			return err
+		}
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE users SET default_cost_center_id = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+	`, req.CostCenterID, userID)
+	if err != nil {
+		return fmt.Errorf("error assigning cost center: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// parseMonthRange parses an inclusive YYYY-MM range, defaulting to the current month.
+// Returns the start of the first month and the start of the month after the last one.
+func parseMonthRange(startMonth, endMonth string) (time.Time, time.Time, error) {
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	var err error
+	if startMonth != "" {
+		start, err = time.Parse("2006-01", startMonth)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start_month format (required: YYYY-MM)")
+		}
+	}
+	end := start
+	if endMonth != "" {
+		end, err = time.Parse("2006-01", endMonth)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end_month format (required: YYYY-MM)")
+		}
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end_month cannot be before start_month")
+	}
+	return start, end.AddDate(0, 1, 0), nil
+}
+
+// GetSpendReport returns the spend of each cost center per month, by the month the
+// reservation starts. Confirmed and completed reservations count in full and
+// cancelled ones only for the cancellation fee they were charged.
+func (s *CostCenterService) GetSpendReport(query *models.CostCenterSpendQuery) (*models.CostCenterSpendResponse, error) {
+	start, end, err := parseMonthRange(query.StartMonth, query.EndMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		WITH fees AS (
+			SELECT reservation_id, SUM(amount) as amount
+			FROM reservation_ledger
+			WHERE entry_type = 'cancellation_fee'
+			GROUP BY reservation_id
+		)
+		SELECT
+			to_char(date_trunc('month', r.start_time), 'YYYY-MM') as month,
+			cc.id,
+			COALESCE(cc.code, ''),
+			COALESCE(cc.name, ''),
+			COALESCE(d.name, ''),
+			COUNT(*) FILTER (WHERE r.status IN ('confirmed', 'completed')),
+			COALESCE(SUM(r.room_subtotal) FILTER (WHERE r.status IN ('confirmed', 'completed')), 0),
+			COALESCE(SUM(r.snack_subtotal) FILTER (WHERE r.status IN ('confirmed', 'completed')), 0),
+			COALESCE(SUM(r.discount_total) FILTER (WHERE r.status IN ('confirmed', 'completed')), 0),
+			COALESCE(SUM(r.tax_amount) FILTER (WHERE r.status IN ('confirmed', 'completed')), 0),
+			COALESCE(SUM(r.price) FILTER (WHERE r.status IN ('confirmed', 'completed')), 0),
+			COALESCE(SUM(f.amount), 0)
+		FROM reservations r
+		LEFT JOIN fees f ON f.reservation_id = r.id
+		LEFT JOIN cost_centers cc ON cc.id = r.cost_center_id
+		LEFT JOIN departments d ON d.id = cc.department_id
+		WHERE r.start_time >= $1 AND r.start_time < $2
+			AND (r.status IN ('confirmed', 'completed') OR f.amount IS NOT NULL)
+		GROUP BY month, cc.id, cc.code, cc.name, d.name
+		ORDER BY month ASC, d.name ASC NULLS LAST, cc.code ASC NULLS LAST`,
+		start, end,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying cost center spend: %v", err)
+	}
+	defer rows.Close()
+
+	spendRows := []models.CostCenterSpend{}
+	for rows.Next() {
+		var row models.CostCenterSpend
+		var reservationTotal float64
+		err := rows.Scan(
+			&row.Month, &row.CostCenterID, &row.CostCenterCode, &row.CostCenterName, &row.Department,
+			&row.Reservations, &row.RoomSpend, &row.SnackSpend, &row.Discounts, &row.Tax,
+			&reservationTotal, &row.CancellationFees,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning cost center spend: %v", err)
+		}
+		row.Total = roundMoney(reservationTotal + row.CancellationFees)
+		spendRows = append(spendRows, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cost center spend: %v", err)
+	}
+
+	return &models.CostCenterSpendResponse{
+		StartMonth: start.Format("2006-01"),
+		EndMonth:   end.AddDate(0, -1, 0).Format("2006-01"),
+		Rows:       spendRows,
+	}, nil
+}
+
+// SpendReportCSV renders a spend report as CSV with one line per cost center and month
+func SpendReportCSV(report *models.CostCenterSpendResponse) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	money := func(amount float64) string {
+		return strconv.FormatFloat(amount, 'f', 2, 64)
+	}
+
+	records := [][]string{{
+		"month", "department", "cost_center_code", "cost_center_name", "reservations",
+		"room_spend", "snack_spend", "discounts", "tax", "cancellation_fees", "total",
+	}}
+	for _, row := range report.Rows {
+		records = append(records, []string{
+			row.Month, row.Department, row.CostCenterCode, row.CostCenterName, strconv.Itoa(row.Reservations),
+			money(row.RoomSpend), money(row.SnackSpend), money(row.Discounts), money(row.Tax),
+			money(row.CancellationFees), money(row.Total),
+		})
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("error writing csv: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"e_meeting/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMonthRange(t *testing.T) {
+	start, end, err := parseMonthRange("2026-01", "2026-03")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), end)
+
+	start, end, err = parseMonthRange("2026-12", "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), end)
+	assert.Equal(t, 12, int(start.Month()))
+
+	_, _, err = parseMonthRange("2026-03", "2026-01")
+	assert.Error(t, err)
+	_, _, err = parseMonthRange("2026/03", "")
+	assert.Error(t, err)
+}
+
+func TestSpendReportCSV(t *testing.T) {
+	report := &models.CostCenterSpendResponse{Rows: []models.CostCenterSpend{{
+		Month: "2026-03", Department: "Finance, Ops", CostCenterCode: "FIN-01", CostCenterName: "Finance",
+		Reservations: 2, RoomSpend: 300000, SnackSpend: 50000, Tax: 38500, Total: 388500,
+	}}}
+
+	out, err := SpendReportCSV(report)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "month,department,cost_center_code"))
+	assert.Equal(t, `2026-03,"Finance, Ops",FIN-01,Finance,2,300000.00,50000.00,0.00,38500.00,0.00,388500.00`, lines[1])
+}
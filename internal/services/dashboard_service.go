@@ -9,12 +9,14 @@ import (
 )
 
 type DashboardService struct {
-	db *sql.DB
+	db      *sql.DB
+	budgets *BudgetService
 }
 
-func NewDashboardService(db *sql.DB) *DashboardService {
+func NewDashboardService(db *sql.DB, budgets *BudgetService) *DashboardService {
 	return &DashboardService{
-		db: db,
+		db:      db,
+		budgets: budgets,
 	}
 }
 
@@ -52,12 +54,16 @@ func (s *DashboardService) GetDashboardStats(query *models.DashboardQuery) (*mod
 	defer tx.Rollback()
 
 	// Get total statistics
-	var totalOmzet float64
+	var totalOmzet, roomRevenue, snackRevenue, discounts, totalTax float64
 	var totalReservations, totalVisitors, totalRooms int
 
 	err = tx.QueryRow(`
 		SELECT 
 			COALESCE(SUM(r.price), 0) as total_omzet,
+			COALESCE(SUM(r.room_subtotal), 0) as room_revenue,
+			COALESCE(SUM(r.snack_subtotal), 0) as snack_revenue,
+			COALESCE(SUM(r.discount_total), 0) as discounts,
+			COALESCE(SUM(r.tax_amount), 0) as total_tax,
 			COUNT(DISTINCT r.id) as total_reservations,
 			COALESCE(SUM(r.visitor_count), 0) as total_visitors,
 			(SELECT COUNT(*) FROM rooms) as total_rooms
@@ -65,13 +71,29 @@ func (s *DashboardService) GetDashboardStats(query *models.DashboardQuery) (*mod
 		LEFT JOIN reservations r ON r.room_id = rm.id
 		WHERE (r.start_time >= $1 AND r.end_time <= $2 AND r.status = 'confirmed') OR r.id IS NULL`,
 		startDate, endDate,
-	).Scan(&totalOmzet, &totalReservations, &totalVisitors, &totalRooms)
+	).Scan(&totalOmzet, &roomRevenue, &snackRevenue, &discounts, &totalTax, &totalReservations, &totalVisitors, &totalRooms)
 
 	if err != nil {
 		fmt.Println(err, " error getting total statistics")
 		return nil, fmt.Errorf("error getting total statistics: %v", err)
 	}
 
+	// Cancellation fees are revenue kept from cancelled reservations; refunds are paid back
+	var cancellationFees, refunds float64
+	err = tx.QueryRow(`
+		SELECT
+			COALESCE(SUM(l.amount) FILTER (WHERE l.entry_type = 'cancellation_fee'), 0),
+			COALESCE(SUM(l.amount) FILTER (WHERE l.entry_type = 'refund'), 0)
+		FROM reservation_ledger l
+		JOIN reservations r ON r.id = l.reservation_id
+		WHERE r.start_time >= $1 AND r.end_time <= $2`,
+		startDate, endDate,
+	).Scan(&cancellationFees, &refunds)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cancellation statistics: %v", err)
+	}
+	totalOmzet += cancellationFees
+
 	// Get per-room statistics
 	rows, err := tx.Query(`
 		WITH room_bookings AS (
@@ -80,16 +102,28 @@ func (s *DashboardService) GetDashboardStats(query *models.DashboardQuery) (*mod
 					rm.name as room_name,
 					COUNT(r.id) as total_bookings,
 					COALESCE(SUM(EXTRACT(EPOCH FROM (r.end_time - r.start_time)) / 3600), 0) as total_hours,
+					COALESCE(SUM(r.room_subtotal), 0) as room_revenue,
+					COALESCE(SUM(r.snack_subtotal), 0) as snack_revenue,
+					COALESCE(SUM(r.discount_total), 0) as discounts,
+					COALESCE(SUM(r.tax_amount), 0) as tax,
 					COALESCE(SUM(r.price), 0) as revenue
 				FROM rooms rm
 				LEFT JOIN reservations r 
 					ON r.room_id = rm.id AND r.status = 'confirmed'
 					AND r.start_time >= $1 AND r.end_time <= $2
 				GROUP BY rm.id, rm.name
+			),
+			room_fees AS (
+				SELECT r.room_id, SUM(l.amount) as cancellation_fees
+				FROM reservation_ledger l
+				JOIN reservations r ON r.id = l.reservation_id
+				WHERE l.entry_type = 'cancellation_fee'
+					AND r.start_time >= $1 AND r.end_time <= $2
+				GROUP BY r.room_id
 			)
 
 		SELECT 
-			room_id,
+			rb.room_id,
 			room_name,
 			total_bookings,
 			total_hours,
@@ -97,8 +131,14 @@ func (s *DashboardService) GetDashboardStats(query *models.DashboardQuery) (*mod
 				WHEN $3 = 0 THEN 0
 				ELSE (total_hours / ($3 * 24) * 100)
 			END as occupancy_rate,
-			revenue
-		FROM room_bookings
+			room_revenue,
+			snack_revenue,
+			discounts,
+			tax,
+			COALESCE(rf.cancellation_fees, 0) as cancellation_fees,
+			revenue + COALESCE(rf.cancellation_fees, 0) as revenue
+		FROM room_bookings rb
+		LEFT JOIN room_fees rf ON rf.room_id = rb.room_id
 		ORDER BY revenue DESC`,
 		startDate, endDate,
 		endDate.Sub(startDate).Hours()/24, // Total days in period
@@ -118,6 +158,11 @@ func (s *DashboardService) GetDashboardStats(query *models.DashboardQuery) (*mod
 			&stat.TotalBookings,
 			&stat.TotalHours,
 			&stat.Occupancy,
+			&stat.RoomRevenue,
+			&stat.SnackRevenue,
+			&stat.Discounts,
+			&stat.Tax,
+			&stat.CancellationFees,
 			&stat.Revenue,
 		)
 		if err != nil {
@@ -132,18 +177,31 @@ func (s *DashboardService) GetDashboardStats(query *models.DashboardQuery) (*mod
 		return nil, fmt.Errorf("error iterating room statistics: %v", err)
 	}
 
+	// Budget consumption and forecast of the months in the period
+	budgets, err := s.budgets.GetConsumption(tx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("error committing transaction: %v", err)
 	}
 
 	return &models.DashboardResponse{
-		StartDate:    startDate,
-		EndDate:      endDate,
-		TotalOmzet:   totalOmzet,
-		Reservations: totalReservations,
-		Visitors:     totalVisitors,
-		TotalRooms:   totalRooms,
-		RoomStats:    roomStats,
+		StartDate:        startDate,
+		EndDate:          endDate,
+		TotalOmzet:       totalOmzet,
+		RoomRevenue:      roomRevenue,
+		SnackRevenue:     snackRevenue,
+		Discounts:        discounts,
+		TotalTax:         totalTax,
+		CancellationFees: cancellationFees,
+		Refunds:          refunds,
+		Reservations:     totalReservations,
+		Visitors:         totalVisitors,
+		TotalRooms:       totalRooms,
+		RoomStats:        roomStats,
+		Budgets:          budgets,
 	}, nil
 }
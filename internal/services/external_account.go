@@ -0,0 +1,403 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/auth"
+	"e_meeting/internal/models"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// externalIdentity is a user as an identity provider or directory describes them
+type externalIdentity struct {
+	Issuer            string // Identity provider or directory the user belongs to
+	Subject           string // Stable ID of the user there
+	Email             string
+	EmailVerified     bool // The provider vouches for the address, so it may link and update accounts
+	PreferredUsername string
+	Groups            []string
+	HasGroups         bool // Groups were provided, an empty list then maps to the user role
+}
+
+// externalAccount is an account signing in through an external identity provider
+type externalAccount struct {
+	ID               uuid.UUID
+	Username         string
+	Email            string
+	Role             string
+	TokenVersion     int
+	Status           bool
+	Deleted          bool
+	TwoFactorEnabled bool
+}
+
+func (a *externalAccount) state() userState {
+	return userState{Role: a.Role, Status: a.Status, Deleted: a.Deleted}
+}
+
+func (a *externalAccount) user() *models.User {
+	return &models.User{
+		ID:               a.ID,
+		Username:         a.Username,
+		Email:            a.Email,
+		Role:             a.Role,
+		TokenVersion:     a.TokenVersion,
+		Status:           a.Status,
+		TwoFactorEnabled: a.TwoFactorEnabled,
+	}
+}
+
+// lockExternalAccount locks the account matching the where clause, sql.ErrNoRows if none does
+func lockExternalAccount(tx *sql.Tx, where string, args ...interface{}) (*externalAccount, error) {
+	var account externalAccount
+	err := tx.QueryRow(`
+		SELECT id, username, email, role, token_version, status, deleted_at IS NOT NULL, two_factor_enabled
+		FROM users
+		WHERE `+where+`
+		FOR UPDATE
+	`, args...).Scan(
+		&account.ID, &account.Username, &account.Email, &account.Role, &account.TokenVersion,
+		&account.Status, &account.Deleted, &account.TwoFactorEnabled,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// lockIdentityAccount locks the account an identity is linked to, sql.ErrNoRows if none is
+func lockIdentityAccount(tx *sql.Tx, issuer, subject string) (*externalAccount, error) {
+	return lockExternalAccount(tx, `id = (
+		SELECT user_id FROM user_identities WHERE issuer = $1 AND subject = $2
+	)`, issuer, subject)
+}
+
+// usernameBase derives a username for a new account from the preferred username
+// or the email address, keeping only the letters and digits usernames allow
+func usernameBase(preferred, email string) string {
+	source := preferred
+	if source == "" {
+		source, _, _ = strings.Cut(email, "@")
+	}
+
+	var b strings.Builder
+	for _, r := range source {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+		if b.Len() == 40 {
+			break
+		}
+	}
+	base := b.String()
+	if len(base) < 3 {
+		base += "user"
+	}
+	return base
+}
+
+// uniqueUsername returns base, or base with the lowest number appended that no account uses
+func uniqueUsername(tx *sql.Tx, base string) (string, error) {
+	for i := 1; i <= 1000; i++ {
+		candidate := base
+		if i > 1 {
+			candidate += strconv.Itoa(i)
+		}
+		var taken bool
+		err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM users WHERE LOWER(username) = LOWER($1))`, candidate).Scan(&taken)
+		if err != nil {
+			return "", fmt.Errorf("error checking username: %v", err)
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no free username for %s", base)
+}
+
+// syncRole gives an account the role its groups map to and reports whether it
+// changed. The last active admin keeps its role.
+func syncRole(tx *sql.Tx, account *externalAccount, role string, activeAdmins int) (bool, error) {
+	if account.Role == role {
+		return false, nil
+	}
+	after := account.state()
+	after.Role = role
+	if err := checkLastAdmin(account.state(), after, activeAdmins); err != nil {
+		log.Warn().Str("user_id", account.ID.String()).Msg("Kept the role of the last active admin despite its groups")
+		return false, nil
+	}
+
+	_, err := tx.Exec(`
+		UPDATE users SET role = $2, token_version = token_version + 1, updated_at = NOW()
+		WHERE id = $1
+	`, account.ID, role)
+	if err != nil {
+		return false, fmt.Errorf("error updating user role: %v", err)
+	}
+	account.Role = role
+	account.TokenVersion++
+	return true, nil
+}
+
+// syncEmail takes over a verified email address that changed at the provider,
+// unless another account already uses it
+func syncEmail(tx *sql.Tx, account *externalAccount, identity externalIdentity) error {
+	if !identity.EmailVerified || identity.Email == "" || strings.EqualFold(account.Email, identity.Email) {
+		return nil
+	}
+
+	result, err := tx.Exec(`
+		UPDATE users SET email = $2, updated_at = NOW()
+		WHERE id = $1 AND NOT EXISTS (
+			SELECT 1 FROM users WHERE LOWER(email) = LOWER($2) AND id <> $1
+		)
+	`, account.ID, identity.Email)
+	if err != nil {
+		return fmt.Errorf("error updating user email: %v", err)
+	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		log.Warn().Str("user_id", account.ID.String()).Msg("Kept the email of an account, the new address is used by another account")
+		return nil
+	}
+	account.Email = identity.Email
+	return nil
+}
+
+// externalAccounts keeps the accounts of users signing in through an external
+// identity provider or directory in step with it
+type externalAccounts struct {
+	db         *sql.DB
+	userStates *auth.UserStateCache
+	roles      RoleMapping
+}
+
+func newExternalAccounts(db *sql.DB, userStates *auth.UserStateCache, roles RoleMapping) *externalAccounts {
+	return &externalAccounts{
+		db:         db,
+		userStates: userStates,
+		roles:      roles,
+	}
+}
+
+// syncsRoles reports whether the groups of an identity decide the role of its account
+func (e *externalAccounts) syncsRoles(identity externalIdentity) bool {
+	return identity.HasGroups && e.roles.Enabled()
+}
+
+// update syncs the role and email of a locked account with its identity and
+// reports whether its tokens must be checked again. signedIn records a login.
+func (e *externalAccounts) update(tx *sql.Tx, account *externalAccount, identity externalIdentity, activeAdmins int, signedIn bool) (bool, error) {
+	changed := false
+	if e.syncsRoles(identity) {
+		var err error
+		if changed, err = syncRole(tx, account, e.roles.Role(identity.Groups), activeAdmins); err != nil {
+			return false, err
+		}
+	}
+	if err := syncEmail(tx, account, identity); err != nil {
+		return false, err
+	}
+
+	_, err := tx.Exec(`
+		UPDATE user_identities
+		SET email = NULLIF($3, ''), last_login_at = CASE WHEN $4 THEN NOW() ELSE last_login_at END
+		WHERE issuer = $1 AND subject = $2
+	`, identity.Issuer, identity.Subject, identity.Email, signedIn)
+	if err != nil {
+		return false, fmt.Errorf("error updating identity: %v", err)
+	}
+	return changed, nil
+}
+
+// signIn finds the account of an identity, linking an account with the same
+// verified email or creating one on its first login, and syncs it
+func (e *externalAccounts) signIn(identity externalIdentity) (*externalAccount, error) {
+	// Start transaction
+	tx, err := e.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	activeAdmins := 0
+	if e.syncsRoles(identity) {
+		if activeAdmins, err = lockActiveAdmins(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	account, err := lockIdentityAccount(tx, identity.Issuer, identity.Subject)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+
+	if err == sql.ErrNoRows {
+		if identity.Email == "" {
+			return nil, fmt.Errorf("identity provider did not return an email address")
+		}
+		account, err = lockExternalAccount(tx, `LOWER(email) = LOWER($1)`, identity.Email)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("error fetching user: %v", err)
+		}
+		if err == nil && !identity.EmailVerified {
+			// Anyone can claim an address at some providers, only a verified one proves ownership
+			return nil, fmt.Errorf("email address is not verified by the identity provider")
+		}
+		if err == sql.ErrNoRows {
+			if account, err = e.create(tx, identity); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO user_identities (user_id, issuer, subject, email)
+			VALUES ($1, $2, $3, $4)
+		`, account.ID, identity.Issuer, identity.Subject, identity.Email)
+		if err != nil {
+			return nil, fmt.Errorf("error linking identity: %v", err)
+		}
+	}
+
+	if !account.state().isActive() {
+		return nil, fmt.Errorf("account is deactivated")
+	}
+
+	changed, err := e.update(tx, account, identity, activeAdmins, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+	if changed && e.userStates != nil {
+		e.userStates.Invalidate(account.ID.String())
+	}
+
+	return account, nil
+}
+
+// create provisions the account of a user signing in for the first time.
+// It has no password, the user signs in through the identity provider only.
+func (e *externalAccounts) create(tx *sql.Tx, identity externalIdentity) (*externalAccount, error) {
+	username, err := uniqueUsername(tx, usernameBase(identity.PreferredUsername, identity.Email))
+	if err != nil {
+		return nil, err
+	}
+	role := "user"
+	if e.syncsRoles(identity) {
+		role = e.roles.Role(identity.Groups)
+	}
+
+	account := &externalAccount{Username: username, Email: identity.Email, Role: role, Status: true}
+	err = tx.QueryRow(`
+		INSERT INTO users (username, email, password, role, status)
+		VALUES ($1, $2, '', $3, TRUE)
+		RETURNING id
+	`, username, identity.Email, role).Scan(&account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error creating user: %v", err)
+	}
+	return account, nil
+}
+
+// sync updates the active account linked to an identity, if there is one
+func (e *externalAccounts) sync(identity externalIdentity) error {
+	// Start transaction
+	tx, err := e.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	activeAdmins := 0
+	if e.syncsRoles(identity) {
+		if activeAdmins, err = lockActiveAdmins(tx); err != nil {
+			return err
+		}
+	}
+
+	account, err := lockIdentityAccount(tx, identity.Issuer, identity.Subject)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching user: %v", err)
+	}
+	if !account.state().isActive() {
+		return nil
+	}
+
+	changed, err := e.update(tx, account, identity, activeAdmins, false)
+	if err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	if changed && e.userStates != nil {
+		e.userStates.Invalidate(account.ID.String())
+	}
+	return nil
+}
+
+// deactivate signs out and deactivates the account linked to an identity that
+// no longer exists at its provider. The last active admin stays active.
+func (e *externalAccounts) deactivate(issuer, subject string) (bool, error) {
+	// Start transaction
+	tx, err := e.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	activeAdmins, err := lockActiveAdmins(tx)
+	if err != nil {
+		return false, err
+	}
+	account, err := lockIdentityAccount(tx, issuer, subject)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error fetching user: %v", err)
+	}
+
+	after := account.state()
+	after.Status = false
+	if !account.state().isActive() {
+		return false, nil
+	}
+	if err := checkLastAdmin(account.state(), after, activeAdmins); err != nil {
+		log.Warn().Str("user_id", account.ID.String()).Msg("Kept the last active admin active despite its removal from the directory")
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE users SET status = FALSE, token_version = token_version + 1, updated_at = NOW()
+		WHERE id = $1
+	`, account.ID)
+	if err != nil {
+		return false, fmt.Errorf("error updating user: %v", err)
+	}
+	if _, err := revokeUserSessions(tx, account.ID); err != nil {
+		return false, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("error committing transaction: %v", err)
+	}
+	if e.userStates != nil {
+		e.userStates.Invalidate(account.ID.String())
+	}
+	return true, nil
+}
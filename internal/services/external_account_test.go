@@ -0,0 +1,15 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsernameBase(t *testing.T) {
+	assert.Equal(t, "alicesmith", usernameBase("alice.smith", "other@example.com"))
+	assert.Equal(t, "bob", usernameBase("", "bob@example.com"))
+	assert.Equal(t, "jouser", usernameBase("", "j.o@example.com"))
+	assert.Equal(t, "user", usernameBase("", ""))
+	assert.Len(t, usernameBase("a123456789b123456789c123456789d123456789e123456789", ""), 40)
+}
@@ -0,0 +1,410 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/models"
+	"e_meeting/internal/pdf"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InvoiceService issues invoices for confirmed reservations and renders them as PDF
+type InvoiceService struct {
+	db       *sql.DB
+	taxLabel string
+	location *time.Location
+}
+
+func NewInvoiceService(db *sql.DB, taxLabel string, location *time.Location) *InvoiceService {
+	return &InvoiceService{
+		db:       db,
+		taxLabel: taxLabel,
+		location: location,
+	}
+}
+
+// invoiceableStatus reports whether a reservation in the given status gets an invoice
+func invoiceableStatus(status string) bool {
+	return status == string(models.ReservationStatusConfirmed) || status == string(models.ReservationStatusCompleted)
+}
+
+// GenerateInvoice issues the invoice for a reservation inside the caller's transaction.
+// It does nothing when the reservation already has an invoice.
+func (s *InvoiceService) GenerateInvoice(tx *sql.Tx, reservationID uuid.UUID) error {
+	var (
+		userID                                uuid.UUID
+		roomName                              string
+		startTime, endTime                    time.Time
+		roomSubtotal, snackSubtotal, discount float64
+		taxRate, taxAmount, total             float64
+		existing                              int
+	)
+	// Lock the reservation so concurrent status updates cannot issue two invoices
+	err := tx.QueryRow(`
+		SELECT r.user_id, rm.name, r.start_time, r.end_time,
+			r.room_subtotal, r.snack_subtotal, r.discount_total, r.tax_rate, r.tax_amount, r.price,
+			(SELECT COUNT(*) FROM invoices i WHERE i.reservation_id = r.id)
+		FROM reservations r
+		JOIN rooms rm ON rm.id = r.room_id
+		WHERE r.id = $1
+		FOR UPDATE OF r
+	`, reservationID).Scan(
+		&userID, &roomName, &startTime, &endTime,
+		&roomSubtotal, &snackSubtotal, &discount, &taxRate, &taxAmount, &total,
+		&existing,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("reservation not found")
+		}
+		return fmt.Errorf("error fetching reservation for invoice: %v", err)
+	}
+	if existing > 0 {
+		return nil
+	}
+
+	lines := []models.InvoiceLine{{
+		Kind: models.InvoiceLineRoom,
+		Description: fmt.Sprintf("Room %s, %s - %s (%g hours)", roomName,
+			startTime.Format("2006-01-02 15:04"), endTime.Format("15:04"), roundMoney(endTime.Sub(startTime).Hours())),
+		Quantity:  1,
+		UnitPrice: roomSubtotal,
+		Amount:    roomSubtotal,
+	}}
+
+	snackLines, err := s.snackLines(tx, reservationID)
+	if err != nil {
+		return err
+	}
+	lines = append(lines, snackLines...)
+
+	discountLines, err := s.discountLines(tx, reservationID, discount)
+	if err != nil {
+		return err
+	}
+	lines = append(lines, discountLines...)
+
+	// Take the next number of the year; the upsert serializes concurrent invoices
+	year := time.Now().In(s.location).Year()
+	var sequence int
+	err = tx.QueryRow(`
+		INSERT INTO invoice_sequences (year, last_number) VALUES ($1, 1)
+		ON CONFLICT (year) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING last_number
+	`, year).Scan(&sequence)
+	if err != nil {
+		return fmt.Errorf("error allocating invoice number: %v", err)
+	}
+
+	var invoiceID uuid.UUID
+	err = tx.QueryRow(`
+		INSERT INTO invoices (
+			invoice_number, year, sequence, reservation_id, user_id,
+			subtotal, discount_total, tax_label, tax_rate, tax_amount, total
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`, invoiceNumber(year, sequence), year, sequence, reservationID, userID,
+		roundMoney(roomSubtotal+snackSubtotal), discount, s.taxLabel, taxRate, taxAmount, total,
+	).Scan(&invoiceID)
+	if err != nil {
+		return fmt.Errorf("error creating invoice: %v", err)
+	}
+
+	for i, line := range lines {
+		_, err = tx.Exec(`
+			INSERT INTO invoice_lines (invoice_id, position, kind, description, quantity, unit_price, amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, invoiceID, i+1, line.Kind, line.Description, line.Quantity, line.UnitPrice, line.Amount)
+		if err != nil {
+			return fmt.Errorf("error creating invoice line: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *InvoiceService) snackLines(tx *sql.Tx, reservationID uuid.UUID) ([]models.InvoiceLine, error) {
+	rows, err := tx.Query(`
+		SELECT s.name, rs.quantity, rs.price
+		FROM reservation_snacks rs
+		JOIN snacks s ON s.id = rs.snack_id
+		WHERE rs.reservation_id = $1
+		ORDER BY s.name ASC
+	`, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reservation snacks: %v", err)
+	}
+	defer rows.Close()
+
+	var lines []models.InvoiceLine
+	for rows.Next() {
+		var name string
+		var quantity int
+		var price float64
+		if err := rows.Scan(&name, &quantity, &price); err != nil {
+			return nil, fmt.Errorf("error scanning reservation snack: %v", err)
+		}
+		lines = append(lines, models.InvoiceLine{
+			Kind:        models.InvoiceLineSnack,
+			Description: name,
+			Quantity:    float64(quantity),
+			UnitPrice:   price,
+			Amount:      roundMoney(price * float64(quantity)),
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reservation snacks: %v", err)
+	}
+	return lines, nil
+}
+
+func (s *InvoiceService) discountLines(tx *sql.Tx, reservationID uuid.UUID, discountTotal float64) ([]models.InvoiceLine, error) {
+	rows, err := tx.Query(`
+		SELECT pc.code, pcr.discount_amount
+		FROM promo_code_redemptions pcr
+		JOIN promo_codes pc ON pc.id = pcr.promo_code_id
+		WHERE pcr.reservation_id = $1
+		ORDER BY pc.code ASC
+	`, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reservation discounts: %v", err)
+	}
+	defer rows.Close()
+
+	var lines []models.InvoiceLine
+	var listed float64
+	for rows.Next() {
+		var code string
+		var amount float64
+		if err := rows.Scan(&code, &amount); err != nil {
+			return nil, fmt.Errorf("error scanning reservation discount: %v", err)
+		}
+		listed += amount
+		lines = append(lines, models.InvoiceLine{
+			Kind:        models.InvoiceLineDiscount,
+			Description: "Promo code " + code,
+			Quantity:    1,
+			UnitPrice:   -amount,
+			Amount:      -amount,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reservation discounts: %v", err)
+	}
+
+	// Keep the lines consistent with the stored total if a discount has no redemption record
+	if rest := roundMoney(discountTotal - listed); rest > 0 {
+		lines = append(lines, models.InvoiceLine{
+			Kind:        models.InvoiceLineDiscount,
+			Description: "Discount",
+			Quantity:    1,
+			UnitPrice:   -rest,
+			Amount:      -rest,
+		})
+	}
+	return lines, nil
+}
+
+func invoiceNumber(year, sequence int) string {
+	return fmt.Sprintf("INV-%d-%06d", year, sequence)
+}
+
+// GetInvoiceByReservation returns the invoice of a reservation. Non-admin users can
+// only read invoices of their own reservations. Confirmed or completed reservations
+// without an invoice, such as those confirmed before invoicing existed, get one issued.
+func (s *InvoiceService) GetInvoiceByReservation(reservationID, userID uuid.UUID, isAdmin bool) (*models.Invoice, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var ownerID uuid.UUID
+	var status string
+	err = tx.QueryRow(`SELECT user_id, status FROM reservations WHERE id = $1`, reservationID).Scan(&ownerID, &status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reservation not found")
+		}
+		return nil, fmt.Errorf("error fetching reservation: %v", err)
+	}
+	if !isAdmin && ownerID != userID {
+		return nil, fmt.Errorf("reservation not found")
+	}
+
+	if invoiceableStatus(status) {
+		if err := s.GenerateInvoice(tx, reservationID); err != nil {
+			return nil, err
+		}
+	}
+
+	var invoice models.Invoice
+	err = tx.QueryRow(`
+		SELECT i.id, i.invoice_number, i.reservation_id, i.issued_at,
+			u.id, u.username, u.email,
+			rm.name, r.start_time, r.end_time, r.visitor_count, r.status,
+			i.subtotal, i.discount_total, i.tax_label, i.tax_rate, i.tax_amount, i.total
+		FROM invoices i
+		JOIN reservations r ON r.id = i.reservation_id
+		JOIN rooms rm ON rm.id = r.room_id
+		JOIN users u ON u.id = i.user_id
+		WHERE i.reservation_id = $1
+	`, reservationID).Scan(
+		&invoice.ID, &invoice.InvoiceNumber, &invoice.ReservationID, &invoice.IssuedAt,
+		&invoice.BilledTo.UserID, &invoice.BilledTo.Username, &invoice.BilledTo.Email,
+		&invoice.Reservation.RoomName, &invoice.Reservation.StartTime, &invoice.Reservation.EndTime,
+		&invoice.Reservation.VisitorCount, &invoice.Reservation.Status,
+		&invoice.Subtotal, &invoice.DiscountTotal, &invoice.TaxLabel, &invoice.TaxRate, &invoice.TaxAmount, &invoice.Total,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invoice is not available until the reservation is confirmed")
+		}
+		return nil, fmt.Errorf("error fetching invoice: %v", err)
+	}
+
+	rows, err := tx.Query(`
+		SELECT kind, description, quantity, unit_price, amount
+		FROM invoice_lines
+		WHERE invoice_id = $1
+		ORDER BY position ASC
+	`, invoice.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching invoice lines: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line models.InvoiceLine
+		if err := rows.Scan(&line.Kind, &line.Description, &line.Quantity, &line.UnitPrice, &line.Amount); err != nil {
+			return nil, fmt.Errorf("error scanning invoice line: %v", err)
+		}
+		invoice.Lines = append(invoice.Lines, line)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating invoice lines: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return &invoice, nil
+}
+
+// RenderInvoicePDF lays out an invoice on A4 pages
+func (s *InvoiceService) RenderInvoicePDF(invoice *models.Invoice) []byte {
+	const (
+		left      = 50.0
+		right     = pdf.PageWidth - 50
+		qtyRight  = 360.0
+		unitRight = 455.0
+		bottom    = 80.0
+	)
+
+	doc := pdf.NewDocument()
+	y := pdf.PageHeight - 60
+
+	doc.Text(left, y, 20, true, "INVOICE")
+	doc.TextRight(right, y, 12, true, invoice.InvoiceNumber)
+	y -= 18
+	doc.TextRight(right, y, 10, false, "Issued "+invoice.IssuedAt.In(s.location).Format("2 January 2006"))
+
+	y -= 30
+	doc.Text(left, y, 10, true, "Billed to")
+	doc.Text(300, y, 10, true, "Reservation")
+	y -= 14
+	doc.Text(left, y, 10, false, invoice.BilledTo.Username)
+	doc.Text(300, y, 10, false, invoice.Reservation.RoomName)
+	y -= 14
+	doc.Text(left, y, 10, false, invoice.BilledTo.Email)
+	// Reservation times are stored as wall clock times
+	doc.Text(300, y, 10, false, invoice.Reservation.StartTime.Format("2 Jan 2006 15:04")+" - "+invoice.Reservation.EndTime.Format("15:04"))
+	y -= 14
+	doc.Text(300, y, 10, false, fmt.Sprintf("%d visitors", invoice.Reservation.VisitorCount))
+
+	header := func() {
+		doc.Text(left, y, 10, true, "Description")
+		doc.TextRight(qtyRight, y, 10, true, "Qty")
+		doc.TextRight(unitRight, y, 10, true, "Unit price")
+		doc.TextRight(right, y, 10, true, "Amount")
+		y -= 6
+		doc.Line(left, y, right, y, 0.75)
+		y -= 16
+	}
+
+	y -= 36
+	header()
+	for _, line := range invoice.Lines {
+		if y < bottom {
+			doc.AddPage()
+			y = pdf.PageHeight - 60
+			header()
+		}
+		doc.Text(left, y, 10, false, truncate(line.Description, 52))
+		doc.TextRight(qtyRight, y, 10, false, fmt.Sprintf("%g", line.Quantity))
+		doc.TextRight(unitRight, y, 10, false, formatMoney(line.UnitPrice))
+		doc.TextRight(right, y, 10, false, formatMoney(line.Amount))
+		y -= 16
+	}
+
+	if y < bottom+70 {
+		doc.AddPage()
+		y = pdf.PageHeight - 60
+	}
+	doc.Line(left, y+8, right, y+8, 0.75)
+	y -= 8
+	totals := []struct {
+		label  string
+		amount float64
+	}{
+		{"Subtotal", invoice.Subtotal},
+		{"Discount", -invoice.DiscountTotal},
+		{fmt.Sprintf("%s (%g%%)", invoice.TaxLabel, invoice.TaxRate), invoice.TaxAmount},
+	}
+	for _, total := range totals {
+		doc.TextRight(unitRight, y, 10, false, total.label)
+		doc.TextRight(right, y, 10, false, formatMoney(total.amount))
+		y -= 16
+	}
+	doc.TextRight(unitRight, y, 11, true, "Total")
+	doc.TextRight(right, y, 11, true, formatMoney(invoice.Total))
+
+	return doc.Bytes()
+}
+
+// formatMoney formats an amount with thousands separators and two decimals
+func formatMoney(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	s := fmt.Sprintf("%.2f", amount)
+	whole, fraction := s[:len(s)-3], s[len(s)-3:]
+
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return sign + b.String() + fraction
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
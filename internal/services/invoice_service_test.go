@@ -0,0 +1,36 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"e_meeting/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMoney(t *testing.T) {
+	assert.Equal(t, "0.00", formatMoney(0))
+	assert.Equal(t, "999.50", formatMoney(999.5))
+	assert.Equal(t, "1,234,567.89", formatMoney(1234567.891))
+	assert.Equal(t, "-25,000.00", formatMoney(-25000))
+}
+
+func TestInvoiceNumber(t *testing.T) {
+	assert.Equal(t, "INV-2026-000042", invoiceNumber(2026, 42))
+}
+
+func TestRenderInvoicePDF(t *testing.T) {
+	service := NewInvoiceService(nil, "PPN", time.UTC)
+	invoice := &models.Invoice{InvoiceNumber: "INV-2026-000001", TaxLabel: "PPN", TaxRate: 11, Total: 111000}
+	invoice.Lines = []models.InvoiceLine{{Kind: models.InvoiceLineRoom, Description: "Room Alpha", Quantity: 1, UnitPrice: 100000, Amount: 100000}}
+	for i := 0; i < 60; i++ {
+		invoice.Lines = append(invoice.Lines, models.InvoiceLine{Kind: models.InvoiceLineSnack, Description: "Coffee", Quantity: 1, UnitPrice: 10, Amount: 10})
+	}
+
+	out := service.RenderInvoicePDF(invoice)
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-")))
+	assert.Contains(t, string(out), "(INV-2026-000001) Tj")
+	assert.Contains(t, string(out), "/Count 2") // long invoices continue on a second page
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"e_meeting/internal/auth"
+	"e_meeting/internal/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LDAPAuthenticator signs users in with their directory password. Accounts are
+// linked to directory entries by username; a first login links an account with
+// the same email, or creates one. Sync keeps the role and email of linked
+// accounts in step with the directory.
+type LDAPAuthenticator struct {
+	db        *sql.DB
+	directory *auth.LDAPDirectory
+	accounts  *externalAccounts
+}
+
+func NewLDAPAuthenticator(db *sql.DB, directory *auth.LDAPDirectory, userStates *auth.UserStateCache, roles RoleMapping) *LDAPAuthenticator {
+	return &LDAPAuthenticator{
+		db:        db,
+		directory: directory,
+		accounts:  newExternalAccounts(db, userStates, roles),
+	}
+}
+
+// ldapIdentity describes a directory entry as an identity. Directory emails
+// are managed by administrators, so they count as verified.
+func ldapIdentity(source string, entry *auth.LDAPEntry) externalIdentity {
+	return externalIdentity{
+		Issuer:            source,
+		Subject:           strings.ToLower(entry.Username),
+		Email:             entry.Email,
+		EmailVerified:     true,
+		PreferredUsername: entry.Username,
+		Groups:            entry.GroupNames(),
+		HasGroups:         true,
+	}
+}
+
+func (a *LDAPAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	entry, err := a.directory.Authenticate(ctx, username, password)
+	switch {
+	case errors.Is(err, auth.ErrLDAPUserNotFound):
+		return nil, ErrUserNotFound
+	case errors.Is(err, auth.ErrLDAPInvalidCredentials):
+		return nil, ErrPasswordMismatch
+	case err != nil:
+		log.Error().Err(err).Msg("Failed to authenticate with LDAP")
+		return nil, err
+	}
+
+	account, err := a.accounts.signIn(ldapIdentity(a.directory.Source(), entry))
+	if err != nil {
+		return nil, err
+	}
+	return account.user(), nil
+}
+
+// linkedSubjects lists the directory users linked to an active account
+func (a *LDAPAuthenticator) linkedSubjects(ctx context.Context) ([]string, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT i.subject
+		FROM user_identities i
+		JOIN users u ON u.id = i.user_id
+		WHERE i.issuer = $1 AND u.status = TRUE AND u.deleted_at IS NULL
+		ORDER BY i.subject
+	`, a.directory.Source())
+	if err != nil {
+		return nil, fmt.Errorf("error querying identities: %v", err)
+	}
+	defer rows.Close()
+
+	var subjects []string
+	for rows.Next() {
+		var subject string
+		if err := rows.Scan(&subject); err != nil {
+			return nil, fmt.Errorf("error scanning identity: %v", err)
+		}
+		subjects = append(subjects, subject)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating identities: %v", err)
+	}
+	return subjects, nil
+}
+
+// Sync updates the role and email of every active account linked to the
+// directory and deactivates those whose entry is gone. Users the directory
+// cannot be asked about are skipped until the next sync.
+func (a *LDAPAuthenticator) Sync(ctx context.Context) (synced, deactivated int, err error) {
+	subjects, err := a.linkedSubjects(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	source := a.directory.Source()
+	for _, subject := range subjects {
+		if err := ctx.Err(); err != nil {
+			return synced, deactivated, err
+		}
+
+		entry, err := a.directory.Lookup(ctx, subject)
+		if errors.Is(err, auth.ErrLDAPUserNotFound) {
+			removed, err := a.accounts.deactivate(source, subject)
+			if err != nil {
+				return synced, deactivated, err
+			}
+			if removed {
+				log.Info().Str("username", subject).Msg("Deactivated account removed from the directory")
+				deactivated++
+			}
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Str("username", subject).Msg("Failed to look up directory user")
+			continue
+		}
+
+		identity := ldapIdentity(source, entry)
+		identity.Subject = subject
+		if err := a.accounts.sync(identity); err != nil {
+			return synced, deactivated, err
+		}
+		synced++
+	}
+	return synced, deactivated, nil
+}
+
+// Run syncs the directory every interval until ctx is done
+func (a *LDAPAuthenticator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			synced, deactivated, err := a.Sync(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Error().Err(err).Msg("Failed to sync LDAP directory")
+				continue
+			}
+			log.Info().Int("synced", synced).Int("deactivated", deactivated).Msg("Synced LDAP directory")
+		}
+	}
+}
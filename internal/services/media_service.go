@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"e_meeting/internal/images"
+	"e_meeting/internal/models"
+	"e_meeting/internal/storage"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Bounding boxes of stored images and their thumbnails, in pixels
+const (
+	roomPhotoWidth       = 1600
+	roomPhotoHeight      = 1200
+	roomThumbnailWidth   = 400
+	roomThumbnailHeight  = 300
+	snackImageSize       = 1024
+	snackThumbnailSize   = 256
+	avatarSize           = 256
+	maxPhotosPerRoom     = 20
+	defaultMaxImageBytes = 2 << 20
+)
+
+// storedImage locates an uploaded image and its thumbnail in storage
+type storedImage struct {
+	ImageKey     string
+	ImageURL     string
+	ThumbnailKey string
+	ThumbnailURL string
+}
+
+// MediaService stores room photos and snack images
+type MediaService struct {
+	db            *sql.DB
+	store         storage.Storage
+	maxImageBytes int
+}
+
+func NewMediaService(db *sql.DB, store storage.Storage, maxImageBytes int) *MediaService {
+	if maxImageBytes <= 0 {
+		maxImageBytes = defaultMaxImageBytes
+	}
+	return &MediaService{
+		db:            db,
+		store:         store,
+		maxImageBytes: maxImageBytes,
+	}
+}
+
+// MaxImageBytes is the largest accepted upload
+func (s *MediaService) MaxImageBytes() int {
+	return s.maxImageBytes
+}
+
+// storeImage validates an upload and stores a resized copy and a thumbnail under prefix.
+// Every upload gets new keys so cached copies of a replaced image are never served.
+func (s *MediaService) storeImage(ctx context.Context, prefix string, data []byte, width, height, thumbWidth, thumbHeight int) (*storedImage, error) {
+	img, err := images.Decode(data, s.maxImageBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	full, err := images.Encode(images.Fit(img, width, height))
+	if err != nil {
+		return nil, err
+	}
+	thumbnail, err := images.Encode(images.Fit(img, thumbWidth, thumbHeight))
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	stored := &storedImage{
+		ImageKey:     fmt.Sprintf("%s/%s.jpg", prefix, id),
+		ThumbnailKey: fmt.Sprintf("%s/%s_thumb.jpg", prefix, id),
+	}
+	if err := s.store.Put(ctx, stored.ImageKey, full, images.ContentType); err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, stored.ThumbnailKey, thumbnail, images.ContentType); err != nil {
+		s.removeObjects(ctx, stored.ImageKey)
+		return nil, err
+	}
+	stored.ImageURL = s.store.URL(stored.ImageKey)
+	stored.ThumbnailURL = s.store.URL(stored.ThumbnailKey)
+	return stored, nil
+}
+
+// removeObjects deletes objects that are no longer referenced. Failures are
+// logged rather than returned since the database no longer points at them.
+func (s *MediaService) removeObjects(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Error().Err(err).Str("key", key).Msg("Failed to delete stored object")
+		}
+	}
+}
+
+// StoreAvatar validates an uploaded profile picture, crops it to a square and stores it,
+// returning its key and URL
+func (s *MediaService) StoreAvatar(ctx context.Context, userID uuid.UUID, data []byte) (string, string, error) {
+	img, err := images.Decode(data, s.maxImageBytes)
+	if err != nil {
+		return "", "", err
+	}
+	avatar, err := images.Encode(images.Fill(img, avatarSize, avatarSize))
+	if err != nil {
+		return "", "", err
+	}
+
+	key := fmt.Sprintf("avatars/%s/%s.jpg", userID, uuid.New())
+	if err := s.store.Put(ctx, key, avatar, images.ContentType); err != nil {
+		return "", "", err
+	}
+	return key, s.store.URL(key), nil
+}
+
+// RemoveObject deletes a stored object that is no longer referenced
+func (s *MediaService) RemoveObject(ctx context.Context, key string) {
+	s.removeObjects(ctx, key)
+}
+
+// AddRoomPhoto stores an uploaded photo of a room
+func (s *MediaService) AddRoomPhoto(ctx context.Context, roomID uuid.UUID, data []byte) (*models.RoomPhoto, error) {
+	var photoCount int
+	err := s.db.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM room_photos WHERE room_id = rooms.id)
+		FROM rooms
+		WHERE id = $1
+	`, roomID).Scan(&photoCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("room not found")
+		}
+		return nil, fmt.Errorf("error fetching room: %v", err)
+	}
+	if photoCount >= maxPhotosPerRoom {
+		return nil, fmt.Errorf("a room can have at most %d photos", maxPhotosPerRoom)
+	}
+
+	stored, err := s.storeImage(ctx, "rooms/"+roomID.String(), data, roomPhotoWidth, roomPhotoHeight, roomThumbnailWidth, roomThumbnailHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	photo := models.RoomPhoto{
+		URL:          stored.ImageURL,
+		ThumbnailURL: stored.ThumbnailURL,
+	}
+	err = s.db.QueryRow(`
+		INSERT INTO room_photos (room_id, image_key, image_url, thumbnail_key, thumbnail_url)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, roomID, stored.ImageKey, stored.ImageURL, stored.ThumbnailKey, stored.ThumbnailURL).Scan(&photo.ID, &photo.CreatedAt)
+	if err != nil {
+		s.removeObjects(ctx, stored.ImageKey, stored.ThumbnailKey)
+		return nil, fmt.Errorf("error saving room photo: %v", err)
+	}
+
+	return &photo, nil
+}
+
+// DeleteRoomPhoto removes a photo of a room and its stored objects
+func (s *MediaService) DeleteRoomPhoto(ctx context.Context, roomID, photoID uuid.UUID) error {
+	var imageKey, thumbnailKey string
+	err := s.db.QueryRow(`
+		DELETE FROM room_photos
+		WHERE id = $1 AND room_id = $2
+		RETURNING image_key, thumbnail_key
+	`, photoID, roomID).Scan(&imageKey, &thumbnailKey)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("room photo not found")
+		}
+		return fmt.Errorf("error deleting room photo: %v", err)
+	}
+
+	s.removeObjects(ctx, imageKey, thumbnailKey)
+	return nil
+}
+
+// SetSnackImage stores an uploaded snack image, replacing the previous one
+func (s *MediaService) SetSnackImage(ctx context.Context, snackID uuid.UUID, data []byte) (*models.Snack, error) {
+	var exists bool
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM snacks WHERE id = $1)`, snackID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching snack: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("snack not found")
+	}
+
+	stored, err := s.storeImage(ctx, "snacks/"+snackID.String(), data, snackImageSize, snackImageSize, snackThumbnailSize, snackThumbnailSize)
+	if err != nil {
+		return nil, err
+	}
+
+	snack, oldKeys, err := s.replaceSnackImage(snackID, stored)
+	if err != nil {
+		s.removeObjects(ctx, stored.ImageKey, stored.ThumbnailKey)
+		return nil, err
+	}
+
+	s.removeObjects(ctx, oldKeys...)
+	return snack, nil
+}
+
+// DeleteSnackImage removes the image of a snack and its stored objects
+func (s *MediaService) DeleteSnackImage(ctx context.Context, snackID uuid.UUID) (*models.Snack, error) {
+	snack, oldKeys, err := s.replaceSnackImage(snackID, &storedImage{})
+	if err != nil {
+		return nil, err
+	}
+
+	s.removeObjects(ctx, oldKeys...)
+	return snack, nil
+}
+
+// replaceSnackImage points a snack at a new image, or at none when stored has no keys,
+// and returns the keys of the previous image
+func (s *MediaService) replaceSnackImage(snackID uuid.UUID, stored *storedImage) (*models.Snack, []string, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var oldImageKey, oldThumbnailKey sql.NullString
+	err = tx.QueryRow(`
+		SELECT image_key, thumbnail_key FROM snacks WHERE id = $1 FOR UPDATE
+	`, snackID).Scan(&oldImageKey, &oldThumbnailKey)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("snack not found")
+		}
+		return nil, nil, fmt.Errorf("error fetching snack: %v", err)
+	}
+
+	snack, err := scanSnack(tx.QueryRow(`
+		UPDATE snacks
+		SET image_key = NULLIF($1, ''), image_url = NULLIF($2, ''),
+			thumbnail_key = NULLIF($3, ''), thumbnail_url = NULLIF($4, ''),
+			updated_at = NOW()
+		WHERE id = $5
+		RETURNING `+snackColumns,
+		stored.ImageKey, stored.ImageURL, stored.ThumbnailKey, stored.ThumbnailURL, snackID,
+	))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error updating snack image: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return snack, []string{oldImageKey.String, oldThumbnailKey.String}, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"e_meeting/internal/auth"
+	"e_meeting/internal/models"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// OIDCService signs users in through an OpenID Connect identity provider.
+// Users are matched by the subject the provider knows them by; a first login
+// links an existing account with the same verified email, or creates one.
+type OIDCService struct {
+	db        *sql.DB
+	provider  *auth.OIDCProvider
+	tokens    TokenIssuer
+	twoFactor TwoFactorChallenger
+	accounts  *externalAccounts
+	roleClaim string // Claim listing the groups of the user, empty leaves roles to admins
+	loginTTL  time.Duration
+}
+
+func NewOIDCService(db *sql.DB, provider *auth.OIDCProvider, tokens TokenIssuer, twoFactor TwoFactorChallenger, userStates *auth.UserStateCache, roleClaim string, roles RoleMapping, loginTTL time.Duration) *OIDCService {
+	return &OIDCService{
+		db:        db,
+		provider:  provider,
+		tokens:    tokens,
+		twoFactor: twoFactor,
+		accounts:  newExternalAccounts(db, userStates, roles),
+		roleClaim: roleClaim,
+		loginTTL:  loginTTL,
+	}
+}
+
+func hashOIDCState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+// claimValues reads a claim holding one value or a list of values
+func claimValues(claims map[string]interface{}, name string) ([]string, bool) {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}, true
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// Begin starts a login at the identity provider and returns the URL to send the user to
+func (s *OIDCService) Begin(ctx context.Context) (*models.OIDCLoginResponse, error) {
+	state, err := auth.NewOIDCState()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := auth.NewOIDCState()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := auth.NewPKCEVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, auth.PKCEChallenge(verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO oidc_login_requests (state_hash, nonce, code_verifier, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, hashOIDCState(state), nonce, verifier, time.Now().Add(s.loginTTL))
+	if err != nil {
+		return nil, fmt.Errorf("error storing login request: %v", err)
+	}
+
+	// Logins that were never completed
+	_, err = s.db.ExecContext(ctx, `DELETE FROM oidc_login_requests WHERE expires_at < NOW()`)
+	if err != nil {
+		return nil, fmt.Errorf("error removing expired login requests: %v", err)
+	}
+
+	return &models.OIDCLoginResponse{AuthorizationURL: authURL}, nil
+}
+
+// Callback completes a login the identity provider redirected back with its
+// authorization code. Like Login it returns a 2FA challenge instead of tokens
+// when the account needs one.
+func (s *OIDCService) Callback(ctx context.Context, state, code string, client models.ClientInfo) (*models.LoginResponse, error) {
+	var nonce, verifier string
+	var expiresAt time.Time
+	err := s.db.QueryRowContext(ctx, `
+		DELETE FROM oidc_login_requests
+		WHERE state_hash = $1
+		RETURNING nonce, code_verifier, expires_at
+	`, hashOIDCState(state)).Scan(&nonce, &verifier, &expiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid or expired login state")
+		}
+		return nil, fmt.Errorf("error fetching login request: %v", err)
+	}
+	if time.Now().After(expiresAt) {
+		return nil, fmt.Errorf("invalid or expired login state")
+	}
+
+	rawIDToken, err := s.provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := s.provider.VerifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidIDToken) {
+			log.Warn().Err(err).Msg("Rejected ID token")
+			return nil, auth.ErrInvalidIDToken
+		}
+		return nil, err
+	}
+
+	identity := externalIdentity{
+		Issuer:            idToken.Issuer,
+		Subject:           idToken.Subject,
+		Email:             idToken.Email,
+		EmailVerified:     idToken.EmailVerified,
+		PreferredUsername: idToken.PreferredUsername,
+	}
+	if s.roleClaim != "" {
+		identity.Groups, identity.HasGroups = claimValues(idToken.Claims, s.roleClaim)
+	}
+	account, err := s.accounts.signIn(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.twoFactor.Challenge(account.user())
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return challenge, nil
+	}
+
+	tokens, err := s.tokens.IssueTokens(account.user(), client)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		UserID:       account.ID.String(),
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}, nil
+}
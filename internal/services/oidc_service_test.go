@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleMapping(t *testing.T) {
+	mapping := RoleMapping{Admin: []string{"Room Admins"}, Catering: []string{"kitchen"}}
+
+	assert.Equal(t, "admin", mapping.Role([]string{"staff", "room admins"}))
+	assert.Equal(t, "catering", mapping.Role([]string{"Kitchen"}))
+	// The most privileged role wins
+	assert.Equal(t, "admin", mapping.Role([]string{"kitchen", "Room Admins"}))
+	assert.Equal(t, "user", mapping.Role([]string{"staff"}))
+	assert.Equal(t, "user", mapping.Role(nil))
+
+	assert.True(t, mapping.Enabled())
+	assert.False(t, RoleMapping{}.Enabled())
+}
+
+func TestClaimValues(t *testing.T) {
+	claims := map[string]interface{}{
+		"groups": []interface{}{"admins", 42, "staff"},
+		"role":   "admins",
+	}
+
+	values, ok := claimValues(claims, "groups")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"admins", "staff"}, values)
+
+	values, ok = claimValues(claims, "role")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"admins"}, values)
+
+	// A missing claim leaves the role alone, an empty list does not
+	_, ok = claimValues(claims, "missing")
+	assert.False(t, ok)
+	values, ok = claimValues(map[string]interface{}{"groups": []interface{}{}}, "groups")
+	assert.True(t, ok)
+	assert.Empty(t, values)
+}
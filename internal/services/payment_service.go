@@ -0,0 +1,267 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/models"
+	"e_meeting/internal/payments"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const paymentColumns = `
+	id, reservation_id, user_id, provider, provider_reference, amount, currency, status,
+	checkout_url, failure_reason, created_at, updated_at`
+
+// PaymentService collects reservation payments through a payment gateway and
+// confirms or releases reservations when the gateway reports the outcome. Without
+// a gateway, payments cannot be made and only past payments can be listed.
+type PaymentService struct {
+	db           *sql.DB
+	gateway      payments.Gateway
+	currency     string
+	reservations *ReservationService
+}
+
+func NewPaymentService(db *sql.DB, gateway payments.Gateway, currency string, reservations *ReservationService) *PaymentService {
+	return &PaymentService{
+		db:           db,
+		gateway:      gateway,
+		currency:     currency,
+		reservations: reservations,
+	}
+}
+
+func scanPayment(row interface{ Scan(...interface{}) error }) (*models.Payment, error) {
+	var payment models.Payment
+	err := row.Scan(
+		&payment.ID, &payment.ReservationID, &payment.UserID, &payment.Provider, &payment.ProviderReference,
+		&payment.Amount, &payment.Currency, &payment.Status,
+		&payment.CheckoutURL, &payment.FailureReason, &payment.CreatedAt, &payment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// CreatePayment starts the payment of a pending reservation owned by the user.
+// An open payment for the reservation is returned instead of creating a second one.
+func (s *PaymentService) CreatePayment(reservationID, userID uuid.UUID) (*models.Payment, error) {
+	if s.gateway == nil {
+		return nil, fmt.Errorf("payments are not available")
+	}
+
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var ownerID uuid.UUID
+	var status string
+	var amount float64
+	var roomName string
+	var approvalStatus *models.ApprovalStatus
+	err = tx.QueryRow(`
+		SELECT r.user_id, r.status, r.price, rm.name, r.approval_status
+		FROM reservations r
+		JOIN rooms rm ON rm.id = r.room_id
+		WHERE r.id = $1
+		FOR UPDATE OF r
+	`, reservationID).Scan(&ownerID, &status, &amount, &roomName, &approvalStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reservation not found")
+		}
+		return nil, fmt.Errorf("error fetching reservation: %v", err)
+	}
+	if ownerID != userID {
+		return nil, fmt.Errorf("reservation not found")
+	}
+	if status != string(models.ReservationStatusPending) {
+		return nil, fmt.Errorf("reservation is not awaiting payment")
+	}
+	if approvalStatus != nil && *approvalStatus == models.ApprovalRequired {
+		return nil, fmt.Errorf("reservation is awaiting budget approval")
+	}
+
+	open, err := scanPayment(tx.QueryRow(`SELECT `+paymentColumns+` FROM payments WHERE reservation_id = $1 AND status = 'pending'`, reservationID))
+	if err == nil {
+		return open, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error fetching open payment: %v", err)
+	}
+
+	paymentID := uuid.New()
+	intent, err := s.gateway.CreateIntent(payments.IntentRequest{
+		PaymentID:     paymentID,
+		ReservationID: reservationID,
+		Amount:        amount,
+		Currency:      s.currency,
+		Description:   "Reservation of " + roomName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating payment with %s: %v", s.gateway.Name(), err)
+	}
+
+	payment, err := scanPayment(tx.QueryRow(`
+		INSERT INTO payments (id, reservation_id, user_id, provider, provider_reference, amount, currency, status, checkout_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+paymentColumns,
+		paymentID, reservationID, userID, s.gateway.Name(), intent.Reference, amount, s.currency, intent.Status, intent.CheckoutURL,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error creating payment: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return payment, nil
+}
+
+// GetPayments lists the payments of a reservation, newest first
+func (s *PaymentService) GetPayments(reservationID, userID uuid.UUID, isAdmin bool) (*models.PaymentListResponse, error) {
+	var ownerID uuid.UUID
+	err := s.db.QueryRow(`SELECT user_id FROM reservations WHERE id = $1`, reservationID).Scan(&ownerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reservation not found")
+		}
+		return nil, fmt.Errorf("error fetching reservation: %v", err)
+	}
+	if !isAdmin && ownerID != userID {
+		return nil, fmt.Errorf("reservation not found")
+	}
+
+	rows, err := s.db.Query(`SELECT `+paymentColumns+` FROM payments WHERE reservation_id = $1 ORDER BY created_at DESC`, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying payments: %v", err)
+	}
+	defer rows.Close()
+
+	paymentList := []models.Payment{}
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning payment: %v", err)
+		}
+		paymentList = append(paymentList, *payment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payments: %v", err)
+	}
+
+	return &models.PaymentListResponse{
+		Payments: paymentList,
+	}, nil
+}
+
+// HandleWebhook applies a gateway callback. A successful payment confirms its
+// reservation and a failed one cancels it so the room is released. A payment
+// succeeding after its reservation was cancelled is recorded as owed back in full.
+// Redelivered events and callbacks for payments that are already settled are ignored.
+// Returns payments.ErrInvalidSignature when the callback does not verify.
+func (s *PaymentService) HandleWebhook(provider string, payload []byte, header http.Header) error {
+	if s.gateway == nil {
+		return fmt.Errorf("payments are not available")
+	}
+	if provider != s.gateway.Name() {
+		return fmt.Errorf("unknown payment provider")
+	}
+
+	event, err := s.gateway.ParseWebhook(payload, header)
+	if err != nil {
+		return err
+	}
+
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	payment, err := scanPayment(tx.QueryRow(`
+		SELECT `+paymentColumns+`
+		FROM payments
+		WHERE provider = $1 AND provider_reference = $2
+		FOR UPDATE
+	`, provider, event.Reference))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("payment not found")
+		}
+		return fmt.Errorf("error fetching payment: %v", err)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO payment_events (payment_id, provider, event_id, status, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (provider, event_id) DO NOTHING
+	`, payment.ID, provider, event.EventID, event.Status, string(event.Payload))
+	if err != nil {
+		return fmt.Errorf("error recording payment event: %v", err)
+	}
+	if inserted, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	} else if inserted == 0 {
+		return nil
+	}
+
+	if payment.Status == string(payments.StatusPending) {
+		var failureReason *string
+		if event.Status == payments.StatusFailed && event.FailureReason != "" {
+			failureReason = &event.FailureReason
+		}
+		_, err = tx.Exec(`
+			UPDATE payments
+			SET status = $1, failure_reason = $2, updated_at = NOW()
+			WHERE id = $3
+		`, event.Status, failureReason, payment.ID)
+		if err != nil {
+			return fmt.Errorf("error updating payment: %v", err)
+		}
+
+		// Only reservations still waiting for payment follow the payment outcome
+		var reservationStatus string
+		err = tx.QueryRow(`SELECT status FROM reservations WHERE id = $1 FOR UPDATE`, payment.ReservationID).Scan(&reservationStatus)
+		if err != nil {
+			return fmt.Errorf("error fetching reservation: %v", err)
+		}
+		if reservationStatus == string(models.ReservationStatusPending) {
+			next := models.ReservationStatusConfirmed
+			if event.Status == payments.StatusFailed {
+				next = models.ReservationStatusCancelled
+			}
+			if err := s.reservations.setStatus(tx, payment.ReservationID, next); err != nil {
+				return err
+			}
+		}
+
+		// The cancellation was settled before this money arrived, so all of it is owed back
+		if reservationStatus == string(models.ReservationStatusCancelled) && event.Status == payments.StatusSucceeded {
+			_, err = tx.Exec(`
+				INSERT INTO reservation_ledger (reservation_id, entry_type, amount, description)
+				VALUES ($1, $2, $3, $4)
+			`, payment.ReservationID, models.LedgerRefund, payment.Amount, "Refund of a payment received after the reservation was cancelled")
+			if err != nil {
+				return fmt.Errorf("error recording ledger entry: %v", err)
+			}
+		}
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return nil
+}
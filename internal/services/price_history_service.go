@@ -0,0 +1,238 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// priceTable describes the rate history table of rooms or snacks
+type priceTable struct {
+	table      string // History table
+	itemColumn string // Column referencing the priced item
+	itemTable  string // Table of the priced items
+	notFound   string // Error returned for unknown items
+}
+
+var (
+	roomPrices  = priceTable{table: "room_prices", itemColumn: "room_id", itemTable: "rooms", notFound: "room not found"}
+	snackPrices = priceTable{table: "snack_prices", itemColumn: "snack_id", itemTable: "snacks", notFound: "snack not found"}
+)
+
+// rateAt is a subquery for the rate of the item itemExpr in effect on the date dateExpr:
+// the latest change effective on or before that date, or the first rate for earlier dates
+func rateAt(t priceTable, itemExpr, dateExpr string) string {
+	return fmt.Sprintf(`(SELECT p.price FROM %[1]s p
+		WHERE p.%[2]s = %[3]s
+		ORDER BY p.effective_from <= %[4]s DESC,
+			CASE WHEN p.effective_from <= %[4]s THEN p.effective_from END DESC,
+			p.effective_from ASC
+		LIMIT 1)`, t.table, t.itemColumn, itemExpr, dateExpr)
+}
+
+// roomRateAt is the hourly rate of a room in effect on a date
+func roomRateAt(roomExpr, dateExpr string) string {
+	return rateAt(roomPrices, roomExpr, dateExpr)
+}
+
+// snackRateAt is the price of a snack in effect on a date
+func snackRateAt(snackExpr, dateExpr string) string {
+	return rateAt(snackPrices, snackExpr, dateExpr)
+}
+
+// effectivePrice returns the price of the change in effect on day following the
+// same rule as rateAt. changes must be sorted newest first.
+func effectivePrice(changes []models.PriceChange, day string) float64 {
+	if len(changes) == 0 {
+		return 0
+	}
+	for _, change := range changes {
+		if change.EffectiveFrom <= day {
+			return change.Price
+		}
+	}
+	return changes[len(changes)-1].Price
+}
+
+// recordPrice sets the price of an item from a date onwards, today when effectiveFrom is nil.
+// A change for the same date is replaced.
+func recordPrice(q queryer, t priceTable, itemID uuid.UUID, price float64, effectiveFrom *string, createdBy *uuid.UUID) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := q.QueryRow(fmt.Sprintf(`
+		INSERT INTO %[1]s (%[2]s, price, effective_from, created_by)
+		VALUES ($1, $2, COALESCE($3::date, CURRENT_DATE), $4)
+		ON CONFLICT (%[2]s, effective_from)
+		DO UPDATE SET price = EXCLUDED.price, created_by = EXCLUDED.created_by, created_at = NOW()
+		RETURNING id
+	`, t.table, t.itemColumn), itemID, price, effectiveFrom, createdBy).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return uuid.Nil, fmt.Errorf("%s", t.notFound)
+		}
+		return uuid.Nil, fmt.Errorf("error recording price: %v", err)
+	}
+	return id, nil
+}
+
+// PriceHistoryService keeps the rate history of rooms and snacks and schedules price changes
+type PriceHistoryService struct {
+	db *sql.DB
+}
+
+func NewPriceHistoryService(db *sql.DB) *PriceHistoryService {
+	return &PriceHistoryService{
+		db: db,
+	}
+}
+
+func (s *PriceHistoryService) history(q queryer, t priceTable, itemID uuid.UUID) (*models.PriceHistoryResponse, error) {
+	var today string
+	err := q.QueryRow(fmt.Sprintf(`
+		SELECT to_char(CURRENT_DATE, 'YYYY-MM-DD') FROM %s WHERE id = $1
+	`, t.itemTable), itemID).Scan(&today)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%s", t.notFound)
+		}
+		return nil, fmt.Errorf("error fetching price history: %v", err)
+	}
+
+	rows, err := q.Query(fmt.Sprintf(`
+		SELECT id, price, to_char(effective_from, 'YYYY-MM-DD'), effective_from > CURRENT_DATE, created_by, created_at
+		FROM %s
+		WHERE %s = $1
+		ORDER BY effective_from DESC
+	`, t.table, t.itemColumn), itemID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying price history: %v", err)
+	}
+	defer rows.Close()
+
+	response := &models.PriceHistoryResponse{
+		ItemID:  itemID,
+		Changes: []models.PriceChange{},
+	}
+	for rows.Next() {
+		var change models.PriceChange
+		err := rows.Scan(&change.ID, &change.Price, &change.EffectiveFrom, &change.Scheduled, &change.CreatedBy, &change.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning price change: %v", err)
+		}
+		response.Changes = append(response.Changes, change)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price history: %v", err)
+	}
+
+	response.CurrentPrice = effectivePrice(response.Changes, today)
+	return response, nil
+}
+
+func (s *PriceHistoryService) schedule(t priceTable, itemID uuid.UUID, req *models.SchedulePriceRequest, userID uuid.UUID) (*models.PriceHistoryResponse, error) {
+	if _, err := time.Parse("2006-01-02", req.EffectiveFrom); err != nil {
+		return nil, fmt.Errorf("invalid effective_from, expected YYYY-MM-DD")
+	}
+
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var past bool
+	err = tx.QueryRow(`SELECT $1::date < CURRENT_DATE`, req.EffectiveFrom).Scan(&past)
+	if err != nil {
+		return nil, fmt.Errorf("error checking effective date: %v", err)
+	}
+	if past {
+		return nil, fmt.Errorf("effective_from must not be in the past")
+	}
+
+	if _, err := recordPrice(tx, t, itemID, req.Price, &req.EffectiveFrom, &userID); err != nil {
+		return nil, err
+	}
+
+	response, err := s.history(tx, t, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return response, nil
+}
+
+// cancel removes a scheduled price change. Changes already in effect are kept
+// as the record of past prices.
+func (s *PriceHistoryService) cancel(t priceTable, itemID, changeID uuid.UUID) error {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var scheduled bool
+	err = tx.QueryRow(fmt.Sprintf(`
+		SELECT effective_from > CURRENT_DATE
+		FROM %s
+		WHERE id = $1 AND %s = $2
+		FOR UPDATE
+	`, t.table, t.itemColumn), changeID, itemID).Scan(&scheduled)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("price change not found")
+		}
+		return fmt.Errorf("error fetching price change: %v", err)
+	}
+	if !scheduled {
+		return fmt.Errorf("only scheduled price changes can be cancelled")
+	}
+
+	// The first rate of an item is never scheduled, so an item always keeps a rate
+	_, err = tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, t.table), changeID)
+	if err != nil {
+		return fmt.Errorf("error deleting price change: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (s *PriceHistoryService) GetRoomPriceHistory(roomID uuid.UUID) (*models.PriceHistoryResponse, error) {
+	return s.history(s.db, roomPrices, roomID)
+}
+
+func (s *PriceHistoryService) ScheduleRoomPrice(roomID uuid.UUID, req *models.SchedulePriceRequest, userID uuid.UUID) (*models.PriceHistoryResponse, error) {
+	return s.schedule(roomPrices, roomID, req, userID)
+}
+
+func (s *PriceHistoryService) CancelRoomPriceChange(roomID, changeID uuid.UUID) error {
+	return s.cancel(roomPrices, roomID, changeID)
+}
+
+func (s *PriceHistoryService) GetSnackPriceHistory(snackID uuid.UUID) (*models.PriceHistoryResponse, error) {
+	return s.history(s.db, snackPrices, snackID)
+}
+
+func (s *PriceHistoryService) ScheduleSnackPrice(snackID uuid.UUID, req *models.SchedulePriceRequest, userID uuid.UUID) (*models.PriceHistoryResponse, error) {
+	return s.schedule(snackPrices, snackID, req, userID)
+}
+
+func (s *PriceHistoryService) CancelSnackPriceChange(snackID, changeID uuid.UUID) error {
+	return s.cancel(snackPrices, snackID, changeID)
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"testing"
+
+	"e_meeting/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectivePrice(t *testing.T) {
+	// Newest first, with a change scheduled for March
+	changes := []models.PriceChange{
+		{Price: 120000, EffectiveFrom: "2026-03-01", Scheduled: true},
+		{Price: 100000, EffectiveFrom: "2026-01-15"},
+		{Price: 90000, EffectiveFrom: "2025-06-01"},
+	}
+
+	assert.Equal(t, 100000.0, effectivePrice(changes, "2026-02-28"))
+	assert.Equal(t, 120000.0, effectivePrice(changes, "2026-03-01"))
+	assert.Equal(t, 100000.0, effectivePrice(changes, "2026-01-15"))
+	assert.Equal(t, 90000.0, effectivePrice(changes, "2026-01-14"))
+
+	// The first rate also applies before it was recorded
+	assert.Equal(t, 90000.0, effectivePrice(changes, "2024-12-31"))
+	assert.Equal(t, 0.0, effectivePrice(nil, "2026-01-01"))
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/models"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PriceQuoteService persists reservation calculations as quotes that lock
+// the calculated prices until they expire
+type PriceQuoteService struct {
+	db       *sql.DB
+	validity time.Duration
+}
+
+func NewPriceQuoteService(db *sql.DB, validity time.Duration) *PriceQuoteService {
+	return &PriceQuoteService{
+		db:       db,
+		validity: validity,
+	}
+}
+
+// SaveQuote stores a calculation as a quote and sets its ID and expiry on the response
+func (s *PriceQuoteService) SaveQuote(tx *sql.Tx, req *models.ReservationCalculationRequest, response *models.ReservationCalculationResponse) error {
+	snacks, err := json.Marshal(normalizeSnackOrder(req.Snacks))
+	if err != nil {
+		return fmt.Errorf("error encoding quote snacks: %v", err)
+	}
+	calculation, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("error encoding quote calculation: %v", err)
+	}
+
+	var promoCode *string
+	if code := strings.ToUpper(strings.TrimSpace(req.PromoCode)); code != "" {
+		promoCode = &code
+	}
+
+	expiresAt := time.Now().Add(s.validity)
+	var quoteID uuid.UUID
+	err = tx.QueryRow(`
+		INSERT INTO price_quotes (
+			user_id, room_id, start_time, end_time, promo_code, snacks, calculation, total, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, req.UserID, req.RoomID, req.StartTime, req.EndTime, promoCode, string(snacks), string(calculation), response.TotalCost, expiresAt).Scan(&quoteID)
+	if err != nil {
+		return fmt.Errorf("error saving quote: %v", err)
+	}
+
+	response.QuoteID = &quoteID
+	response.QuoteExpiresAt = &expiresAt
+	return nil
+}
+
+// LoadQuoteForBooking locks a quote and checks that it can price the given reservation request
+// Returns:
+//   - The quoted calculation, or an error starting with "quote" when it cannot be honoured
+func (s *PriceQuoteService) LoadQuoteForBooking(tx *sql.Tx, req *models.CreateReservationRequest) (*models.ReservationCalculationResponse, error) {
+	var (
+		userID, roomID     uuid.UUID
+		startTime, endTime time.Time
+		promoCode          sql.NullString
+		snacksJSON         string
+		calculationJSON    string
+		expiresAt          time.Time
+		reservationID      *uuid.UUID
+	)
+	err := tx.QueryRow(`
+		SELECT user_id, room_id, start_time, end_time, promo_code, snacks, calculation, expires_at, reservation_id
+		FROM price_quotes
+		WHERE id = $1
+		FOR UPDATE
+	`, req.QuoteID).Scan(&userID, &roomID, &startTime, &endTime, &promoCode, &snacksJSON, &calculationJSON, &expiresAt, &reservationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("quote not found")
+		}
+		return nil, fmt.Errorf("error fetching quote: %v", err)
+	}
+
+	if userID != req.UserID {
+		return nil, fmt.Errorf("quote not found")
+	}
+	if reservationID != nil {
+		return nil, fmt.Errorf("quote has already been used")
+	}
+	if time.Now().After(expiresAt) {
+		return nil, fmt.Errorf("quote has expired, please recalculate the reservation cost")
+	}
+
+	var quotedSnacks []models.SnackOrderItem
+	if err := json.Unmarshal([]byte(snacksJSON), &quotedSnacks); err != nil {
+		return nil, fmt.Errorf("error decoding quote snacks: %v", err)
+	}
+
+	if roomID != req.RoomID ||
+		!startTime.Equal(req.StartTime) || !endTime.Equal(req.EndTime) ||
+		!strings.EqualFold(promoCode.String, strings.TrimSpace(req.PromoCode)) ||
+		!sameSnackOrder(quotedSnacks, normalizeSnackOrder(req.Snacks)) {
+		return nil, fmt.Errorf("quote does not match the reservation request")
+	}
+
+	var calculation models.ReservationCalculationResponse
+	if err := json.Unmarshal([]byte(calculationJSON), &calculation); err != nil {
+		return nil, fmt.Errorf("error decoding quote calculation: %v", err)
+	}
+
+	return &calculation, nil
+}
+
+// MarkQuoteUsed links a quote to the reservation that honoured it so it cannot be reused
+func (s *PriceQuoteService) MarkQuoteUsed(tx *sql.Tx, quoteID, reservationID uuid.UUID) error {
+	_, err := tx.Exec(`UPDATE price_quotes SET reservation_id = $1 WHERE id = $2`, reservationID, quoteID)
+	if err != nil {
+		return fmt.Errorf("error marking quote as used: %v", err)
+	}
+	return nil
+}
+
+// normalizeSnackOrder merges lines for the same snack and orders them by snack ID
+func normalizeSnackOrder(items []models.SnackOrderItem) []models.SnackOrderItem {
+	quantities := make(map[uuid.UUID]int, len(items))
+	var order []uuid.UUID
+	for _, item := range items {
+		if _, ok := quantities[item.SnackID]; !ok {
+			order = append(order, item.SnackID)
+		}
+		quantities[item.SnackID] += item.Quantity
+	}
+
+	normalized := make([]models.SnackOrderItem, 0, len(order))
+	for _, id := range order {
+		normalized = append(normalized, models.SnackOrderItem{SnackID: id, Quantity: quantities[id]})
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		return normalized[i].SnackID.String() < normalized[j].SnackID.String()
+	})
+	return normalized
+}
+
+func sameSnackOrder(a, b []models.SnackOrderItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
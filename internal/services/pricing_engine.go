@@ -0,0 +1,281 @@
+package services
+
+import (
+	"e_meeting/internal/models"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const standardRateName = "standard rate"
+
+// PricingEngine prices a room booking by splitting it into segments at
+// midnight and at every rule window boundary, then applying the best
+// matching rule and holiday surcharge to each segment
+type PricingEngine struct {
+	Location         *time.Location // Time zone used to evaluate days and time-of-day windows
+	MinBillable      time.Duration  // Minimum billable duration, 0 disables it
+	BillingIncrement time.Duration  // Billed duration is rounded up to this increment, 0 disables it
+	TaxRate          float64        // Tax rate in percent applied to the discounted subtotal
+	TaxLabel         string         // Tax name shown to users
+}
+
+// pricedRule is a pricing rule with its time window converted to minutes of day
+type pricedRule struct {
+	rule        models.PricingRule
+	specificity int // 2 = room, 1 = room type, 0 = global
+	hasWindow   bool
+	startMinute int
+	endMinute   int
+}
+
+// PriceRoom computes the room cost for [start, end) at the given hourly base rate
+// Parameters:
+//   - baseRate: The room's hourly price
+//   - start, end: The booked time range
+//   - rules: Active rules already filtered to the room, its room type, or global scope
+//   - holidays: Holidays that may fall within the range
+//
+// Returns:
+//   - The line-item breakdown and total, or an error if a rule has an invalid window
+func (e *PricingEngine) PriceRoom(baseRate float64, start, end time.Time, rules []models.PricingRule, holidays []models.Holiday) (*models.RoomPriceBreakdown, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("end time must be after start time")
+	}
+
+	loc := e.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	start = start.In(loc)
+	end = end.In(loc)
+
+	prepared, err := prepareRules(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	holidayByDate := make(map[string]models.Holiday, len(holidays))
+	for _, h := range holidays {
+		holidayByDate[h.Date] = h
+	}
+
+	breakdown := &models.RoomPriceBreakdown{}
+	boundaries := segmentBoundaries(start, end, prepared, loc)
+	for i := 0; i < len(boundaries)-1; i++ {
+		segStart, segEnd := boundaries[i], boundaries[i+1]
+		item := priceSegment(baseRate, segStart, segEnd, prepared, holidayByDate)
+
+		// Merge with the previous line when the same pricing applies
+		if n := len(breakdown.LineItems); n > 0 {
+			prev := &breakdown.LineItems[n-1]
+			if prev.EndTime.Equal(item.StartTime) && prev.RuleName == item.RuleName &&
+				prev.Holiday == item.Holiday && prev.Multiplier == item.Multiplier {
+				prev.EndTime = item.EndTime
+				prev.Hours = prev.EndTime.Sub(prev.StartTime).Hours()
+				prev.Amount = roundMoney(prev.BaseRate * prev.Multiplier * prev.Hours)
+				continue
+			}
+		}
+		breakdown.LineItems = append(breakdown.LineItems, item)
+	}
+
+	actual := end.Sub(start)
+	billed := actual
+	if billed < e.MinBillable {
+		billed = e.MinBillable
+	}
+	if e.BillingIncrement > 0 && billed%e.BillingIncrement != 0 {
+		billed = (billed/e.BillingIncrement + 1) * e.BillingIncrement
+	}
+
+	// Bill any extra time at the rate of the last segment
+	if extra := billed - actual; extra > 0 {
+		last := breakdown.LineItems[len(breakdown.LineItems)-1]
+		description := "billing increment rounding"
+		if actual < e.MinBillable {
+			description = "minimum charge"
+		}
+		breakdown.LineItems = append(breakdown.LineItems, models.PriceLineItem{
+			Description: description,
+			StartTime:   end,
+			EndTime:     end.Add(extra),
+			Hours:       extra.Hours(),
+			BaseRate:    baseRate,
+			Multiplier:  last.Multiplier,
+			RuleID:      last.RuleID,
+			RuleName:    last.RuleName,
+			Holiday:     last.Holiday,
+			Amount:      roundMoney(baseRate * last.Multiplier * extra.Hours()),
+		})
+	}
+
+	for _, item := range breakdown.LineItems {
+		breakdown.Total += item.Amount
+	}
+	breakdown.Total = roundMoney(breakdown.Total)
+	breakdown.ActualHours = actual.Hours()
+	breakdown.BilledHours = billed.Hours()
+
+	return breakdown, nil
+}
+
+// Tax returns the tax due on a taxable amount at the configured rate
+func (e *PricingEngine) Tax(taxable float64) float64 {
+	if taxable <= 0 || e.TaxRate <= 0 {
+		return 0
+	}
+	return roundMoney(taxable * e.TaxRate / 100)
+}
+
+func prepareRules(rules []models.PricingRule) ([]pricedRule, error) {
+	prepared := make([]pricedRule, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.Active {
+			continue
+		}
+
+		p := pricedRule{rule: rule}
+		switch {
+		case rule.RoomID != nil:
+			p.specificity = 2
+		case rule.RoomTypeID != nil:
+			p.specificity = 1
+		}
+
+		if rule.StartTime != nil && rule.EndTime != nil {
+			var err error
+			if p.startMinute, err = parseMinuteOfDay(*rule.StartTime); err != nil {
+				return nil, fmt.Errorf("invalid start_time for pricing rule %s: %v", rule.Name, err)
+			}
+			if p.endMinute, err = parseMinuteOfDay(*rule.EndTime); err != nil {
+				return nil, fmt.Errorf("invalid end_time for pricing rule %s: %v", rule.Name, err)
+			}
+			p.hasWindow = p.startMinute != p.endMinute
+		}
+		prepared = append(prepared, p)
+	}
+
+	// Highest priority first, then the most specific scope
+	sort.SliceStable(prepared, func(i, j int) bool {
+		if prepared[i].rule.Priority != prepared[j].rule.Priority {
+			return prepared[i].rule.Priority > prepared[j].rule.Priority
+		}
+		return prepared[i].specificity > prepared[j].specificity
+	})
+
+	return prepared, nil
+}
+
+// segmentBoundaries returns the sorted cut points of [start, end), including both ends
+func segmentBoundaries(start, end time.Time, rules []pricedRule, loc *time.Location) []time.Time {
+	points := []time.Time{start, end}
+
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	for !day.After(end) {
+		points = append(points, day)
+		for _, r := range rules {
+			if r.hasWindow {
+				points = append(points,
+					day.Add(time.Duration(r.startMinute)*time.Minute),
+					day.Add(time.Duration(r.endMinute)*time.Minute),
+				)
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	sort.Slice(points, func(i, j int) bool { return points[i].Before(points[j]) })
+
+	var boundaries []time.Time
+	for _, p := range points {
+		if p.Before(start) || p.After(end) {
+			continue
+		}
+		if len(boundaries) > 0 && boundaries[len(boundaries)-1].Equal(p) {
+			continue
+		}
+		boundaries = append(boundaries, p)
+	}
+	return boundaries
+}
+
+func priceSegment(baseRate float64, start, end time.Time, rules []pricedRule, holidays map[string]models.Holiday) models.PriceLineItem {
+	item := models.PriceLineItem{
+		Description: "room",
+		StartTime:   start,
+		EndTime:     end,
+		Hours:       end.Sub(start).Hours(),
+		BaseRate:    baseRate,
+		Multiplier:  1,
+		RuleName:    standardRateName,
+	}
+
+	weekday := int(start.Weekday())
+	minute := start.Hour()*60 + start.Minute()
+	for _, r := range rules {
+		if !r.matches(weekday, minute) {
+			continue
+		}
+		id := r.rule.ID
+		item.RuleID = &id
+		item.RuleName = r.rule.Name
+		item.Multiplier = r.rule.Multiplier
+		break
+	}
+
+	if h, ok := holidays[start.Format("2006-01-02")]; ok {
+		item.Holiday = h.Name
+		item.Multiplier *= h.SurchargeMultiplier
+	}
+
+	item.Amount = roundMoney(item.BaseRate * item.Multiplier * item.Hours)
+	return item
+}
+
+func (r pricedRule) matches(weekday, minute int) bool {
+	if len(r.rule.DaysOfWeek) > 0 {
+		found := false
+		for _, d := range r.rule.DaysOfWeek {
+			if d == weekday {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if !r.hasWindow {
+		return true
+	}
+	if r.startMinute < r.endMinute {
+		return minute >= r.startMinute && minute < r.endMinute
+	}
+	return minute >= r.startMinute || minute < r.endMinute
+}
+
+// parseMinuteOfDay converts HH:MM or HH:MM:SS into minutes since midnight
+func parseMinuteOfDay(value string) (int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("invalid hour in %q", value)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid minute in %q", value)
+	}
+	return hour*60 + minute, nil
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"e_meeting/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestPricingEngine_StandardRate(t *testing.T) {
+	engine := &PricingEngine{Location: time.UTC}
+	start := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC) // Wednesday
+
+	breakdown, err := engine.PriceRoom(100000, start, start.Add(2*time.Hour), nil, nil)
+	require.NoError(t, err)
+
+	require.Len(t, breakdown.LineItems, 1)
+	assert.Equal(t, standardRateName, breakdown.LineItems[0].RuleName)
+	assert.Equal(t, 200000.0, breakdown.Total)
+	assert.Equal(t, 2.0, breakdown.BilledHours)
+}
+
+func TestPricingEngine_PeakWindowSplitsSegments(t *testing.T) {
+	engine := &PricingEngine{Location: time.UTC}
+	peak := models.PricingRule{
+		ID:         uuid.New(),
+		Name:       "peak hours",
+		DaysOfWeek: []int{1, 2, 3, 4, 5},
+		StartTime:  strPtr("10:00"),
+		EndTime:    strPtr("12:00"),
+		Multiplier: 1.5,
+		Active:     true,
+	}
+	start := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC) // Wednesday
+
+	breakdown, err := engine.PriceRoom(100000, start, start.Add(4*time.Hour), []models.PricingRule{peak}, nil)
+	require.NoError(t, err)
+
+	require.Len(t, breakdown.LineItems, 3)
+	assert.Equal(t, standardRateName, breakdown.LineItems[0].RuleName)
+	assert.Equal(t, "peak hours", breakdown.LineItems[1].RuleName)
+	assert.Equal(t, 300000.0, breakdown.LineItems[1].Amount)
+	assert.Equal(t, standardRateName, breakdown.LineItems[2].RuleName)
+	assert.Equal(t, 500000.0, breakdown.Total)
+}
+
+func TestPricingEngine_PriorityAndHoliday(t *testing.T) {
+	engine := &PricingEngine{Location: time.UTC}
+	roomID := uuid.New()
+	weekend := models.PricingRule{ID: uuid.New(), Name: "weekend", DaysOfWeek: []int{0, 6}, Multiplier: 1.2, Active: true}
+	roomWeekend := models.PricingRule{ID: uuid.New(), Name: "room weekend", RoomID: &roomID, DaysOfWeek: []int{0, 6}, Multiplier: 2, Active: true, Priority: 1}
+	holiday := models.Holiday{Date: "2026-03-07", Name: "Founders Day", SurchargeMultiplier: 1.5}
+	start := time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC) // Saturday
+
+	breakdown, err := engine.PriceRoom(100000, start, start.Add(time.Hour), []models.PricingRule{weekend, roomWeekend}, []models.Holiday{holiday})
+	require.NoError(t, err)
+
+	require.Len(t, breakdown.LineItems, 1)
+	assert.Equal(t, "room weekend", breakdown.LineItems[0].RuleName)
+	assert.Equal(t, "Founders Day", breakdown.LineItems[0].Holiday)
+	assert.Equal(t, 3.0, breakdown.LineItems[0].Multiplier)
+	assert.Equal(t, 300000.0, breakdown.Total)
+}
+
+func TestPricingEngine_MinimumAndIncrement(t *testing.T) {
+	engine := &PricingEngine{Location: time.UTC, MinBillable: time.Hour, BillingIncrement: 30 * time.Minute}
+	start := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	breakdown, err := engine.PriceRoom(100000, start, start.Add(40*time.Minute), nil, nil)
+	require.NoError(t, err)
+	require.Len(t, breakdown.LineItems, 2)
+	assert.Equal(t, "minimum charge", breakdown.LineItems[1].Description)
+	assert.Equal(t, 1.0, breakdown.BilledHours)
+	assert.Equal(t, 100000.0, breakdown.Total)
+
+	breakdown, err = engine.PriceRoom(100000, start, start.Add(70*time.Minute), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "billing increment rounding", breakdown.LineItems[1].Description)
+	assert.Equal(t, 1.5, breakdown.BilledHours)
+	assert.Equal(t, 150000.0, breakdown.Total)
+}
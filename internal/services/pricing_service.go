@@ -0,0 +1,396 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// queryer is implemented by both *sql.DB and *sql.Tx so pricing lookups can
+// run inside the caller's transaction
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+type PricingService struct {
+	db     *sql.DB
+	engine *PricingEngine
+}
+
+func NewPricingService(db *sql.DB, engine *PricingEngine) *PricingService {
+	return &PricingService{
+		db:     db,
+		engine: engine,
+	}
+}
+
+// PriceRoom loads the rules and holidays relevant to a room and prices [start, end)
+func (s *PricingService) PriceRoom(q queryer, roomID uuid.UUID, baseRate float64, start, end time.Time) (*models.RoomPriceBreakdown, error) {
+	rows, err := q.Query(`
+		SELECT
+			pr.id, pr.name, pr.room_id, pr.room_type_id, pr.days_of_week,
+			to_char(pr.start_time, 'HH24:MI'), to_char(pr.end_time, 'HH24:MI'),
+			pr.multiplier, pr.priority, pr.active, pr.created_at, pr.updated_at
+		FROM pricing_rules pr
+		WHERE pr.active = true
+		AND (
+			pr.room_id = $1
+			OR pr.room_type_id = (SELECT room_type_id FROM rooms WHERE id = $1)
+			OR (pr.room_id IS NULL AND pr.room_type_id IS NULL)
+		)`, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pricing rules: %v", err)
+	}
+	defer rows.Close()
+
+	var rules []models.PricingRule
+	for rows.Next() {
+		rule, err := scanPricingRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pricing rules: %v", err)
+	}
+
+	loc := s.engine.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	holidays, err := s.holidaysBetween(q, start.In(loc).Format("2006-01-02"), end.In(loc).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.engine.PriceRoom(baseRate, start, end, rules, holidays)
+}
+
+// Day returns the date of t in the pricing time zone as YYYY-MM-DD, the date
+// whose room and snack rates apply to a booking starting at t
+func (s *PricingService) Day(t time.Time) string {
+	loc := s.engine.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format("2006-01-02")
+}
+
+// RoomRate returns the hourly rate of a room in effect on the day of start
+func (s *PricingService) RoomRate(q queryer, roomID uuid.UUID, start time.Time) (float64, error) {
+	var rate sql.NullFloat64
+	err := q.QueryRow(`SELECT `+roomRateAt("$1::uuid", "$2::date"), roomID, s.Day(start)).Scan(&rate)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching room rate: %v", err)
+	}
+	if !rate.Valid {
+		return 0, fmt.Errorf("room has no price")
+	}
+	return rate.Float64, nil
+}
+
+// Tax returns the configured tax label and rate with the tax due on a taxable amount
+func (s *PricingService) Tax(taxable float64) (label string, rate float64, amount float64) {
+	return s.engine.TaxLabel, s.engine.TaxRate, s.engine.Tax(taxable)
+}
+
+func (s *PricingService) holidaysBetween(q queryer, fromDate, toDate string) ([]models.Holiday, error) {
+	rows, err := q.Query(`
+		SELECT id, to_char(date, 'YYYY-MM-DD'), name, surcharge_multiplier, created_at
+		FROM holidays
+		WHERE date BETWEEN $1::date AND $2::date
+		ORDER BY date ASC`, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("error querying holidays: %v", err)
+	}
+	defer rows.Close()
+
+	var holidays []models.Holiday
+	for rows.Next() {
+		var h models.Holiday
+		if err := rows.Scan(&h.ID, &h.Date, &h.Name, &h.SurchargeMultiplier, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning holiday: %v", err)
+		}
+		holidays = append(holidays, h)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating holidays: %v", err)
+	}
+
+	return holidays, nil
+}
+
+func scanPricingRule(row interface{ Scan(...interface{}) error }) (*models.PricingRule, error) {
+	var rule models.PricingRule
+	var days pq.Int64Array
+	var startTime, endTime sql.NullString
+
+	err := row.Scan(
+		&rule.ID, &rule.Name, &rule.RoomID, &rule.RoomTypeID, &days,
+		&startTime, &endTime,
+		&rule.Multiplier, &rule.Priority, &rule.Active, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error scanning pricing rule: %v", err)
+	}
+
+	rule.DaysOfWeek = make([]int, 0, len(days))
+	for _, d := range days {
+		rule.DaysOfWeek = append(rule.DaysOfWeek, int(d))
+	}
+	if startTime.Valid {
+		rule.StartTime = &startTime.String
+	}
+	if endTime.Valid {
+		rule.EndTime = &endTime.String
+	}
+
+	return &rule, nil
+}
+
+func (s *PricingService) GetPricingRules() (*models.PricingRuleListResponse, error) {
+	rows, err := s.db.Query(`
+		SELECT
+			id, name, room_id, room_type_id, days_of_week,
+			to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'),
+			multiplier, priority, active, created_at, updated_at
+		FROM pricing_rules
+		ORDER BY priority DESC, name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pricing rules: %v", err)
+	}
+	defer rows.Close()
+
+	var rules []models.PricingRule
+	for rows.Next() {
+		rule, err := scanPricingRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pricing rules: %v", err)
+	}
+
+	return &models.PricingRuleListResponse{
+		Rules: rules,
+	}, nil
+}
+
+func (s *PricingService) CreatePricingRule(req *models.CreatePricingRuleRequest) (*models.PricingRule, error) {
+	if req.RoomID != nil && req.RoomTypeID != nil {
+		return nil, fmt.Errorf("pricing rule cannot target both a room and a room type")
+	}
+	if (req.StartTime == nil) != (req.EndTime == nil) {
+		return nil, fmt.Errorf("start_time and end_time must be provided together")
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	days := make(pq.Int64Array, 0, len(req.DaysOfWeek))
+	for _, d := range req.DaysOfWeek {
+		days = append(days, int64(d))
+	}
+
+	rule, err := scanPricingRule(s.db.QueryRow(`
+		INSERT INTO pricing_rules (name, room_id, room_type_id, days_of_week, start_time, end_time, multiplier, priority, active)
+		VALUES ($1, $2, $3, $4, $5::time, $6::time, $7, $8, $9)
+		RETURNING
+			id, name, room_id, room_type_id, days_of_week,
+			to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'),
+			multiplier, priority, active, created_at, updated_at`,
+		req.Name, req.RoomID, req.RoomTypeID, days, req.StartTime, req.EndTime, req.Multiplier, req.Priority, active,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error creating pricing rule: %v", err)
+	}
+
+	return rule, nil
+}
+
+// applyPricingRuleUpdate changes the fields of rule the request provides. A
+// cleared window is replaced by any days or times provided along with it.
+func applyPricingRuleUpdate(rule *models.PricingRule, req *models.UpdatePricingRuleRequest) error {
+	if req.ClearWindow {
+		rule.DaysOfWeek = []int{}
+		rule.StartTime = nil
+		rule.EndTime = nil
+	}
+
+	// Update only provided fields
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.DaysOfWeek != nil {
+		rule.DaysOfWeek = req.DaysOfWeek
+	}
+	if req.StartTime != nil {
+		rule.StartTime = req.StartTime
+	}
+	if req.EndTime != nil {
+		rule.EndTime = req.EndTime
+	}
+	if req.Multiplier != nil {
+		rule.Multiplier = *req.Multiplier
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+	if (rule.StartTime == nil) != (rule.EndTime == nil) {
+		return fmt.Errorf("start_time and end_time must be provided together")
+	}
+	return nil
+}
+
+func (s *PricingService) UpdatePricingRule(id uuid.UUID, req *models.UpdatePricingRuleRequest) (*models.PricingRule, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	rule, err := scanPricingRule(tx.QueryRow(`
+		SELECT
+			id, name, room_id, room_type_id, days_of_week,
+			to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'),
+			multiplier, priority, active, created_at, updated_at
+		FROM pricing_rules
+		WHERE id = $1
+		FOR UPDATE`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pricing rule not found")
+		}
+		return nil, err
+	}
+
+	if err := applyPricingRuleUpdate(rule, req); err != nil {
+		return nil, err
+	}
+
+	days := make(pq.Int64Array, 0, len(rule.DaysOfWeek))
+	for _, d := range rule.DaysOfWeek {
+		days = append(days, int64(d))
+	}
+
+	updated, err := scanPricingRule(tx.QueryRow(`
+		UPDATE pricing_rules
+		SET name = $1, days_of_week = $2, start_time = $3::time, end_time = $4::time,
+			multiplier = $5, priority = $6, active = $7, updated_at = NOW()
+		WHERE id = $8
+		RETURNING
+			id, name, room_id, room_type_id, days_of_week,
+			to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'),
+			multiplier, priority, active, created_at, updated_at`,
+		rule.Name, days, rule.StartTime, rule.EndTime, rule.Multiplier, rule.Priority, rule.Active, id,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error updating pricing rule: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return updated, nil
+}
+
+func (s *PricingService) DeletePricingRule(id uuid.UUID) error {
+	result, err := s.db.Exec(`DELETE FROM pricing_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting pricing rule: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("pricing rule not found")
+	}
+
+	return nil
+}
+
+func (s *PricingService) GetHolidays() (*models.HolidayListResponse, error) {
+	rows, err := s.db.Query(`
+		SELECT id, to_char(date, 'YYYY-MM-DD'), name, surcharge_multiplier, created_at
+		FROM holidays
+		ORDER BY date ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying holidays: %v", err)
+	}
+	defer rows.Close()
+
+	var holidays []models.Holiday
+	for rows.Next() {
+		var h models.Holiday
+		if err := rows.Scan(&h.ID, &h.Date, &h.Name, &h.SurchargeMultiplier, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning holiday: %v", err)
+		}
+		holidays = append(holidays, h)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating holidays: %v", err)
+	}
+
+	return &models.HolidayListResponse{
+		Holidays: holidays,
+	}, nil
+}
+
+func (s *PricingService) CreateHoliday(req *models.CreateHolidayRequest) (*models.Holiday, error) {
+	var h models.Holiday
+	err := s.db.QueryRow(`
+		INSERT INTO holidays (date, name, surcharge_multiplier)
+		VALUES ($1::date, $2, $3)
+		RETURNING id, to_char(date, 'YYYY-MM-DD'), name, surcharge_multiplier, created_at`,
+		req.Date, req.Name, req.SurchargeMultiplier,
+	).Scan(&h.ID, &h.Date, &h.Name, &h.SurchargeMultiplier, &h.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, fmt.Errorf("holiday already exists for this date")
+		}
+		return nil, fmt.Errorf("error creating holiday: %v", err)
+	}
+
+	return &h, nil
+}
+
+func (s *PricingService) DeleteHoliday(id uuid.UUID) error {
+	result, err := s.db.Exec(`DELETE FROM holidays WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting holiday: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("holiday not found")
+	}
+
+	return nil
+}
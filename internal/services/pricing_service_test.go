@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+
+	"e_meeting/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPricingRuleUpdate(t *testing.T) {
+	newRule := func() *models.PricingRule {
+		return &models.PricingRule{
+			Name:       "peak hours",
+			DaysOfWeek: []int{1, 2, 3, 4, 5},
+			StartTime:  strPtr("10:00"),
+			EndTime:    strPtr("12:00"),
+			Multiplier: 1.5,
+		}
+	}
+
+	// Fields left out are kept
+	rule := newRule()
+	multiplier := 2.0
+	require.NoError(t, applyPricingRuleUpdate(rule, &models.UpdatePricingRuleRequest{Multiplier: &multiplier}))
+	assert.Equal(t, 2.0, rule.Multiplier)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, rule.DaysOfWeek)
+	assert.Equal(t, "10:00", *rule.StartTime)
+
+	// A cleared window matches every day and the whole day
+	rule = newRule()
+	require.NoError(t, applyPricingRuleUpdate(rule, &models.UpdatePricingRuleRequest{ClearWindow: true}))
+	assert.Empty(t, rule.DaysOfWeek)
+	assert.Nil(t, rule.StartTime)
+	assert.Nil(t, rule.EndTime)
+
+	// Days provided along with it replace the cleared window
+	rule = newRule()
+	require.NoError(t, applyPricingRuleUpdate(rule, &models.UpdatePricingRuleRequest{ClearWindow: true, DaysOfWeek: []int{0, 6}}))
+	assert.Equal(t, []int{0, 6}, rule.DaysOfWeek)
+	assert.Nil(t, rule.StartTime)
+
+	// Half a time window is refused
+	rule = newRule()
+	err := applyPricingRuleUpdate(rule, &models.UpdatePricingRuleRequest{ClearWindow: true, StartTime: strPtr("08:00")})
+	assert.EqualError(t, err, "start_time and end_time must be provided together")
+}
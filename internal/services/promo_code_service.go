@@ -0,0 +1,291 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/models"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const promoCodeColumns = `
+	pc.id, pc.code, pc.description, pc.discount_type, pc.discount_value, pc.applies_to, pc.room_type_id,
+	pc.valid_from, pc.valid_until, pc.max_uses, pc.max_uses_per_user, pc.active, pc.created_at, pc.updated_at`
+
+type PromoCodeService struct {
+	db *sql.DB
+}
+
+func NewPromoCodeService(db *sql.DB) *PromoCodeService {
+	return &PromoCodeService{
+		db: db,
+	}
+}
+
+func scanPromoCode(row interface{ Scan(...interface{}) error }) (*models.PromoCode, error) {
+	var promo models.PromoCode
+	err := row.Scan(
+		&promo.ID, &promo.Code, &promo.Description, &promo.DiscountType, &promo.DiscountValue, &promo.AppliesTo, &promo.RoomTypeID,
+		&promo.ValidFrom, &promo.ValidUntil, &promo.MaxUses, &promo.MaxUsesPerUser, &promo.Active, &promo.CreatedAt, &promo.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+// ApplyPromoCode validates a promo code for a user and an order and returns the discount it grants
+// Parameters:
+//   - q: The database or transaction to query
+//   - code: The promo code entered by the user (case-insensitive)
+//   - userID: The user placing the order, used for per-user limits
+//   - roomID: The booked room, used for room type restrictions
+//   - roomCost, snackCost: Order subtotals the discount may apply to
+//   - lock: Lock the promo code row so concurrent bookings cannot exceed usage limits
+//
+// Returns:
+//   - The discount line, or an error starting with "promo code" when the code cannot be used
+func (s *PromoCodeService) ApplyPromoCode(q queryer, code string, userID, roomID uuid.UUID, roomCost, snackCost float64, lock bool) (*models.DiscountLine, error) {
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes pc WHERE pc.code = $1`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+
+	promo, err := scanPromoCode(q.QueryRow(query, strings.ToUpper(strings.TrimSpace(code))))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("promo code not found")
+		}
+		return nil, fmt.Errorf("error fetching promo code: %v", err)
+	}
+
+	if !promo.Active {
+		return nil, fmt.Errorf("promo code is inactive")
+	}
+
+	now := time.Now()
+	if now.Before(promo.ValidFrom) || !now.Before(promo.ValidUntil) {
+		return nil, fmt.Errorf("promo code is not valid at this time")
+	}
+
+	if promo.RoomTypeID != nil {
+		var roomTypeID *uuid.UUID
+		err = q.QueryRow(`SELECT room_type_id FROM rooms WHERE id = $1`, roomID).Scan(&roomTypeID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("error checking room type: %v", err)
+		}
+		if roomTypeID == nil || *roomTypeID != *promo.RoomTypeID {
+			return nil, fmt.Errorf("promo code does not apply to this room")
+		}
+	}
+
+	if promo.MaxUses != nil || promo.MaxUsesPerUser != nil {
+		var totalUses, userUses int
+		err = q.QueryRow(`
+			SELECT COUNT(*), COUNT(*) FILTER (WHERE pcr.user_id = $2)
+			FROM promo_code_redemptions pcr
+			JOIN reservations r ON r.id = pcr.reservation_id
+			WHERE pcr.promo_code_id = $1 AND r.status != 'cancelled'
+		`, promo.ID, userID).Scan(&totalUses, &userUses)
+		if err != nil {
+			return nil, fmt.Errorf("error counting promo code usage: %v", err)
+		}
+		if promo.MaxUses != nil && totalUses >= *promo.MaxUses {
+			return nil, fmt.Errorf("promo code usage limit reached")
+		}
+		if promo.MaxUsesPerUser != nil && userUses >= *promo.MaxUsesPerUser {
+			return nil, fmt.Errorf("promo code usage limit per user reached")
+		}
+	}
+
+	return promoDiscount(promo, roomCost, snackCost)
+}
+
+// promoDiscount computes the discount of a promo code on an order. The discount
+// never exceeds the part of the order the code applies to.
+func promoDiscount(promo *models.PromoCode, roomCost, snackCost float64) (*models.DiscountLine, error) {
+	var base float64
+	switch promo.AppliesTo {
+	case models.PromoScopeRoom:
+		base = roomCost
+	case models.PromoScopeSnacks:
+		base = snackCost
+	default:
+		base = roomCost + snackCost
+	}
+	if base <= 0 {
+		return nil, fmt.Errorf("promo code does not apply to this order")
+	}
+
+	var amount float64
+	var description string
+	if promo.DiscountType == models.DiscountTypePercentage {
+		amount = base * promo.DiscountValue / 100
+		description = fmt.Sprintf("%g%% off %s", promo.DiscountValue, promo.AppliesTo)
+	} else {
+		amount = promo.DiscountValue
+		description = fmt.Sprintf("%.2f off %s", promo.DiscountValue, promo.AppliesTo)
+	}
+	amount = roundMoney(math.Min(amount, base))
+
+	return &models.DiscountLine{
+		PromoCodeID: promo.ID,
+		Code:        promo.Code,
+		AppliesTo:   promo.AppliesTo,
+		Description: description,
+		Amount:      amount,
+	}, nil
+}
+
+// RecordRedemption stores the use of a promo code by a reservation
+func (s *PromoCodeService) RecordRedemption(tx *sql.Tx, discount *models.DiscountLine, userID, reservationID uuid.UUID) error {
+	_, err := tx.Exec(`
+		INSERT INTO promo_code_redemptions (promo_code_id, user_id, reservation_id, discount_amount)
+		VALUES ($1, $2, $3, $4)
+	`, discount.PromoCodeID, userID, reservationID, discount.Amount)
+	if err != nil {
+		return fmt.Errorf("error recording promo code redemption: %v", err)
+	}
+	return nil
+}
+
+func (s *PromoCodeService) GetPromoCodes() (*models.PromoCodeListResponse, error) {
+	rows, err := s.db.Query(`
+		SELECT ` + promoCodeColumns + `,
+			(SELECT COUNT(*)
+				FROM promo_code_redemptions pcr
+				JOIN reservations r ON r.id = pcr.reservation_id
+				WHERE pcr.promo_code_id = pc.id AND r.status != 'cancelled') AS times_used
+		FROM promo_codes pc
+		ORDER BY pc.valid_from DESC, pc.code ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying promo codes: %v", err)
+	}
+	defer rows.Close()
+
+	var promoCodes []models.PromoCode
+	for rows.Next() {
+		var promo models.PromoCode
+		err := rows.Scan(
+			&promo.ID, &promo.Code, &promo.Description, &promo.DiscountType, &promo.DiscountValue, &promo.AppliesTo, &promo.RoomTypeID,
+			&promo.ValidFrom, &promo.ValidUntil, &promo.MaxUses, &promo.MaxUsesPerUser, &promo.Active, &promo.CreatedAt, &promo.UpdatedAt,
+			&promo.TimesUsed,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning promo code: %v", err)
+		}
+		promoCodes = append(promoCodes, promo)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating promo codes: %v", err)
+	}
+
+	return &models.PromoCodeListResponse{
+		PromoCodes: promoCodes,
+	}, nil
+}
+
+func (s *PromoCodeService) CreatePromoCode(req *models.CreatePromoCodeRequest) (*models.PromoCode, error) {
+	if req.DiscountType == models.DiscountTypePercentage && req.DiscountValue > 100 {
+		return nil, fmt.Errorf("percentage discount cannot exceed 100")
+	}
+
+	promo, err := scanPromoCode(s.db.QueryRow(`
+		INSERT INTO promo_codes (
+			code, description, discount_type, discount_value, applies_to, room_type_id,
+			valid_from, valid_until, max_uses, max_uses_per_user
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING `+strings.ReplaceAll(promoCodeColumns, "pc.", ""),
+		strings.ToUpper(req.Code), req.Description, req.DiscountType, req.DiscountValue, req.AppliesTo, req.RoomTypeID,
+		req.ValidFrom, req.ValidUntil, req.MaxUses, req.MaxUsesPerUser,
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, fmt.Errorf("promo code already exists")
+		}
+		return nil, fmt.Errorf("error creating promo code: %v", err)
+	}
+
+	return promo, nil
+}
+
+// applyPromoCodeUpdate changes the fields of promo the request provides. A
+// cleared limit is replaced by a limit provided along with it.
+func applyPromoCodeUpdate(promo *models.PromoCode, req *models.UpdatePromoCodeRequest) error {
+	if req.ClearMaxUses {
+		promo.MaxUses = nil
+	}
+	if req.ClearMaxUsesPerUser {
+		promo.MaxUsesPerUser = nil
+	}
+
+	// Update only provided fields
+	if req.Description != nil {
+		promo.Description = req.Description
+	}
+	if req.ValidFrom != nil {
+		promo.ValidFrom = *req.ValidFrom
+	}
+	if req.ValidUntil != nil {
+		promo.ValidUntil = *req.ValidUntil
+	}
+	if req.MaxUses != nil {
+		promo.MaxUses = req.MaxUses
+	}
+	if req.MaxUsesPerUser != nil {
+		promo.MaxUsesPerUser = req.MaxUsesPerUser
+	}
+	if req.Active != nil {
+		promo.Active = *req.Active
+	}
+	if !promo.ValidUntil.After(promo.ValidFrom) {
+		return fmt.Errorf("valid_until must be after valid_from")
+	}
+	return nil
+}
+
+func (s *PromoCodeService) UpdatePromoCode(id uuid.UUID, req *models.UpdatePromoCodeRequest) (*models.PromoCode, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	promo, err := scanPromoCode(tx.QueryRow(`SELECT `+promoCodeColumns+` FROM promo_codes pc WHERE pc.id = $1 FOR UPDATE`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("promo code not found")
+		}
+		return nil, fmt.Errorf("error fetching promo code: %v", err)
+	}
+
+	if err := applyPromoCodeUpdate(promo, req); err != nil {
+		return nil, err
+	}
+
+	updated, err := scanPromoCode(tx.QueryRow(`
+		UPDATE promo_codes
+		SET description = $1, valid_from = $2, valid_until = $3, max_uses = $4, max_uses_per_user = $5, active = $6, updated_at = NOW()
+		WHERE id = $7
+		RETURNING `+strings.ReplaceAll(promoCodeColumns, "pc.", ""),
+		promo.Description, promo.ValidFrom, promo.ValidUntil, promo.MaxUses, promo.MaxUsesPerUser, promo.Active, id,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error updating promo code: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"e_meeting/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromoDiscount_Percentage(t *testing.T) {
+	promo := &models.PromoCode{
+		ID:            uuid.New(),
+		Code:          "SPRING10",
+		DiscountType:  models.DiscountTypePercentage,
+		DiscountValue: 10,
+		AppliesTo:     models.PromoScopeAll,
+	}
+
+	discount, err := promoDiscount(promo, 200000, 50000)
+	require.NoError(t, err)
+	assert.Equal(t, promo.ID, discount.PromoCodeID)
+	assert.Equal(t, "SPRING10", discount.Code)
+	assert.Equal(t, "10% off all", discount.Description)
+	assert.Equal(t, 25000.0, discount.Amount)
+}
+
+func TestPromoDiscount_FixedAmount(t *testing.T) {
+	promo := &models.PromoCode{
+		Code:          "FLAT",
+		DiscountType:  models.DiscountTypeFixed,
+		DiscountValue: 30000,
+		AppliesTo:     models.PromoScopeAll,
+	}
+
+	discount, err := promoDiscount(promo, 200000, 50000)
+	require.NoError(t, err)
+	assert.Equal(t, "30000.00 off all", discount.Description)
+	assert.Equal(t, 30000.0, discount.Amount)
+}
+
+func TestPromoDiscount_Scope(t *testing.T) {
+	promo := &models.PromoCode{
+		DiscountType:  models.DiscountTypePercentage,
+		DiscountValue: 50,
+		AppliesTo:     models.PromoScopeRoom,
+	}
+
+	// Only the part of the order the code applies to is discounted
+	discount, err := promoDiscount(promo, 200000, 50000)
+	require.NoError(t, err)
+	assert.Equal(t, models.PromoScopeRoom, discount.AppliesTo)
+	assert.Equal(t, 100000.0, discount.Amount)
+
+	promo.AppliesTo = models.PromoScopeSnacks
+	discount, err = promoDiscount(promo, 200000, 50000)
+	require.NoError(t, err)
+	assert.Equal(t, 25000.0, discount.Amount)
+
+	// A snack code on an order without snacks has nothing to discount
+	_, err = promoDiscount(promo, 200000, 0)
+	assert.EqualError(t, err, "promo code does not apply to this order")
+}
+
+func TestPromoDiscount_CappedAtSubtotal(t *testing.T) {
+	promo := &models.PromoCode{
+		DiscountType:  models.DiscountTypeFixed,
+		DiscountValue: 80000,
+		AppliesTo:     models.PromoScopeSnacks,
+	}
+
+	discount, err := promoDiscount(promo, 200000, 50000)
+	require.NoError(t, err)
+	assert.Equal(t, 50000.0, discount.Amount, "a fixed discount never exceeds the snack subtotal")
+
+	promo.AppliesTo = models.PromoScopeRoom
+	promo.DiscountValue = 250000
+	discount, err = promoDiscount(promo, 200000, 50000)
+	require.NoError(t, err)
+	assert.Equal(t, 200000.0, discount.Amount, "a fixed discount never exceeds the room subtotal")
+
+	// Percentages over 100 are capped the same way
+	promo.DiscountType = models.DiscountTypePercentage
+	promo.DiscountValue = 150
+	discount, err = promoDiscount(promo, 200000, 50000)
+	require.NoError(t, err)
+	assert.Equal(t, 200000.0, discount.Amount)
+}
+
+func TestApplyPromoCodeUpdate(t *testing.T) {
+	validFrom := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	newPromo := func() *models.PromoCode {
+		maxUses, maxUsesPerUser := 100, 1
+		return &models.PromoCode{
+			ValidFrom:      validFrom,
+			ValidUntil:     validFrom.AddDate(0, 1, 0),
+			MaxUses:        &maxUses,
+			MaxUsesPerUser: &maxUsesPerUser,
+			Active:         true,
+		}
+	}
+
+	// Limits left out are kept
+	promo := newPromo()
+	active := false
+	require.NoError(t, applyPromoCodeUpdate(promo, &models.UpdatePromoCodeRequest{Active: &active}))
+	assert.False(t, promo.Active)
+	assert.Equal(t, 100, *promo.MaxUses)
+	assert.Equal(t, 1, *promo.MaxUsesPerUser)
+
+	// Cleared limits make the code unlimited again
+	promo = newPromo()
+	require.NoError(t, applyPromoCodeUpdate(promo, &models.UpdatePromoCodeRequest{ClearMaxUses: true}))
+	assert.Nil(t, promo.MaxUses)
+	assert.Equal(t, 1, *promo.MaxUsesPerUser)
+
+	promo = newPromo()
+	require.NoError(t, applyPromoCodeUpdate(promo, &models.UpdatePromoCodeRequest{ClearMaxUsesPerUser: true}))
+	assert.Equal(t, 100, *promo.MaxUses)
+	assert.Nil(t, promo.MaxUsesPerUser)
+
+	// A limit provided along with the flag replaces the cleared one
+	promo = newPromo()
+	maxUses := 50
+	require.NoError(t, applyPromoCodeUpdate(promo, &models.UpdatePromoCodeRequest{ClearMaxUses: true, MaxUses: &maxUses}))
+	assert.Equal(t, 50, *promo.MaxUses)
+
+	promo = newPromo()
+	err := applyPromoCodeUpdate(promo, &models.UpdatePromoCodeRequest{ValidUntil: &validFrom})
+	assert.EqualError(t, err, "valid_until must be after valid_from")
+}
@@ -12,12 +12,34 @@ import (
 )
 
 type ReservationService struct {
-	db *sql.DB
+	db            *sql.DB
+	pricing       *PricingService
+	promos        *PromoCodeService
+	quotes        *PriceQuoteService
+	invoices      *InvoiceService
+	cancellations *CancellationPolicyService
+	costCenters   *CostCenterService
+	budgets       *BudgetService
+	stock         *SnackStockService
+	catering      *CateringService
+
+	maxSnacksPerVisitor int
 }
 
-func NewReservationService(db *sql.DB) *ReservationService {
+func NewReservationService(db *sql.DB, pricing *PricingService, promos *PromoCodeService, quotes *PriceQuoteService, invoices *InvoiceService, cancellations *CancellationPolicyService, costCenters *CostCenterService, budgets *BudgetService, stock *SnackStockService, catering *CateringService, maxSnacksPerVisitor int) *ReservationService {
 	return &ReservationService{
-		db: db,
+		db:            db,
+		pricing:       pricing,
+		promos:        promos,
+		quotes:        quotes,
+		invoices:      invoices,
+		cancellations: cancellations,
+		costCenters:   costCenters,
+		budgets:       budgets,
+		stock:         stock,
+		catering:      catering,
+
+		maxSnacksPerVisitor: maxSnacksPerVisitor,
 	}
 }
 func (s *ReservationService) GetReservationHistory(query *models.ReservationHistoryQuery) (*models.ReservationHistoryResponse, error) {
@@ -68,7 +90,7 @@ func (s *ReservationService) GetReservationHistory(query *models.ReservationHist
 			r.price,
 			r.status,
 			rm.capacity,
-			rm.price_per_hour
+			r.room_price_per_hour
 		FROM reservations r
 		JOIN rooms rm ON r.room_id = rm.id
 		JOIN users u ON r.user_id = u.id
@@ -156,24 +178,28 @@ func (s *ReservationService) UpdateReservationStatus(req *models.UpdateReservati
 	}
 	defer tx.Rollback()
 
-	// Update reservation status
-	result, err := tx.Exec(`
-		UPDATE reservations
-		SET status = $1, updated_at = NOW()
-		WHERE id = $2`,
-		req.Status,
-		req.ReservationID,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("error updating reservation status: %v", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return nil, fmt.Errorf("error getting rows affected: %v", err)
-	}
-	if rowsAffected == 0 {
-		return nil, fmt.Errorf("reservation not found with ID: %v", req.ReservationID)
+	// Update reservation status, cancellations settle their fee and refund like any other
+	if req.Status == models.ReservationStatusCancelled {
+		if _, err := s.cancel(tx, req.ReservationID, uuid.Nil, true); err != nil {
+			return nil, err
+		}
+	} else {
+		var status models.ReservationStatus
+		var approvalStatus *models.ApprovalStatus
+		err = tx.QueryRow(`SELECT status, approval_status FROM reservations WHERE id = $1 FOR UPDATE`,
+			req.ReservationID).Scan(&status, &approvalStatus)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("reservation not found")
+			}
+			return nil, fmt.Errorf("error fetching reservation: %v", err)
+		}
+		if err := checkStatusChange(status, req.Status, approvalStatus); err != nil {
+			return nil, err
+		}
+		if err := s.setStatus(tx, req.ReservationID, req.Status); err != nil {
+			return nil, err
+		}
 	}
 
 	// Fetch updated reservation with all details
@@ -194,7 +220,7 @@ func (s *ReservationService) UpdateReservationStatus(req *models.UpdateReservati
 			r.price,
 			r.status,
 			rm.capacity,
-			rm.price_per_hour
+			r.room_price_per_hour
 		FROM reservations r
 		JOIN rooms rm ON r.room_id = rm.id
 		JOIN users u ON r.user_id = u.id
@@ -236,6 +262,159 @@ func (s *ReservationService) UpdateReservationStatus(req *models.UpdateReservati
 	return &event, nil
 }
 
+// checkStatusChange reports why a reservation in status from cannot be moved to
+// status to. Cancelled reservations have released their stock and settled their
+// refund, so they stay cancelled. Reservations awaiting budget approval cannot be
+// confirmed, just as they cannot be paid.
+func checkStatusChange(from, to models.ReservationStatus, approvalStatus *models.ApprovalStatus) error {
+	if from == models.ReservationStatusCancelled && to != models.ReservationStatusCancelled {
+		return fmt.Errorf("cancelled reservations cannot be reactivated")
+	}
+	if to == models.ReservationStatusConfirmed && approvalStatus != nil && *approvalStatus == models.ApprovalRequired {
+		return fmt.Errorf("reservation is awaiting budget approval")
+	}
+	return nil
+}
+
+// setStatus changes the status of a reservation inside the caller's transaction,
+// issues its invoice once it is confirmed or completed and returns its snack stock once it is cancelled
+func (s *ReservationService) setStatus(tx *sql.Tx, reservationID uuid.UUID, status models.ReservationStatus) error {
+	result, err := tx.Exec(`
+		UPDATE reservations
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2`,
+		status,
+		reservationID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating reservation status: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("reservation not found with ID: %v", reservationID)
+	}
+
+	if invoiceableStatus(string(status)) {
+		if err := s.invoices.GenerateInvoice(tx, reservationID); err != nil {
+			return err
+		}
+	}
+	if status == models.ReservationStatusCancelled {
+		if err := s.stock.Release(tx, reservationID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CancelReservation cancels a reservation on behalf of its owner or an admin,
+// charging the cancellation fee of the room type's policy and refunding the rest of any payment
+func (s *ReservationService) CancelReservation(reservationID, userID uuid.UUID, isAdmin bool) (*models.CancelReservationResponse, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	response, err := s.cancel(tx, reservationID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return response, nil
+}
+
+// cancel cancels a reservation inside the caller's transaction and settles its
+// cancellation fee and refund
+func (s *ReservationService) cancel(tx *sql.Tx, reservationID, userID uuid.UUID, isAdmin bool) (*models.CancelReservationResponse, error) {
+	var ownerID uuid.UUID
+	var status string
+	var price float64
+	var startTime time.Time
+	var roomTypeID *uuid.UUID
+	err := tx.QueryRow(`
+		SELECT r.user_id, r.status, r.price, r.start_time, rm.room_type_id
+		FROM reservations r
+		JOIN rooms rm ON rm.id = r.room_id
+		WHERE r.id = $1
+		FOR UPDATE OF r
+	`, reservationID).Scan(&ownerID, &status, &price, &startTime, &roomTypeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reservation not found")
+		}
+		return nil, fmt.Errorf("error fetching reservation: %v", err)
+	}
+	if !isAdmin && ownerID != userID {
+		return nil, fmt.Errorf("reservation not found")
+	}
+
+	switch models.ReservationStatus(status) {
+	case models.ReservationStatusCancelled:
+		return nil, fmt.Errorf("reservation is already cancelled")
+	case models.ReservationStatusCompleted:
+		return nil, fmt.Errorf("completed reservations cannot be cancelled")
+	}
+
+	if err := s.setStatus(tx, reservationID, models.ReservationStatusCancelled); err != nil {
+		return nil, err
+	}
+
+	return s.cancellations.Settle(tx, reservationID, roomTypeID, price, startTime)
+}
+
+// DecideBudgetApproval approves or rejects a reservation that exceeded its cost center
+// budget. Rejected reservations are cancelled without a cancellation fee.
+func (s *ReservationService) DecideBudgetApproval(reservationID uuid.UUID, approved bool) (*models.ReservationDetailResponse, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var approvalStatus *models.ApprovalStatus
+	err = tx.QueryRow(`SELECT approval_status FROM reservations WHERE id = $1 FOR UPDATE`, reservationID).Scan(&approvalStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reservation not found")
+		}
+		return nil, fmt.Errorf("error fetching reservation: %v", err)
+	}
+	if approvalStatus == nil || *approvalStatus != models.ApprovalRequired {
+		return nil, fmt.Errorf("reservation is not awaiting budget approval")
+	}
+
+	decision := models.ApprovalApproved
+	if !approved {
+		decision = models.ApprovalRejected
+		if err := s.setStatus(tx, reservationID, models.ReservationStatusCancelled); err != nil {
+			return nil, err
+		}
+	}
+	_, err = tx.Exec(`UPDATE reservations SET approval_status = $1, updated_at = NOW() WHERE id = $2`, decision, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("error updating approval status: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return s.GetReservationByID(reservationID)
+}
+
 func (s *ReservationService) CalculateReservationCost(req *models.ReservationCalculationRequest) (*models.ReservationCalculationResponse, error) {
 	// Start transaction
 	tx, err := s.db.Begin()
@@ -251,10 +430,10 @@ func (s *ReservationService) CalculateReservationCost(req *models.ReservationCal
 		PricePerHour float64
 	}
 	err = tx.QueryRow(`
-		SELECT id, name, price_per_hour
+		SELECT id, name
 		FROM rooms
 		WHERE id = $1 AND status = 'active'
-	`, req.RoomID).Scan(&room.ID, &room.Name, &room.PricePerHour)
+	`, req.RoomID).Scan(&room.ID, &room.Name)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("room not found or inactive")
@@ -262,101 +441,42 @@ func (s *ReservationService) CalculateReservationCost(req *models.ReservationCal
 		return nil, fmt.Errorf("error querying room: %v", err)
 	}
 
-	// Calculate room cost
-	duration := req.EndTime.Sub(req.StartTime)
-	hours := duration.Hours()
-	roomCost := room.PricePerHour * hours
-
-	// Get snack details and calculate costs
-	var snackIDs []uuid.UUID
-	for _, snack := range req.Snacks {
-		snackIDs = append(snackIDs, snack.SnackID)
-	}
-
-	rows, err := tx.Query(`
-		SELECT id, name, category, price
-		FROM snacks
-		WHERE id = ANY($1)
-	`, pq.Array(snackIDs))
+	// Rates in effect on the day of the booking
+	room.PricePerHour, err = s.pricing.RoomRate(tx, room.ID, req.StartTime)
 	if err != nil {
-		return nil, fmt.Errorf("error querying snacks: %v", err)
-	}
-	defer rows.Close()
-
-	var snacks []struct {
-		ID       uuid.UUID
-		Name     string
-		Category string
-		Price    float64
-		Quantity int
+		return nil, err
 	}
 
-	for rows.Next() {
-		var snack struct {
-			ID       uuid.UUID
-			Name     string
-			Category string
-			Price    float64
-		}
-		err := rows.Scan(&snack.ID, &snack.Name, &snack.Category, &snack.Price)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning snack: %v", err)
-		}
-
-		// Find quantity for this snack
-		for _, reqSnack := range req.Snacks {
-			if reqSnack.SnackID == snack.ID {
-				snacks = append(snacks, struct {
-					ID       uuid.UUID
-					Name     string
-					Category string
-					Price    float64
-					Quantity int
-				}{
-					ID:       snack.ID,
-					Name:     snack.Name,
-					Category: snack.Category,
-					Price:    snack.Price,
-					Quantity: reqSnack.Quantity,
-				})
-				break
-			}
-		}
+	// Calculate room cost
+	breakdown, err := s.pricing.PriceRoom(tx, room.ID, room.PricePerHour, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating snacks: %v", err)
+	// Validate the snack order
+	snacks, err := loadSnackOrder(tx, req.RoomID, s.pricing.Day(req.StartTime), req.Snacks, maxSnackQuantity(req.VisitorCount, s.maxSnacksPerVisitor))
+	if err != nil {
+		return nil, err
 	}
 
 	// Calculate total cost
 	response := &models.ReservationCalculationResponse{
-		Room: struct {
-			ID           uuid.UUID `json:"id"`
-			Name         string    `json:"name"`
-			PricePerHour float64   `json:"price_per_hour"`
-			TotalHours   float64   `json:"total_hours"`
-			TotalCost    float64   `json:"total_cost"`
-		}{
+		Room: models.RoomCostDetail{
 			ID:           room.ID,
 			Name:         room.Name,
 			PricePerHour: room.PricePerHour,
-			TotalHours:   hours,
-			TotalCost:    roomCost,
+			TotalHours:   breakdown.ActualHours,
+			BilledHours:  breakdown.BilledHours,
+			LineItems:    breakdown.LineItems,
+			TotalCost:    breakdown.Total,
 		},
-		TotalCost: roomCost,
+		TotalCost: breakdown.Total,
 	}
 
 	// Calculate snack costs
 	for _, snack := range snacks {
 		subtotal := snack.Price * float64(snack.Quantity)
-		response.Snacks = append(response.Snacks, struct {
-			ID       uuid.UUID `json:"id"`
-			Name     string    `json:"name"`
-			Category string    `json:"category"`
-			Price    float64   `json:"price"`
-			Quantity int       `json:"quantity"`
-			Subtotal float64   `json:"subtotal"`
-		}{
+		response.Snacks = append(response.Snacks, models.SnackCostDetail{
 			ID:       snack.ID,
 			Name:     snack.Name,
 			Category: snack.Category,
@@ -366,6 +486,29 @@ func (s *ReservationService) CalculateReservationCost(req *models.ReservationCal
 		})
 		response.TotalCost += subtotal
 	}
+	response.Subtotal = response.TotalCost
+
+	// Apply promo code
+	if req.PromoCode != "" {
+		discount, err := s.promos.ApplyPromoCode(tx, req.PromoCode, req.UserID, room.ID, breakdown.Total, response.Subtotal-breakdown.Total, false)
+		if err != nil {
+			return nil, err
+		}
+		response.Discounts = append(response.Discounts, *discount)
+		response.DiscountTotal += discount.Amount
+	}
+
+	// Apply tax on the discounted subtotal
+	taxable := roundMoney(response.Subtotal - response.DiscountTotal)
+	response.TaxLabel, response.TaxRate, response.TaxAmount = s.pricing.Tax(taxable)
+	response.TotalCost = roundMoney(taxable + response.TaxAmount)
+
+	// Persist a quote locking these prices
+	if req.SaveQuote {
+		if err = s.quotes.SaveQuote(tx, req, response); err != nil {
+			return nil, err
+		}
+	}
 
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
@@ -386,21 +529,28 @@ func (s *ReservationService) GetReservationByID(id uuid.UUID) (*models.Reservati
 	// Get reservation details with room and user information
 	var reservation models.ReservationDetailResponse
 	var createdAt, updatedAt time.Time
+	var costCenterID *uuid.UUID
+	var costCenterCode, costCenterName sql.NullString
 
 	err = tx.QueryRow(`
 		SELECT 
 			r.id, r.status, r.start_time, r.end_time, r.visitor_count, r.price, r.created_at, r.updated_at,
-			rm.id, rm.name, rm.capacity, rm.price_per_hour,
-			u.id, u.username
+			r.room_subtotal, r.snack_subtotal, r.discount_total, r.tax_rate, r.tax_amount,
+			rm.id, rm.name, rm.capacity, r.room_price_per_hour,
+			u.id, u.username,
+			cc.id, cc.code, cc.name, r.approval_status
 		FROM reservations r
 		JOIN rooms rm ON r.room_id = rm.id
 		JOIN users u ON r.user_id = u.id
+		LEFT JOIN cost_centers cc ON cc.id = r.cost_center_id
 		WHERE r.id = $1
 	`, id).Scan(
 		&reservation.ID, &reservation.Status, &reservation.StartTime, &reservation.EndTime,
 		&reservation.VisitorCount, &reservation.Price, &createdAt, &updatedAt,
+		&reservation.RoomSubtotal, &reservation.SnackSubtotal, &reservation.DiscountTotal, &reservation.TaxRate, &reservation.TaxAmount,
 		&reservation.Room.ID, &reservation.Room.Name, &reservation.Room.Capacity, &reservation.Room.PricePerHour,
 		&reservation.User.ID, &reservation.User.Username,
+		&costCenterID, &costCenterCode, &costCenterName, &reservation.ApprovalStatus,
 	)
 
 	if err != nil {
@@ -412,13 +562,21 @@ func (s *ReservationService) GetReservationByID(id uuid.UUID) (*models.Reservati
 
 	reservation.CreatedAt = createdAt
 	reservation.UpdatedAt = updatedAt
+	if costCenterID != nil {
+		reservation.CostCenter = &models.CostCenterRef{
+			ID:   *costCenterID,
+			Code: costCenterCode.String,
+			Name: costCenterName.String,
+		}
+	}
 
 	// Get snacks for this reservation
 	rows, err := tx.Query(`
 		SELECT 
-			s.id, s.name, s.category, rs.price, rs.quantity
+			s.id, s.name, c.name, rs.price, rs.quantity, s.active, s.dietary_tags, s.allergens
 		FROM reservation_snacks rs
 		JOIN snacks s ON rs.snack_id = s.id
+		JOIN snack_categories c ON c.id = s.category_id
 		WHERE rs.reservation_id = $1
 	`, id)
 
@@ -428,16 +586,21 @@ func (s *ReservationService) GetReservationByID(id uuid.UUID) (*models.Reservati
 	defer rows.Close()
 
 	var totalSnackCost float64
+	var dietaryItems []dietaryItem
 	for rows.Next() {
 		var snack struct {
-			ID       uuid.UUID
-			Name     string
-			Category string
-			Price    float64
-			Quantity int
+			ID          uuid.UUID
+			Name        string
+			Category    string
+			Price       float64
+			Quantity    int
+			Active      bool
+			DietaryTags []string
+			Allergens   []string
 		}
 
-		err := rows.Scan(&snack.ID, &snack.Name, &snack.Category, &snack.Price, &snack.Quantity)
+		err := rows.Scan(&snack.ID, &snack.Name, &snack.Category, &snack.Price, &snack.Quantity, &snack.Active,
+			pq.Array(&snack.DietaryTags), pq.Array(&snack.Allergens))
 		if err != nil {
 			return nil, fmt.Errorf("error scanning snack: %v", err)
 		}
@@ -446,29 +609,81 @@ func (s *ReservationService) GetReservationByID(id uuid.UUID) (*models.Reservati
 		totalSnackCost += subtotal
 
 		reservation.Snacks = append(reservation.Snacks, struct {
-			ID       uuid.UUID `json:"id"`
-			Name     string    `json:"name"`
-			Category string    `json:"category"`
-			Price    float64   `json:"price"`
-			Quantity int       `json:"quantity"`
-			Subtotal float64   `json:"subtotal"`
+			ID          uuid.UUID `json:"id"`
+			Name        string    `json:"name"`
+			Category    string    `json:"category"`
+			Price       float64   `json:"price"`
+			Quantity    int       `json:"quantity"`
+			Subtotal    float64   `json:"subtotal"`
+			Active      bool      `json:"active"`
+			DietaryTags []string  `json:"dietary_tags"`
+			Allergens   []string  `json:"allergens"`
 		}{
-			ID:       snack.ID,
-			Name:     snack.Name,
-			Category: snack.Category,
-			Price:    snack.Price,
-			Quantity: snack.Quantity,
-			Subtotal: subtotal,
+			ID:          snack.ID,
+			Name:        snack.Name,
+			Category:    snack.Category,
+			Price:       snack.Price,
+			Quantity:    snack.Quantity,
+			Subtotal:    subtotal,
+			Active:      snack.Active,
+			DietaryTags: snack.DietaryTags,
+			Allergens:   snack.Allergens,
+		})
+		dietaryItems = append(dietaryItems, dietaryItem{
+			Name:        snack.Name,
+			Quantity:    snack.Quantity,
+			DietaryTags: snack.DietaryTags,
+			Allergens:   snack.Allergens,
 		})
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating snacks: %v", err)
 	}
+	reservation.DietarySummary = summarizeDiet(dietaryItems)
 
-	// Calculate total cost (room cost + snack cost)
+	// Get discounts applied to this reservation
+	discountRows, err := tx.Query(`
+		SELECT pc.id, pc.code, pc.applies_to, COALESCE(pc.description, ''), pcr.discount_amount
+		FROM promo_code_redemptions pcr
+		JOIN promo_codes pc ON pc.id = pcr.promo_code_id
+		WHERE pcr.reservation_id = $1
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reservation discounts: %v", err)
+	}
+	defer discountRows.Close()
+
+	for discountRows.Next() {
+		var discount models.DiscountLine
+		err := discountRows.Scan(&discount.PromoCodeID, &discount.Code, &discount.AppliesTo, &discount.Description, &discount.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning discount: %v", err)
+		}
+		reservation.Discounts = append(reservation.Discounts, discount)
+	}
+
+	if err = discountRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating discounts: %v", err)
+	}
+
+	// Total cost is the stored grand total (room + snacks - discounts + tax)
 	reservation.TotalCost = reservation.Price
 
+	// Cancellation fees and refunds
+	reservation.Ledger, err = s.cancellations.ledgerEntries(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range reservation.Ledger {
+		switch entry.EntryType {
+		case models.LedgerCancellationFee:
+			reservation.CancellationFee += entry.Amount
+		case models.LedgerRefund:
+			reservation.RefundAmount += entry.Amount
+		}
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("error committing transaction: %v", err)
@@ -508,13 +723,14 @@ func (s *ReservationService) CreateReservation(req *models.CreateReservationRequ
 	defer tx.Rollback()
 
 	// Check room availability
+	var roomName string
 	var roomCapacity int
 	var pricePerHour float64
 	err = tx.QueryRow(`
-		SELECT capacity, price_per_hour
+		SELECT name, capacity
 		FROM rooms
 		WHERE id = $1 AND status = 'active'
-	`, req.RoomID).Scan(&roomCapacity, &pricePerHour)
+	`, req.RoomID).Scan(&roomName, &roomCapacity)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("room not found or inactive")
@@ -547,86 +763,128 @@ func (s *ReservationService) CreateReservation(req *models.CreateReservationRequ
 		return nil, fmt.Errorf("room is already booked for the selected time period")
 	}
 
-	// Calculate room cost
-	bookingDuration := req.EndTime.Sub(req.StartTime)
-	hours := bookingDuration.Hours()
-	roomCost := pricePerHour * hours
+	// Resolve the cost center charged for this booking
+	costCenterID, err := s.costCenters.ResolveForBooking(tx, req.UserID, req.CostCenterID)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get snack details and calculate costs
-	var snackIDs []uuid.UUID
-	for _, snack := range req.Snacks {
-		snackIDs = append(snackIDs, snack.SnackID)
+	// Load the quote whose prices this booking honours
+	var quote *models.ReservationCalculationResponse
+	quotedSnackPrices := make(map[uuid.UUID]float64)
+	if req.QuoteID != nil {
+		quote, err = s.quotes.LoadQuoteForBooking(tx, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, snack := range quote.Snacks {
+			quotedSnackPrices[snack.ID] = snack.Price
+		}
 	}
 
-	rows, err := tx.Query(`
-		SELECT id, name, price
-		FROM snacks
-		WHERE id = ANY($1)
-	`, pq.Array(snackIDs))
-	if err != nil {
-		return nil, fmt.Errorf("error querying snacks: %v", err)
+	// Calculate room cost
+	var breakdown *models.RoomPriceBreakdown
+	if quote != nil {
+		pricePerHour = quote.Room.PricePerHour
+		breakdown = &models.RoomPriceBreakdown{
+			ActualHours: quote.Room.TotalHours,
+			BilledHours: quote.Room.BilledHours,
+			LineItems:   quote.Room.LineItems,
+			Total:       quote.Room.TotalCost,
+		}
+	} else {
+		// Rate in effect on the day of the booking
+		pricePerHour, err = s.pricing.RoomRate(tx, req.RoomID, req.StartTime)
+		if err != nil {
+			return nil, err
+		}
+		breakdown, err = s.pricing.PriceRoom(tx, req.RoomID, pricePerHour, req.StartTime, req.EndTime)
+		if err != nil {
+			return nil, err
+		}
 	}
-	defer rows.Close()
+	roomCost := breakdown.Total
 
-	var snacks []struct {
-		ID       uuid.UUID
-		Name     string
-		Price    float64
-		Quantity int
+	// Validate the snack order and calculate costs
+	snacks, err := loadSnackOrder(tx, req.RoomID, s.pricing.Day(req.StartTime), req.Snacks, maxSnackQuantity(req.VisitorCount, s.maxSnacksPerVisitor))
+	if err != nil {
+		return nil, err
 	}
 	var totalSnackCost float64
-
-	for rows.Next() {
-		var snack struct {
-			ID    uuid.UUID
-			Name  string
-			Price float64
+	for i, snack := range snacks {
+		if price, ok := quotedSnackPrices[snack.ID]; ok {
+			snacks[i].Price = price
 		}
-		err := rows.Scan(&snack.ID, &snack.Name, &snack.Price)
+		totalSnackCost += snacks[i].Price * float64(snack.Quantity)
+	}
+
+	// Snack orders must leave the kitchen enough time before delivery
+	var deliverAt time.Time
+	if len(snacks) > 0 {
+		deliverAt, err = s.catering.DeliveryTime(req.StartTime, req.SnackDeliveryTime)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning snack: %v", err)
+			return nil, err
 		}
+	}
 
-		// Find quantity for this snack
-		for _, reqSnack := range req.Snacks {
-			if reqSnack.SnackID == snack.ID {
-				subtotal := snack.Price * float64(reqSnack.Quantity)
-				totalSnackCost += subtotal
-				snacks = append(snacks, struct {
-					ID       uuid.UUID
-					Name     string
-					Price    float64
-					Quantity int
-				}{
-					ID:       snack.ID,
-					Name:     snack.Name,
-					Price:    snack.Price,
-					Quantity: reqSnack.Quantity,
-				})
-				break
-			}
+	// Apply promo code
+	var discount *models.DiscountLine
+	var discountTotal float64
+	if req.PromoCode != "" {
+		discount, err = s.promos.ApplyPromoCode(tx, req.PromoCode, req.UserID, req.RoomID, roomCost, totalSnackCost, true)
+		if err != nil {
+			return nil, err
 		}
+		if quote != nil {
+			discount.Amount = quote.DiscountTotal
+		}
+		discountTotal = discount.Amount
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating snacks: %v", err)
+	// Calculate total cost with tax on the discounted subtotal
+	taxable := roundMoney(roomCost + totalSnackCost - discountTotal)
+	taxLabel, taxRate, taxAmount := s.pricing.Tax(taxable)
+	if quote != nil {
+		taxLabel, taxRate, taxAmount = quote.TaxLabel, quote.TaxRate, quote.TaxAmount
 	}
+	totalCost := roundMoney(taxable + taxAmount)
 
-	// Calculate total cost
-	totalCost := roomCost + totalSnackCost
+	// Check the booking against the monthly budget of its cost center
+	var approvalStatus *models.ApprovalStatus
+	if costCenterID != nil {
+		approvalRequired, err := s.budgets.CheckBooking(tx, *costCenterID, req.StartTime, totalCost)
+		if err != nil {
+			return nil, err
+		}
+		if approvalRequired {
+			required := models.ApprovalRequired
+			approvalStatus = &required
+		}
+	}
 
 	// Create reservation
 	var reservationID uuid.UUID
 	err = tx.QueryRow(`
 		INSERT INTO reservations (
-			room_id, user_id, start_time, end_time, visitor_count, price, status
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			room_id, user_id, start_time, end_time, visitor_count, price, status,
+			room_price_per_hour, room_subtotal, snack_subtotal, discount_total, tax_rate, tax_amount, cost_center_id, approval_status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		RETURNING id
-	`, req.RoomID, req.UserID, req.StartTime, req.EndTime, req.VisitorCount, totalCost, "pending").Scan(&reservationID)
+	`, req.RoomID, req.UserID, req.StartTime, req.EndTime, req.VisitorCount, totalCost, "pending",
+		pricePerHour, roomCost, totalSnackCost, discountTotal, taxRate, taxAmount, costCenterID, approvalStatus).Scan(&reservationID)
 	if err != nil {
 		return nil, fmt.Errorf("error creating reservation: %v", err)
 	}
 
+	// Take the ordered snacks from stock
+	quantities := make(map[uuid.UUID]int)
+	for _, snack := range snacks {
+		quantities[snack.ID] = snack.Quantity
+	}
+	if err = s.stock.Reserve(tx, reservationID, quantities, req.StartTime); err != nil {
+		return nil, err
+	}
+
 	// Create snack orders
 	for _, snack := range snacks {
 		_, err = tx.Exec(`
@@ -639,6 +897,29 @@ func (s *ReservationService) CreateReservation(req *models.CreateReservationRequ
 		}
 	}
 
+	// Queue the snacks for the kitchen
+	if len(snacks) > 0 {
+		if err = s.catering.CreateOrder(tx, reservationID, deliverAt, req.CateringNotes); err != nil {
+			return nil, err
+		}
+	}
+
+	// Mark the quote as used
+	if quote != nil {
+		if err = s.quotes.MarkQuoteUsed(tx, *req.QuoteID, reservationID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Record promo code usage
+	var discounts []models.DiscountLine
+	if discount != nil {
+		if err = s.promos.RecordRedemption(tx, discount, req.UserID, reservationID); err != nil {
+			return nil, err
+		}
+		discounts = append(discounts, *discount)
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("error committing transaction: %v", err)
@@ -647,7 +928,25 @@ func (s *ReservationService) CreateReservation(req *models.CreateReservationRequ
 	return &models.CreateReservationResponse{
 		ReservationID: reservationID,
 		Status:        "pending",
-		TotalCost:     totalCost,
-		CreatedAt:     time.Now(),
+		Room: models.RoomCostDetail{
+			ID:           req.RoomID,
+			Name:         roomName,
+			PricePerHour: pricePerHour,
+			TotalHours:   breakdown.ActualHours,
+			BilledHours:  breakdown.BilledHours,
+			LineItems:    breakdown.LineItems,
+			TotalCost:    roomCost,
+		},
+		SnackCost:      totalSnackCost,
+		Discounts:      discounts,
+		DiscountTotal:  discountTotal,
+		TaxLabel:       taxLabel,
+		TaxRate:        taxRate,
+		TaxAmount:      taxAmount,
+		TotalCost:      totalCost,
+		QuoteID:        req.QuoteID,
+		CostCenterID:   costCenterID,
+		ApprovalStatus: approvalStatus,
+		CreatedAt:      time.Now(),
 	}, nil
 }
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+
+	"e_meeting/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckStatusChange(t *testing.T) {
+	required := models.ApprovalRequired
+	approved := models.ApprovalApproved
+
+	// Reservations awaiting budget approval cannot be confirmed
+	assert.EqualError(t, checkStatusChange(models.ReservationStatusPending, models.ReservationStatusConfirmed, &required),
+		"reservation is awaiting budget approval")
+	assert.NoError(t, checkStatusChange(models.ReservationStatusPending, models.ReservationStatusConfirmed, &approved))
+	assert.NoError(t, checkStatusChange(models.ReservationStatusPending, models.ReservationStatusConfirmed, nil))
+
+	// Cancelled reservations stay cancelled
+	for _, to := range []models.ReservationStatus{models.ReservationStatusPending, models.ReservationStatusConfirmed, models.ReservationStatusCompleted} {
+		assert.EqualError(t, checkStatusChange(models.ReservationStatusCancelled, to, nil), "cancelled reservations cannot be reactivated")
+	}
+
+	// Other changes do not depend on approval
+	assert.NoError(t, checkStatusChange(models.ReservationStatusConfirmed, models.ReservationStatusCompleted, &required))
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"e_meeting/internal/models"
+	"strings"
+)
+
+// RoleMapping gives the members of groups of an external directory a role.
+// Group names are compared case-insensitively.
+type RoleMapping struct {
+	Admin    []string // Groups whose members are admins
+	Catering []string // Groups whose members are on the catering team
+}
+
+// Enabled reports whether any group is mapped
+func (m RoleMapping) Enabled() bool {
+	return len(m.Admin) > 0 || len(m.Catering) > 0
+}
+
+// Role returns the role of a member of groups, the most privileged one wins
+func (m RoleMapping) Role(groups []string) string {
+	switch {
+	case containsFold(groups, m.Admin):
+		return models.RoleAdmin
+	case containsFold(groups, m.Catering):
+		return models.RoleCatering
+	}
+	return "user"
+}
+
+// containsFold reports whether values and wanted have an element in common
+func containsFold(values, wanted []string) bool {
+	for _, value := range values {
+		for _, w := range wanted {
+			if strings.EqualFold(value, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -3,11 +3,14 @@ package services
 import (
 	"database/sql"
 	"e_meeting/internal/models"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
 )
 
 type RoomService struct {
@@ -27,24 +30,84 @@ func (s *RoomService) CreateRoom(req *models.CreateRoomRequest) (*models.Room, e
 		Capacity:     req.Capacity,
 		PricePerHour: req.PricePerHour,
 		Status:       req.Status,
+		RoomTypeID:   req.RoomTypeID,
+		LocationID:   req.LocationID,
+		Photos:       []models.RoomPhoto{},
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
-	err := s.db.QueryRow(`
-		INSERT INTO rooms (id, name, capacity, price_per_hour, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, name, capacity, price_per_hour, status, created_at, updated_at`,
-		room.ID, room.Name, room.Capacity, room.PricePerHour, room.Status, room.CreatedAt, room.UpdatedAt,
-	).Scan(&room.ID, &room.Name, &room.Capacity, &room.PricePerHour, &room.Status, &room.CreatedAt, &room.UpdatedAt)
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO rooms (id, name, capacity, status, room_type_id, location_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, name, capacity, status, room_type_id, location_id, created_at, updated_at`,
+		room.ID, room.Name, room.Capacity, room.Status, room.RoomTypeID, room.LocationID, room.CreatedAt, room.UpdatedAt,
+	).Scan(&room.ID, &room.Name, &room.Capacity, &room.Status, &room.RoomTypeID, &room.LocationID, &room.CreatedAt, &room.UpdatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("error creating room: %v", err)
 	}
 
+	// The first rate of a room
+	if _, err := recordPrice(tx, roomPrices, room.ID, room.PricePerHour, nil, nil); err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
 	return room, nil
 }
 
+// loadRoomPhotos fills in the photos of each room, oldest first
+func loadRoomPhotos(q queryer, rooms []models.Room) error {
+	if len(rooms) == 0 {
+		return nil
+	}
+
+	byRoom := make(map[uuid.UUID]*models.Room)
+	roomIDs := make([]uuid.UUID, 0, len(rooms))
+	for i := range rooms {
+		rooms[i].Photos = []models.RoomPhoto{}
+		byRoom[rooms[i].ID] = &rooms[i]
+		roomIDs = append(roomIDs, rooms[i].ID)
+	}
+
+	rows, err := q.Query(`
+		SELECT room_id, id, image_url, thumbnail_url, created_at
+		FROM room_photos
+		WHERE room_id = ANY($1)
+		ORDER BY created_at ASC, id ASC
+	`, pq.Array(roomIDs))
+	if err != nil {
+		return fmt.Errorf("error querying room photos: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var roomID uuid.UUID
+		var photo models.RoomPhoto
+		if err := rows.Scan(&roomID, &photo.ID, &photo.URL, &photo.ThumbnailURL, &photo.CreatedAt); err != nil {
+			return fmt.Errorf("error scanning room photo: %v", err)
+		}
+		room := byRoom[roomID]
+		room.Photos = append(room.Photos, photo)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating room photos: %v", err)
+	}
+	return nil
+}
+
 func (s *RoomService) UpdateRoom(id uuid.UUID, req *models.UpdateRoomRequest) (*models.Room, error) {
 	// Start transaction
 	tx, err := s.db.Begin()
@@ -56,10 +119,10 @@ func (s *RoomService) UpdateRoom(id uuid.UUID, req *models.UpdateRoomRequest) (*
 	// First, check if room exists
 	var room models.Room
 	err = tx.QueryRow(`
-		SELECT id, name, capacity, price_per_hour, status, created_at, updated_at
+		SELECT id, name, capacity, `+roomRateAt("rooms.id", "CURRENT_DATE")+`, status, room_type_id, location_id, created_at, updated_at
 		FROM rooms WHERE id = $1`,
 		id,
-	).Scan(&room.ID, &room.Name, &room.Capacity, &room.PricePerHour, &room.Status, &room.CreatedAt, &room.UpdatedAt)
+	).Scan(&room.ID, &room.Name, &room.Capacity, &room.PricePerHour, &room.Status, &room.RoomTypeID, &room.LocationID, &room.CreatedAt, &room.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -75,31 +138,50 @@ func (s *RoomService) UpdateRoom(id uuid.UUID, req *models.UpdateRoomRequest) (*
 	if req.Capacity != nil {
 		room.Capacity = *req.Capacity
 	}
+	priceChanged := req.PricePerHour != nil && *req.PricePerHour != room.PricePerHour
 	if req.PricePerHour != nil {
 		room.PricePerHour = *req.PricePerHour
 	}
 	if req.Status != nil {
 		room.Status = *req.Status
 	}
+	if req.RoomTypeID != nil {
+		room.RoomTypeID = req.RoomTypeID
+	}
+	if req.LocationID != nil {
+		room.LocationID = req.LocationID
+	}
 	room.UpdatedAt = time.Now()
 
 	// Update room
 	_, err = tx.Exec(`
 		UPDATE rooms 
-		SET name = $1, capacity = $2, price_per_hour = $3, status = $4, updated_at = $5
-		WHERE id = $6`,
-		room.Name, room.Capacity, room.PricePerHour, room.Status, room.UpdatedAt, room.ID,
+		SET name = $1, capacity = $2, status = $3, room_type_id = $4, location_id = $5, updated_at = $6
+		WHERE id = $7`,
+		room.Name, room.Capacity, room.Status, room.RoomTypeID, room.LocationID, room.UpdatedAt, room.ID,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error updating room: %v", err)
 	}
 
+	// A new price applies from today; earlier rates stay in the price history
+	if priceChanged {
+		if _, err := recordPrice(tx, roomPrices, room.ID, room.PricePerHour, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	rooms := []models.Room{room}
+	if err := loadRoomPhotos(tx, rooms); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("error committing transaction: %v", err)
 	}
 
-	return &room, nil
+	return &rooms[0], nil
 }
 
 func (s *RoomService) DeleteRoom(id uuid.UUID) error {
@@ -187,6 +269,12 @@ func (s *RoomService) GetRooms(filter *models.RoomFilter, pagination *models.Pag
 			argCount++
 		}
 
+		if filter.LocationID != nil {
+			conditions = append(conditions, fmt.Sprintf("location_id = $%d", argCount))
+			args = append(args, *filter.LocationID)
+			argCount++
+		}
+
 		if filter.MinCapacity != nil {
 			conditions = append(conditions, fmt.Sprintf("capacity >= $%d", argCount))
 			args = append(args, *filter.MinCapacity)
@@ -227,11 +315,12 @@ func (s *RoomService) GetRooms(filter *models.RoomFilter, pagination *models.Pag
 	totalPages := (totalCount + pagination.PageSize - 1) / pagination.PageSize
 	// Get rooms with pagination
 	query := fmt.Sprintf(`
-		SELECT id, name, capacity, price_per_hour, status, created_at, updated_at
+		SELECT id, name, capacity, %s, status, room_type_id, location_id, created_at, updated_at
 		FROM rooms 
 		WHERE %s
 		ORDER BY name ASC
 		LIMIT $%d OFFSET $%d`,
+		roomRateAt("rooms.id", "CURRENT_DATE"),
 		strings.Join(conditions, " AND "),
 		argCount,
 		argCount+1,
@@ -255,6 +344,8 @@ func (s *RoomService) GetRooms(filter *models.RoomFilter, pagination *models.Pag
 			&room.Capacity,
 			&room.PricePerHour,
 			&room.Status,
+			&room.RoomTypeID,
+			&room.LocationID,
 			&room.CreatedAt,
 			&room.UpdatedAt,
 		)
@@ -267,6 +358,11 @@ func (s *RoomService) GetRooms(filter *models.RoomFilter, pagination *models.Pag
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating rooms: %v", err)
 	}
+	rows.Close()
+
+	if err := loadRoomPhotos(tx, rooms); err != nil {
+		return nil, err
+	}
 
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
@@ -350,3 +446,93 @@ func (s *RoomService) GetRoomSchedule(roomID uuid.UUID, query *models.RoomSchedu
 		EndTime:   query.EndDateTime,
 	}, nil
 }
+
+func (s *RoomService) CreateRoomType(req *models.CreateRoomTypeRequest) (*models.RoomType, error) {
+	var roomType models.RoomType
+	err := s.db.QueryRow(`
+		INSERT INTO room_types (name)
+		VALUES ($1)
+		RETURNING id, name, created_at, updated_at`,
+		req.Name,
+	).Scan(&roomType.ID, &roomType.Name, &roomType.CreatedAt, &roomType.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error creating room type: %v", err)
+	}
+
+	return &roomType, nil
+}
+
+func (s *RoomService) GetRoomTypes() (*models.RoomTypeListResponse, error) {
+	rows, err := s.db.Query(`
+		SELECT id, name, created_at, updated_at
+		FROM room_types
+		ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying room types: %v", err)
+	}
+	defer rows.Close()
+
+	var roomTypes []models.RoomType
+	for rows.Next() {
+		var roomType models.RoomType
+		if err := rows.Scan(&roomType.ID, &roomType.Name, &roomType.CreatedAt, &roomType.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning room type: %v", err)
+		}
+		roomTypes = append(roomTypes, roomType)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating room types: %v", err)
+	}
+
+	return &models.RoomTypeListResponse{
+		RoomTypes: roomTypes,
+	}, nil
+}
+
+func (s *RoomService) CreateLocation(req *models.CreateLocationRequest) (*models.Location, error) {
+	var location models.Location
+	err := s.db.QueryRow(`
+		INSERT INTO locations (name)
+		VALUES ($1)
+		RETURNING id, name, created_at, updated_at`,
+		req.Name,
+	).Scan(&location.ID, &location.Name, &location.CreatedAt, &location.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, fmt.Errorf("location already exists")
+		}
+		return nil, fmt.Errorf("error creating location: %v", err)
+	}
+
+	return &location, nil
+}
+
+func (s *RoomService) GetLocations() (*models.LocationListResponse, error) {
+	rows, err := s.db.Query(`
+		SELECT id, name, created_at, updated_at
+		FROM locations
+		ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying locations: %v", err)
+	}
+	defer rows.Close()
+
+	locations := []models.Location{}
+	for rows.Next() {
+		var location models.Location
+		if err := rows.Scan(&location.ID, &location.Name, &location.CreatedAt, &location.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning location: %v", err)
+		}
+		locations = append(locations, location)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating locations: %v", err)
+	}
+
+	return &models.LocationListResponse{
+		Locations: locations,
+	}, nil
+}
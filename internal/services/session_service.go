@@ -0,0 +1,193 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/auth"
+	"e_meeting/internal/models"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// maxUserAgentLength is the size of sessions.user_agent
+const maxUserAgentLength = 512
+
+// SessionService lists the logins of users and signs them out
+type SessionService struct {
+	db         *sql.DB
+	sessions   *auth.SessionCache
+	userStates *auth.UserStateCache
+}
+
+func NewSessionService(db *sql.DB, sessions *auth.SessionCache, userStates *auth.UserStateCache) *SessionService {
+	return &SessionService{
+		db:         db,
+		sessions:   sessions,
+		userStates: userStates,
+	}
+}
+
+// truncateUserAgent shortens a user agent to fit sessions.user_agent without splitting a character
+func truncateUserAgent(userAgent string) string {
+	if utf8.RuneCountInString(userAgent) <= maxUserAgentLength {
+		return userAgent
+	}
+	return strings.ToValidUTF8(string([]rune(userAgent)[:maxUserAgentLength]), "")
+}
+
+// revokeSession signs a session out, its refresh tokens can no longer be exchanged
+func revokeSession(q execer, sessionID uuid.UUID) error {
+	_, err := q.Exec(`UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, sessionID)
+	if err != nil {
+		return fmt.Errorf("error revoking session: %v", err)
+	}
+	_, err = q.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, sessionID)
+	if err != nil {
+		return fmt.Errorf("error revoking refresh tokens: %v", err)
+	}
+	return nil
+}
+
+// revokeUserSessions signs a user out of every session and returns the sessions revoked
+func revokeUserSessions(tx *sql.Tx, userID uuid.UUID) ([]string, error) {
+	rows, err := tx.Query(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error revoking sessions: %v", err)
+	}
+	defer rows.Close()
+
+	var sessionIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning session: %v", err)
+		}
+		sessionIDs = append(sessionIDs, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %v", err)
+	}
+	rows.Close()
+
+	_, err = tx.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error revoking refresh tokens: %v", err)
+	}
+	return sessionIDs, nil
+}
+
+// GetSessions lists the active sessions of a user, most recently used first.
+// currentSessionID marks the session of the request, if any.
+func (s *SessionService) GetSessions(userID uuid.UUID, currentSessionID string) (*models.SessionListResponse, error) {
+	rows, err := s.db.Query(`
+		SELECT id, user_agent, ip_address, created_at, last_used_at, expires_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sessions: %v", err)
+	}
+	defer rows.Close()
+
+	response := &models.SessionListResponse{Sessions: []models.Session{}}
+	for rows.Next() {
+		var session models.Session
+		err := rows.Scan(&session.ID, &session.UserAgent, &session.IPAddress,
+			&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning session: %v", err)
+		}
+		session.Current = session.ID.String() == currentSessionID
+		response.Sessions = append(response.Sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %v", err)
+	}
+	return response, nil
+}
+
+// RevokeSession signs one session of a user out
+func (s *SessionService) RevokeSession(userID, sessionID uuid.UUID) error {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var id uuid.UUID
+	err = tx.QueryRow(`
+		SELECT id FROM sessions
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+		FOR UPDATE
+	`, sessionID, userID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("session not found")
+		}
+		return fmt.Errorf("error fetching session: %v", err)
+	}
+
+	if err := revokeSession(tx, sessionID); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	s.sessions.Invalidate(sessionID.String())
+	return nil
+}
+
+// SignOutUser ends every session of a user and invalidates all of their tokens,
+// including tokens issued before sessions existed. Returns the number of sessions ended.
+func (s *SessionService) SignOutUser(userID uuid.UUID) (int, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE users SET token_version = token_version + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("error updating user: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("user not found")
+	}
+
+	sessionIDs, err := revokeUserSessions(tx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %v", err)
+	}
+	s.userStates.Invalidate(userID.String())
+	s.sessions.Invalidate(sessionIDs...)
+	return len(sessionIDs), nil
+}
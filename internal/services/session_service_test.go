@@ -0,0 +1,24 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateUserAgent(t *testing.T) {
+	ua := "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"
+	assert.Equal(t, ua, truncateUserAgent(ua))
+	assert.Equal(t, "", truncateUserAgent(""))
+
+	long := strings.Repeat("a", maxUserAgentLength+10)
+	assert.Equal(t, long[:maxUserAgentLength], truncateUserAgent(long))
+
+	// Characters are never split
+	wide := strings.Repeat("é", maxUserAgentLength+1)
+	truncated := truncateUserAgent(wide)
+	assert.True(t, utf8.ValidString(truncated))
+	assert.Equal(t, maxUserAgentLength, utf8.RuneCountInString(truncated))
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"e_meeting/internal/models"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SnackOrderError is returned when one or more snack lines of an order are invalid.
+// Nothing is charged or booked; every rejected line is listed.
+type SnackOrderError struct {
+	Lines []models.SnackLineError
+}
+
+func (e *SnackOrderError) Error() string {
+	return fmt.Sprintf("invalid snack order: %d line(s) rejected", len(e.Lines))
+}
+
+// snackOrderLine is a validated snack line with duplicates merged
+type snackOrderLine struct {
+	ID       uuid.UUID
+	Name     string
+	Category string
+	Price    float64
+	Active   bool
+	OnMenu   bool
+	Quantity int
+}
+
+// maxSnackQuantity returns the largest quantity allowed per snack line, 0 for no limit
+func maxSnackQuantity(visitorCount, perVisitor int) int {
+	if visitorCount <= 0 || perVisitor <= 0 {
+		return 0
+	}
+	return visitorCount * perVisitor
+}
+
+// checkSnackOrder merges duplicate lines of an order and checks each snack against the
+// catalogue rows in found. Lines keep the order in which a snack first appears.
+// Lines without a positive quantity are reported on their own and never merged.
+func checkSnackOrder(items []models.SnackOrderItem, found map[uuid.UUID]snackOrderLine, maxQuantity int) ([]snackOrderLine, []models.SnackLineError) {
+	var order []uuid.UUID
+	var lineErrors []models.SnackLineError
+	quantities := make(map[uuid.UUID]int)
+	positions := make(map[uuid.UUID][]int)
+	for i, item := range items {
+		if item.Quantity <= 0 {
+			lineErrors = append(lineErrors, models.SnackLineError{
+				Lines:   []int{i},
+				SnackID: item.SnackID,
+				Code:    models.SnackLineInvalidQuantity,
+				Message: fmt.Sprintf("quantity %d must be at least 1", item.Quantity),
+			})
+			continue
+		}
+		if _, ok := quantities[item.SnackID]; !ok {
+			order = append(order, item.SnackID)
+		}
+		quantities[item.SnackID] += item.Quantity
+		positions[item.SnackID] = append(positions[item.SnackID], i)
+	}
+
+	var lines []snackOrderLine
+	for _, id := range order {
+		snack, ok := found[id]
+		switch {
+		case !ok:
+			lineErrors = append(lineErrors, models.SnackLineError{
+				Lines:   positions[id],
+				SnackID: id,
+				Code:    models.SnackLineUnknown,
+				Message: "snack not found",
+			})
+		case !snack.Active:
+			lineErrors = append(lineErrors, models.SnackLineError{
+				Lines:   positions[id],
+				SnackID: id,
+				Code:    models.SnackLineArchived,
+				Message: fmt.Sprintf("snack %s is no longer available", snack.Name),
+			})
+		case !snack.OnMenu:
+			lineErrors = append(lineErrors, models.SnackLineError{
+				Lines:   positions[id],
+				SnackID: id,
+				Code:    models.SnackLineNotOnMenu,
+				Message: fmt.Sprintf("snack %s is not served at this room's location", snack.Name),
+			})
+		case maxQuantity > 0 && quantities[id] > maxQuantity:
+			lineErrors = append(lineErrors, models.SnackLineError{
+				Lines:   positions[id],
+				SnackID: id,
+				Code:    models.SnackLineQuantityExceeded,
+				Message: fmt.Sprintf("quantity %d of snack %s exceeds the maximum of %d", quantities[id], snack.Name, maxQuantity),
+			})
+		default:
+			snack.Quantity = quantities[id]
+			lines = append(lines, snack)
+		}
+	}
+	return lines, lineErrors
+}
+
+// loadSnackOrder loads the snacks of an order for a room, priced at the rates in effect
+// on day, and validates it, returning a *SnackOrderError listing every rejected line
+func loadSnackOrder(q queryer, roomID uuid.UUID, day string, items []models.SnackOrderItem, maxQuantity int) ([]snackOrderLine, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	snackIDs := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		snackIDs = append(snackIDs, item.SnackID)
+	}
+
+	rows, err := q.Query(`
+		SELECT s.id, s.name, c.name, `+snackRateAt("s.id", "$3::date")+`, s.active, `+onMenuCondition("r.location_id", "s.id")+`
+		FROM snacks s
+		JOIN snack_categories c ON c.id = s.category_id
+		LEFT JOIN rooms r ON r.id = $2
+		WHERE s.id = ANY($1)
+	`, pq.Array(snackIDs), roomID, day)
+	if err != nil {
+		return nil, fmt.Errorf("error querying snacks: %v", err)
+	}
+	defer rows.Close()
+
+	found := make(map[uuid.UUID]snackOrderLine)
+	for rows.Next() {
+		var snack snackOrderLine
+		if err := rows.Scan(&snack.ID, &snack.Name, &snack.Category, &snack.Price, &snack.Active, &snack.OnMenu); err != nil {
+			return nil, fmt.Errorf("error scanning snack: %v", err)
+		}
+		found[snack.ID] = snack
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating snacks: %v", err)
+	}
+
+	lines, lineErrors := checkSnackOrder(items, found, maxQuantity)
+	if len(lineErrors) > 0 {
+		return nil, &SnackOrderError{Lines: lineErrors}
+	}
+	return lines, nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+
+	"e_meeting/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxSnackQuantity(t *testing.T) {
+	assert.Equal(t, 15, maxSnackQuantity(3, 5))
+	assert.Equal(t, 0, maxSnackQuantity(0, 5))
+	assert.Equal(t, 0, maxSnackQuantity(3, 0))
+}
+
+func TestCheckSnackOrder(t *testing.T) {
+	coffee := snackOrderLine{ID: uuid.New(), Name: "Coffee", Price: 10000, Active: true, OnMenu: true}
+	tea := snackOrderLine{ID: uuid.New(), Name: "Tea", Price: 8000, Active: true, OnMenu: true}
+	cake := snackOrderLine{ID: uuid.New(), Name: "Cake", Price: 20000, Active: false, OnMenu: true}
+	soup := snackOrderLine{ID: uuid.New(), Name: "Soup", Price: 15000, Active: true, OnMenu: false}
+	found := map[uuid.UUID]snackOrderLine{coffee.ID: coffee, tea.ID: tea, cake.ID: cake, soup.ID: soup}
+
+	// Duplicate lines are merged in order of first appearance
+	lines, lineErrors := checkSnackOrder([]models.SnackOrderItem{
+		{SnackID: tea.ID, Quantity: 2},
+		{SnackID: coffee.ID, Quantity: 1},
+		{SnackID: tea.ID, Quantity: 3},
+	}, found, 10)
+	assert.Empty(t, lineErrors)
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, tea.ID, lines[0].ID)
+		assert.Equal(t, 5, lines[0].Quantity)
+		assert.Equal(t, coffee.ID, lines[1].ID)
+		assert.Equal(t, 1, lines[1].Quantity)
+	}
+
+	// Every invalid line is reported, with all positions of merged lines
+	unknown := uuid.New()
+	_, lineErrors = checkSnackOrder([]models.SnackOrderItem{
+		{SnackID: unknown, Quantity: 1},
+		{SnackID: cake.ID, Quantity: 1},
+		{SnackID: coffee.ID, Quantity: 6},
+		{SnackID: tea.ID, Quantity: 1},
+		{SnackID: coffee.ID, Quantity: 5},
+		{SnackID: soup.ID, Quantity: 1},
+	}, found, 10)
+	if assert.Len(t, lineErrors, 4) {
+		assert.Equal(t, models.SnackLineUnknown, lineErrors[0].Code)
+		assert.Equal(t, []int{0}, lineErrors[0].Lines)
+		assert.Equal(t, models.SnackLineArchived, lineErrors[1].Code)
+		assert.Equal(t, "snack Cake is no longer available", lineErrors[1].Message)
+		assert.Equal(t, models.SnackLineQuantityExceeded, lineErrors[2].Code)
+		assert.Equal(t, []int{2, 4}, lineErrors[2].Lines)
+		assert.Equal(t, "quantity 11 of snack Coffee exceeds the maximum of 10", lineErrors[2].Message)
+		assert.Equal(t, models.SnackLineNotOnMenu, lineErrors[3].Code)
+		assert.Equal(t, "snack Soup is not served at this room's location", lineErrors[3].Message)
+	}
+
+	// Lines without a positive quantity are reported one by one instead of merged
+	lines, lineErrors = checkSnackOrder([]models.SnackOrderItem{
+		{SnackID: coffee.ID, Quantity: 2},
+		{SnackID: coffee.ID, Quantity: 0},
+		{SnackID: tea.ID, Quantity: -3},
+	}, found, 10)
+	if assert.Len(t, lineErrors, 2) {
+		assert.Equal(t, models.SnackLineInvalidQuantity, lineErrors[0].Code)
+		assert.Equal(t, []int{1}, lineErrors[0].Lines)
+		assert.Equal(t, "quantity 0 must be at least 1", lineErrors[0].Message)
+		assert.Equal(t, []int{2}, lineErrors[1].Lines)
+		assert.Equal(t, tea.ID, lineErrors[1].SnackID)
+	}
+	if assert.Len(t, lines, 1) {
+		assert.Equal(t, 2, lines[0].Quantity)
+	}
+
+	// Without a limit any quantity is accepted
+	lines, lineErrors = checkSnackOrder([]models.SnackOrderItem{{SnackID: coffee.ID, Quantity: 500}}, found, 0)
+	assert.Empty(t, lineErrors)
+	assert.Len(t, lines, 1)
+}
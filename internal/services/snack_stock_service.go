@@ -0,0 +1,401 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/models"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SnackStockService tracks snack stock levels and daily capacity. Stock is taken
+// when a reservation is created and returned when it is cancelled.
+type SnackStockService struct {
+	db *sql.DB
+}
+
+func NewSnackStockService(db *sql.DB) *SnackStockService {
+	return &SnackStockService{
+		db: db,
+	}
+}
+
+// checkAvailability checks an order of quantity items against the tracked stock and the
+// daily capacity of a snack. bookedThatDay is what other reservations already ordered for the day.
+func checkAvailability(name string, stock, dailyCapacity *int, bookedThatDay, quantity int, day time.Time) error {
+	if stock != nil && *stock < quantity {
+		return fmt.Errorf("insufficient stock for snack %s: %d available", name, *stock)
+	}
+	if dailyCapacity != nil && bookedThatDay+quantity > *dailyCapacity {
+		left := *dailyCapacity - bookedThatDay
+		if left < 0 {
+			left = 0
+		}
+		return fmt.Errorf("daily capacity for snack %s on %s is reached: %d left", name, day.Format("2006-01-02"), left)
+	}
+	return nil
+}
+
+// recordMovement logs a stock change of a snack and returns the ID of the movement
+func recordMovement(tx *sql.Tx, snackID uuid.UUID, reservationID *uuid.UUID, movementType models.StockMovementType, quantity, stockAfter int, note string, createdBy *uuid.UUID) (uuid.UUID, error) {
+	var notePtr *string
+	if note != "" {
+		notePtr = &note
+	}
+	var movementID uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO snack_stock_movements (snack_id, reservation_id, movement_type, quantity, stock_after, note, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, snackID, reservationID, movementType, quantity, stockAfter, notePtr, createdBy).Scan(&movementID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("error recording stock movement: %v", err)
+	}
+	return movementID, nil
+}
+
+// Reserve takes the ordered quantities of a reservation starting on start from stock inside
+// the caller's transaction. It must run before the reservation's snack orders are inserted.
+// Snack rows are locked in ID order so concurrent bookings of the same snacks queue up
+// instead of overselling or deadlocking.
+func (s *SnackStockService) Reserve(tx *sql.Tx, reservationID uuid.UUID, quantities map[uuid.UUID]int, start time.Time) error {
+	snackIDs := make([]uuid.UUID, 0, len(quantities))
+	for id := range quantities {
+		snackIDs = append(snackIDs, id)
+	}
+	sort.Slice(snackIDs, func(i, j int) bool { return snackIDs[i].String() < snackIDs[j].String() })
+
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, snackID := range snackIDs {
+		quantity := quantities[snackID]
+
+		var name string
+		var stock, dailyCapacity *int
+		err := tx.QueryRow(`
+			SELECT name, stock_quantity, daily_capacity
+			FROM snacks
+			WHERE id = $1
+			FOR UPDATE
+		`, snackID).Scan(&name, &stock, &dailyCapacity)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("snack not found")
+			}
+			return fmt.Errorf("error locking snack: %v", err)
+		}
+
+		var bookedThatDay int
+		if dailyCapacity != nil {
+			err = tx.QueryRow(`
+				SELECT COALESCE(SUM(rs.quantity), 0)
+				FROM reservation_snacks rs
+				JOIN reservations r ON r.id = rs.reservation_id
+				WHERE rs.snack_id = $1 AND r.status != 'cancelled'
+					AND r.start_time >= $2 AND r.start_time < $3
+			`, snackID, day, day.AddDate(0, 0, 1)).Scan(&bookedThatDay)
+			if err != nil {
+				return fmt.Errorf("error checking daily capacity: %v", err)
+			}
+		}
+
+		if err := checkAvailability(name, stock, dailyCapacity, bookedThatDay, quantity, day); err != nil {
+			return err
+		}
+
+		if stock == nil {
+			continue
+		}
+		var stockAfter int
+		err = tx.QueryRow(`
+			UPDATE snacks SET stock_quantity = stock_quantity - $1, updated_at = NOW()
+			WHERE id = $2
+			RETURNING stock_quantity
+		`, quantity, snackID).Scan(&stockAfter)
+		if err != nil {
+			return fmt.Errorf("error updating stock: %v", err)
+		}
+		if _, err := recordMovement(tx, snackID, &reservationID, models.StockMovementReserve, -quantity, stockAfter, "", nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Release returns the stock still held by a reservation inside the caller's transaction.
+// It is safe to call more than once; only the outstanding quantity is returned.
+func (s *SnackStockService) Release(tx *sql.Tx, reservationID uuid.UUID) error {
+	rows, err := tx.Query(`
+		SELECT snack_id, -SUM(quantity)
+		FROM snack_stock_movements
+		WHERE reservation_id = $1 AND movement_type IN ('reserve', 'release')
+		GROUP BY snack_id
+		HAVING SUM(quantity) < 0
+		ORDER BY snack_id
+	`, reservationID)
+	if err != nil {
+		return fmt.Errorf("error querying reserved stock: %v", err)
+	}
+	defer rows.Close()
+
+	held := make(map[uuid.UUID]int)
+	var snackIDs []uuid.UUID
+	for rows.Next() {
+		var snackID uuid.UUID
+		var quantity int
+		if err := rows.Scan(&snackID, &quantity); err != nil {
+			return fmt.Errorf("error scanning reserved stock: %v", err)
+		}
+		held[snackID] = quantity
+		snackIDs = append(snackIDs, snackID)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating reserved stock: %v", err)
+	}
+	rows.Close()
+
+	for _, snackID := range snackIDs {
+		var stockAfter int
+		err := tx.QueryRow(`
+			UPDATE snacks SET stock_quantity = COALESCE(stock_quantity, 0) + $1, updated_at = NOW()
+			WHERE id = $2
+			RETURNING stock_quantity
+		`, held[snackID], snackID).Scan(&stockAfter)
+		if err != nil {
+			return fmt.Errorf("error updating stock: %v", err)
+		}
+		if _, err := recordMovement(tx, snackID, &reservationID, models.StockMovementRelease, held[snackID], stockAfter, "", nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restock adds delivered items to the stock of a snack
+func (s *SnackStockService) Restock(snackID, userID uuid.UUID, req *models.RestockSnackRequest) (*models.StockMovement, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var stockAfter int
+	err = tx.QueryRow(`
+		UPDATE snacks SET stock_quantity = COALESCE(stock_quantity, 0) + $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING stock_quantity
+	`, req.Quantity, snackID).Scan(&stockAfter)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("snack not found")
+		}
+		return nil, fmt.Errorf("error updating stock: %v", err)
+	}
+	movementID, err := recordMovement(tx, snackID, nil, models.StockMovementRestock, req.Quantity, stockAfter, req.Note, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return s.getMovement(movementID)
+}
+
+// AdjustStock sets the counted stock of a snack and logs the difference
+func (s *SnackStockService) AdjustStock(snackID, userID uuid.UUID, req *models.AdjustStockRequest) (*models.StockMovement, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var current *int
+	err = tx.QueryRow(`SELECT stock_quantity FROM snacks WHERE id = $1 FOR UPDATE`, snackID).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("snack not found")
+		}
+		return nil, fmt.Errorf("error fetching snack: %v", err)
+	}
+
+	_, err = tx.Exec(`UPDATE snacks SET stock_quantity = $1, updated_at = NOW() WHERE id = $2`, req.StockQuantity, snackID)
+	if err != nil {
+		return nil, fmt.Errorf("error updating stock: %v", err)
+	}
+
+	change := req.StockQuantity
+	if current != nil {
+		change -= *current
+	}
+	movementID, err := recordMovement(tx, snackID, nil, models.StockMovementAdjustment, change, req.StockQuantity, req.Note, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return s.getMovement(movementID)
+}
+
+// UpdateStockSettings changes the daily capacity and low stock threshold of a snack
+func (s *SnackStockService) UpdateStockSettings(snackID uuid.UUID, req *models.UpdateStockSettingsRequest) (*models.Snack, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	snack, err := scanSnack(tx.QueryRow(`SELECT `+snackColumns+` FROM snacks WHERE id = $1 FOR UPDATE`, snackID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("snack not found")
+		}
+		return nil, fmt.Errorf("error fetching snack: %v", err)
+	}
+
+	// Update only provided fields
+	if req.DailyCapacity != nil {
+		snack.DailyCapacity = req.DailyCapacity
+		if *req.DailyCapacity == 0 {
+			snack.DailyCapacity = nil
+		}
+	}
+	if req.LowStockThreshold != nil {
+		snack.LowStockThreshold = *req.LowStockThreshold
+	}
+
+	updated, err := scanSnack(tx.QueryRow(`
+		UPDATE snacks
+		SET daily_capacity = $1, low_stock_threshold = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING `+snackColumns,
+		snack.DailyCapacity, snack.LowStockThreshold, snackID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error updating snack: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return updated, nil
+}
+
+// GetLowStock lists active snacks whose tracked stock is at or below their threshold
+func (s *SnackStockService) GetLowStock() (*models.LowStockResponse, error) {
+	rows, err := s.db.Query(`
+		SELECT s.id, s.name, c.name, s.stock_quantity, s.low_stock_threshold
+		FROM snacks s
+		JOIN snack_categories c ON c.id = s.category_id
+		WHERE s.active AND s.stock_quantity IS NOT NULL AND s.stock_quantity <= s.low_stock_threshold
+		ORDER BY s.stock_quantity ASC, s.name ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying low stock: %v", err)
+	}
+	defer rows.Close()
+
+	alerts := []models.LowStockAlert{}
+	for rows.Next() {
+		var alert models.LowStockAlert
+		err := rows.Scan(&alert.SnackID, &alert.Name, &alert.Category, &alert.StockQuantity, &alert.LowStockThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning low stock: %v", err)
+		}
+		alert.OutOfStock = alert.StockQuantity == 0
+		alerts = append(alerts, alert)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating low stock: %v", err)
+	}
+
+	return &models.LowStockResponse{
+		Alerts: alerts,
+	}, nil
+}
+
+const stockMovementColumns = `
+	m.id, m.snack_id, s.name, m.reservation_id, m.movement_type, m.quantity, m.stock_after, m.note, m.created_by, m.created_at`
+
+func scanStockMovement(row interface{ Scan(...interface{}) error }) (*models.StockMovement, error) {
+	var movement models.StockMovement
+	err := row.Scan(
+		&movement.ID, &movement.SnackID, &movement.SnackName, &movement.ReservationID, &movement.Type,
+		&movement.Quantity, &movement.StockAfter, &movement.Note, &movement.CreatedBy, &movement.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &movement, nil
+}
+
+func (s *SnackStockService) getMovement(id uuid.UUID) (*models.StockMovement, error) {
+	movement, err := scanStockMovement(s.db.QueryRow(`
+		SELECT `+stockMovementColumns+`
+		FROM snack_stock_movements m
+		JOIN snacks s ON s.id = m.snack_id
+		WHERE m.id = $1
+	`, id))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching stock movement: %v", err)
+	}
+	return movement, nil
+}
+
+// GetStockMovements lists the most recent stock movements of a snack, newest first
+func (s *SnackStockService) GetStockMovements(snackID uuid.UUID, query *models.StockMovementQuery) (*models.StockMovementListResponse, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = 100
+	}
+
+	var exists bool
+	if err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM snacks WHERE id = $1)`, snackID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("error fetching snack: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("snack not found")
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+stockMovementColumns+`
+		FROM snack_stock_movements m
+		JOIN snacks s ON s.id = m.snack_id
+		WHERE m.snack_id = $1
+		ORDER BY m.created_at DESC
+		LIMIT $2
+	`, snackID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying stock movements: %v", err)
+	}
+	defer rows.Close()
+
+	movements := []models.StockMovement{}
+	for rows.Next() {
+		movement, err := scanStockMovement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning stock movement: %v", err)
+		}
+		movements = append(movements, *movement)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stock movements: %v", err)
+	}
+
+	return &models.StockMovementListResponse{
+		Movements: movements,
+	}, nil
+}
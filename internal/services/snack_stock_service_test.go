@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAvailability(t *testing.T) {
+	day := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	intPtr := func(v int) *int { return &v }
+
+	// Untracked stock and no daily limit always fit
+	assert.NoError(t, checkAvailability("Coffee", nil, nil, 500, 100, day))
+
+	// Stock can be used up exactly
+	assert.NoError(t, checkAvailability("Coffee", intPtr(10), nil, 0, 10, day))
+	err := checkAvailability("Coffee", intPtr(9), nil, 0, 10, day)
+	assert.EqualError(t, err, "insufficient stock for snack Coffee: 9 available")
+
+	// Daily capacity counts what was already booked for the day
+	assert.NoError(t, checkAvailability("Coffee", nil, intPtr(50), 40, 10, day))
+	err = checkAvailability("Coffee", intPtr(100), intPtr(50), 45, 10, day)
+	assert.EqualError(t, err, "daily capacity for snack Coffee on 2026-05-04 is reached: 5 left")
+
+	// A capacity lowered below what is booked reports nothing left
+	err = checkAvailability("Coffee", nil, intPtr(20), 30, 1, day)
+	assert.EqualError(t, err, "daily capacity for snack Coffee on 2026-05-04 is reached: 0 left")
+}
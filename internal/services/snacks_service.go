@@ -3,10 +3,15 @@ package services
 import (
 	"database/sql"
 	"e_meeting/internal/models"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
 )
 
 type SnackService struct {
@@ -19,7 +24,174 @@ func NewSnackService(db *sql.DB) *SnackService {
 	}
 }
 
-func (s *SnackService) GetSnacks() (*models.SnackListResponse, error) {
+var snackColumns = `
+	snacks.id, snacks.name, snacks.category_id,
+	(SELECT sc.name FROM snack_categories sc WHERE sc.id = snacks.category_id),
+	` + snackRateAt("snacks.id", "CURRENT_DATE") + `, snacks.active, snacks.archived_at, snacks.stock_quantity, snacks.daily_capacity,
+	snacks.low_stock_threshold, snacks.dietary_tags, snacks.allergens, snacks.image_url, snacks.thumbnail_url,
+	snacks.created_at, snacks.updated_at`
+
+// onMenuCondition is true when the snack with ID snackExpr is served at the location
+// locationExpr. Locations without a menu, and rooms without a location, serve every snack.
+func onMenuCondition(locationExpr, snackExpr string) string {
+	return fmt.Sprintf(`(%[1]s IS NULL
+		OR NOT EXISTS (SELECT 1 FROM location_snacks ls WHERE ls.location_id = %[1]s)
+		OR EXISTS (SELECT 1 FROM location_snacks ls WHERE ls.location_id = %[1]s AND ls.snack_id = %[2]s))`,
+		locationExpr, snackExpr)
+}
+
+func scanSnack(row interface{ Scan(...interface{}) error }) (*models.Snack, error) {
+	var snack models.Snack
+	err := row.Scan(
+		&snack.ID,
+		&snack.Name,
+		&snack.CategoryID,
+		&snack.Category,
+		&snack.Price,
+		&snack.Active,
+		&snack.ArchivedAt,
+		&snack.StockQuantity,
+		&snack.DailyCapacity,
+		&snack.LowStockThreshold,
+		pq.Array(&snack.DietaryTags),
+		pq.Array(&snack.Allergens),
+		&snack.ImageURL,
+		&snack.ThumbnailURL,
+		&snack.CreatedAt,
+		&snack.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &snack, nil
+}
+
+func validDietaryTag(tag string) bool {
+	switch tag {
+	case models.DietaryVegetarian, models.DietaryVegan, models.DietaryHalal, models.DietaryGlutenFree:
+		return true
+	}
+	return false
+}
+
+// normalizeDietaryTags removes duplicate tags and tags vegan snacks as vegetarian too
+func normalizeDietaryTags(tags []string) []string {
+	set := make(map[string]bool)
+	for _, tag := range tags {
+		set[tag] = true
+	}
+	if set[models.DietaryVegan] {
+		set[models.DietaryVegetarian] = true
+	}
+	return sortedKeys(set)
+}
+
+// normalizeAllergens lower-cases allergens and removes blanks and duplicates
+func normalizeAllergens(allergens []string) []string {
+	set := make(map[string]bool)
+	for _, allergen := range allergens {
+		allergen = strings.ToLower(strings.TrimSpace(allergen))
+		if allergen != "" {
+			set[allergen] = true
+		}
+	}
+	return sortedKeys(set)
+}
+
+// splitList splits a comma separated query value, dropping blank items
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// dietaryItem is an ordered snack line as seen by the dietary summary
+type dietaryItem struct {
+	Name        string
+	Quantity    int
+	DietaryTags []string
+	Allergens   []string
+}
+
+// summarizeDiet counts the ordered items per dietary tag and groups them per allergen
+func summarizeDiet(items []dietaryItem) models.DietarySummary {
+	summary := models.DietarySummary{
+		Tags: map[string]int{
+			models.DietaryVegetarian: 0,
+			models.DietaryVegan:      0,
+			models.DietaryHalal:      0,
+			models.DietaryGlutenFree: 0,
+		},
+		Allergens: []models.AllergenSummary{},
+	}
+
+	allergens := make(map[string]*models.AllergenSummary)
+	for _, item := range items {
+		summary.TotalItems += item.Quantity
+		for _, tag := range item.DietaryTags {
+			summary.Tags[tag] += item.Quantity
+		}
+		for _, allergen := range item.Allergens {
+			entry, ok := allergens[allergen]
+			if !ok {
+				entry = &models.AllergenSummary{Allergen: allergen}
+				allergens[allergen] = entry
+			}
+			entry.Items += item.Quantity
+			entry.Snacks = append(entry.Snacks, item.Name)
+		}
+	}
+
+	names := make(map[string]bool)
+	for allergen := range allergens {
+		names[allergen] = true
+	}
+	for _, allergen := range sortedKeys(names) {
+		summary.Allergens = append(summary.Allergens, *allergens[allergen])
+	}
+	return summary
+}
+
+// GetSnacks lists the snack catalogue filtered by category, dietary tags and allergens.
+// With a room ID only the snacks served at the room's location are listed.
+// Archived snacks are only included when requested.
+func (s *SnackService) GetSnacks(query *models.SnackListQuery) (*models.SnackListResponse, error) {
+	dietary := splitList(query.Dietary)
+	for _, tag := range dietary {
+		if !validDietaryTag(tag) {
+			return nil, fmt.Errorf("invalid dietary tag %s", tag)
+		}
+	}
+
+	var categoryID, roomID *uuid.UUID
+	if query.CategoryID != "" {
+		id, err := uuid.Parse(query.CategoryID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid category ID")
+		}
+		categoryID = &id
+	}
+	if query.RoomID != "" {
+		id, err := uuid.Parse(query.RoomID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid room ID")
+		}
+		roomID = &id
+	}
+
 	// Start transaction
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -27,12 +199,30 @@ func (s *SnackService) GetSnacks() (*models.SnackListResponse, error) {
 	}
 	defer tx.Rollback()
 
+	// Resolve the location whose menu applies
+	var locationID *uuid.UUID
+	if roomID != nil {
+		err = tx.QueryRow(`SELECT location_id FROM rooms WHERE id = $1`, *roomID).Scan(&locationID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("room not found")
+			}
+			return nil, fmt.Errorf("error fetching room: %v", err)
+		}
+	}
+
 	// Query all snacks
 	rows, err := tx.Query(`
-		SELECT id, name, category, price, created_at, updated_at
+		SELECT `+snackColumns+`
 		FROM snacks
-		ORDER BY category, name
-	`)
+		JOIN snack_categories c ON c.id = snacks.category_id
+		WHERE (snacks.active OR $1)
+			AND ($2::uuid IS NULL OR snacks.category_id = $2)
+			AND snacks.dietary_tags @> $3
+			AND NOT snacks.allergens && $4
+			AND `+onMenuCondition("$5::uuid", "snacks.id")+`
+		ORDER BY c.sort_order, c.name, snacks.name
+	`, query.IncludeArchived, categoryID, pq.Array(dietary), pq.Array(normalizeAllergens(splitList(query.ExcludeAllergens))), locationID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying snacks: %v", err)
 	}
@@ -40,19 +230,11 @@ func (s *SnackService) GetSnacks() (*models.SnackListResponse, error) {
 
 	var snacks []models.Snack
 	for rows.Next() {
-		var snack models.Snack
-		err := rows.Scan(
-			&snack.ID,
-			&snack.Name,
-			&snack.Category,
-			&snack.Price,
-			&snack.CreatedAt,
-			&snack.UpdatedAt,
-		)
+		snack, err := scanSnack(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning snack: %v", err)
 		}
-		snacks = append(snacks, snack)
+		snacks = append(snacks, *snack)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -85,26 +267,459 @@ func (s *SnackService) CreateSnack(req *models.CreateSnackRequest) (*models.Crea
 	snackID := uuid.New()
 	createdAt := time.Now()
 
+	dietaryTags := normalizeDietaryTags(req.DietaryTags)
+	allergens := normalizeAllergens(req.Allergens)
+
 	// Insert new snack
-	_, err = tx.Exec(`
-		INSERT INTO snacks (id, name, category, price, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $5)
-	`, snackID, req.Name, req.Category, req.Price, createdAt)
+	var category string
+	err = tx.QueryRow(`
+		INSERT INTO snacks (id, name, category_id, dietary_tags, allergens, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING (SELECT name FROM snack_categories WHERE id = $3)
+	`, snackID, req.Name, req.CategoryID, pq.Array(dietaryTags), pq.Array(allergens), createdAt).Scan(&category)
 
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, fmt.Errorf("snack category not found")
+		}
 		return nil, fmt.Errorf("error creating snack: %v", err)
 	}
 
+	// The first price of a snack
+	if _, err := recordPrice(tx, snackPrices, snackID, req.Price, nil, nil); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("error committing transaction: %v", err)
 	}
 
 	return &models.CreateSnackResponse{
-		ID:        snackID,
-		Name:      req.Name,
-		Category:  req.Category,
-		Price:     req.Price,
-		CreatedAt: createdAt,
+		ID:          snackID,
+		Name:        req.Name,
+		CategoryID:  req.CategoryID,
+		Category:    category,
+		Price:       req.Price,
+		DietaryTags: dietaryTags,
+		Allergens:   allergens,
+		CreatedAt:   createdAt,
+	}, nil
+}
+
+func (s *SnackService) UpdateSnack(id uuid.UUID, req *models.UpdateSnackRequest) (*models.Snack, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	snack, err := scanSnack(tx.QueryRow(`SELECT `+snackColumns+` FROM snacks WHERE snacks.id = $1 FOR UPDATE`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("snack not found")
+		}
+		return nil, fmt.Errorf("error fetching snack: %v", err)
+	}
+
+	// Update only provided fields
+	if req.Name != nil {
+		snack.Name = *req.Name
+	}
+	if req.CategoryID != nil {
+		snack.CategoryID = *req.CategoryID
+	}
+	priceChanged := req.Price != nil && *req.Price != snack.Price
+	if req.Price != nil {
+		snack.Price = *req.Price
+	}
+	if req.Active != nil {
+		snack.Active = *req.Active
+	}
+	if req.DietaryTags != nil {
+		snack.DietaryTags = *req.DietaryTags
+	}
+	if req.Allergens != nil {
+		snack.Allergens = *req.Allergens
+	}
+
+	// A new price applies from today; earlier prices stay in the price history
+	if priceChanged {
+		if _, err := recordPrice(tx, snackPrices, id, snack.Price, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// archived_at records when the snack was archived and is cleared when it is restored
+	updated, err := scanSnack(tx.QueryRow(`
+		UPDATE snacks
+		SET name = $1, category_id = $2, active = $3,
+			archived_at = CASE WHEN $3 THEN NULL ELSE COALESCE(archived_at, NOW()) END,
+			dietary_tags = $4, allergens = $5,
+			updated_at = NOW()
+		WHERE id = $6
+		RETURNING `+snackColumns,
+		snack.Name, snack.CategoryID, snack.Active,
+		pq.Array(normalizeDietaryTags(snack.DietaryTags)), pq.Array(normalizeAllergens(snack.Allergens)), id,
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, fmt.Errorf("snack category not found")
+		}
+		return nil, fmt.Errorf("error updating snack: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return updated, nil
+}
+
+// ArchiveSnack hides a snack from the catalogue and stops it from being ordered.
+// Reservations that already include it are unaffected.
+func (s *SnackService) ArchiveSnack(id uuid.UUID) (*models.Snack, error) {
+	active := false
+	return s.UpdateSnack(id, &models.UpdateSnackRequest{Active: &active})
+}
+
+// DeleteSnack removes a snack that was never ordered. Ordered snacks must be
+// archived instead so historical reservations keep their snack details.
+func (s *SnackService) DeleteSnack(id uuid.UUID) error {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var ordered bool
+	err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM reservation_snacks WHERE snack_id = $1)`, id).Scan(&ordered)
+	if err != nil {
+		return fmt.Errorf("error checking snack orders: %v", err)
+	}
+	if ordered {
+		return fmt.Errorf("snack has been ordered and can only be archived")
+	}
+
+	result, err := tx.Exec(`DELETE FROM snacks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting snack: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("snack not found")
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return nil
+}
+
+// GetSnackCategories lists the snack categories in menu order with the number of snacks in each
+func (s *SnackService) GetSnackCategories() (*models.SnackCategoryListResponse, error) {
+	rows, err := s.db.Query(`
+		SELECT c.id, c.name, c.sort_order, COUNT(s.id), c.created_at, c.updated_at
+		FROM snack_categories c
+		LEFT JOIN snacks s ON s.category_id = c.id
+		GROUP BY c.id
+		ORDER BY c.sort_order, c.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying snack categories: %v", err)
+	}
+	defer rows.Close()
+
+	categories := []models.SnackCategory{}
+	for rows.Next() {
+		var category models.SnackCategory
+		err := rows.Scan(&category.ID, &category.Name, &category.SortOrder, &category.SnackCount,
+			&category.CreatedAt, &category.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning snack category: %v", err)
+		}
+		categories = append(categories, category)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating snack categories: %v", err)
+	}
+
+	return &models.SnackCategoryListResponse{
+		Categories: categories,
 	}, nil
 }
+
+func (s *SnackService) CreateSnackCategory(req *models.CreateSnackCategoryRequest) (*models.SnackCategory, error) {
+	category := models.SnackCategory{
+		ID:        uuid.New(),
+		Name:      strings.TrimSpace(req.Name),
+		SortOrder: req.SortOrder,
+	}
+	if category.Name == "" {
+		return nil, fmt.Errorf("snack category name is required")
+	}
+
+	err := s.db.QueryRow(`
+		INSERT INTO snack_categories (id, name, sort_order)
+		VALUES ($1, $2, $3)
+		RETURNING created_at, updated_at
+	`, category.ID, category.Name, category.SortOrder).Scan(&category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, fmt.Errorf("snack category already exists")
+		}
+		return nil, fmt.Errorf("error creating snack category: %v", err)
+	}
+
+	return &category, nil
+}
+
+func (s *SnackService) UpdateSnackCategory(id uuid.UUID, req *models.UpdateSnackCategoryRequest) (*models.SnackCategory, error) {
+	var name *string
+	if req.Name != nil {
+		trimmed := strings.TrimSpace(*req.Name)
+		if trimmed == "" {
+			return nil, fmt.Errorf("snack category name is required")
+		}
+		name = &trimmed
+	}
+
+	var category models.SnackCategory
+	err := s.db.QueryRow(`
+		UPDATE snack_categories
+		SET name = COALESCE($1, name), sort_order = COALESCE($2, sort_order), updated_at = NOW()
+		WHERE id = $3
+		RETURNING id, name, sort_order, (SELECT COUNT(*) FROM snacks WHERE category_id = $3), created_at, updated_at
+	`, name, req.SortOrder, id).Scan(&category.ID, &category.Name, &category.SortOrder, &category.SnackCount,
+		&category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("snack category not found")
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, fmt.Errorf("snack category already exists")
+		}
+		return nil, fmt.Errorf("error updating snack category: %v", err)
+	}
+
+	return &category, nil
+}
+
+// DeleteSnackCategory removes an empty category. Categories that still hold
+// snacks must be merged into another category instead.
+func (s *SnackService) DeleteSnackCategory(id uuid.UUID) error {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var hasSnacks bool
+	err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM snacks WHERE category_id = $1)`, id).Scan(&hasSnacks)
+	if err != nil {
+		return fmt.Errorf("error checking category snacks: %v", err)
+	}
+	if hasSnacks {
+		return fmt.Errorf("snack category has snacks")
+	}
+
+	result, err := tx.Exec(`DELETE FROM snack_categories WHERE id = $1`, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("snack category has snacks")
+		}
+		return fmt.Errorf("error deleting snack category: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("snack category not found")
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return nil
+}
+
+// MergeSnackCategory moves every snack of a category into the target category
+// and deletes the merged category
+func (s *SnackService) MergeSnackCategory(id uuid.UUID, targetID uuid.UUID) (*models.SnackCategory, error) {
+	if id == targetID {
+		return nil, fmt.Errorf("cannot merge a snack category into itself")
+	}
+
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Lock both categories so neither is renamed or deleted while snacks move
+	var found int
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM (
+			SELECT id FROM snack_categories WHERE id IN ($1, $2) ORDER BY id FOR UPDATE
+		) locked
+	`, id, targetID).Scan(&found)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching snack categories: %v", err)
+	}
+	if found != 2 {
+		return nil, fmt.Errorf("snack category not found")
+	}
+
+	_, err = tx.Exec(`UPDATE snacks SET category_id = $1, updated_at = NOW() WHERE category_id = $2`, targetID, id)
+	if err != nil {
+		return nil, fmt.Errorf("error moving snacks: %v", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM snack_categories WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting snack category: %v", err)
+	}
+
+	var category models.SnackCategory
+	err = tx.QueryRow(`
+		SELECT id, name, sort_order, (SELECT COUNT(*) FROM snacks WHERE category_id = $1), created_at, updated_at
+		FROM snack_categories
+		WHERE id = $1
+	`, targetID).Scan(&category.ID, &category.Name, &category.SortOrder, &category.SnackCount,
+		&category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching snack category: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return &category, nil
+}
+
+func getLocationMenu(q queryer, locationID uuid.UUID) (*models.LocationMenu, error) {
+	var exists bool
+	err := q.QueryRow(`SELECT EXISTS (SELECT 1 FROM locations WHERE id = $1)`, locationID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching location: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("location not found")
+	}
+
+	rows, err := q.Query(`
+		SELECT `+snackColumns+`
+		FROM location_snacks ls
+		JOIN snacks ON snacks.id = ls.snack_id
+		JOIN snack_categories c ON c.id = snacks.category_id
+		WHERE ls.location_id = $1
+		ORDER BY c.sort_order, c.name, snacks.name
+	`, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying location menu: %v", err)
+	}
+	defer rows.Close()
+
+	menu := &models.LocationMenu{
+		LocationID: locationID,
+		Snacks:     []models.Snack{},
+	}
+	for rows.Next() {
+		snack, err := scanSnack(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning snack: %v", err)
+		}
+		menu.Snacks = append(menu.Snacks, *snack)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating location menu: %v", err)
+	}
+	menu.Restricted = len(menu.Snacks) > 0
+
+	return menu, nil
+}
+
+// GetLocationMenu lists the snacks served at a location
+func (s *SnackService) GetLocationMenu(locationID uuid.UUID) (*models.LocationMenu, error) {
+	return getLocationMenu(s.db, locationID)
+}
+
+// SetLocationMenu replaces the snacks served at a location
+func (s *SnackService) SetLocationMenu(locationID uuid.UUID, snackIDs []uuid.UUID) (*models.LocationMenu, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the location so concurrent menu updates apply one after the other
+	err = tx.QueryRow(`SELECT id FROM locations WHERE id = $1 FOR UPDATE`, locationID).Scan(&locationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("location not found")
+		}
+		return nil, fmt.Errorf("error fetching location: %v", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM location_snacks WHERE location_id = $1`, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("error clearing location menu: %v", err)
+	}
+
+	if len(snackIDs) > 0 {
+		result, err := tx.Exec(`
+			INSERT INTO location_snacks (location_id, snack_id)
+			SELECT $1, id FROM snacks WHERE id = ANY($2)
+		`, locationID, pq.Array(snackIDs))
+		if err != nil {
+			return nil, fmt.Errorf("error setting location menu: %v", err)
+		}
+
+		unique := make(map[uuid.UUID]bool)
+		for _, id := range snackIDs {
+			unique[id] = true
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("error getting rows affected: %v", err)
+		}
+		if int(rowsAffected) != len(unique) {
+			return nil, fmt.Errorf("snack not found")
+		}
+	}
+
+	menu, err := getLocationMenu(tx, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return menu, nil
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+
+	"e_meeting/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeDietaryTags(t *testing.T) {
+	assert.Equal(t, []string{"halal", "vegan", "vegetarian"}, normalizeDietaryTags([]string{"vegan", "halal", "vegan"}))
+	assert.Equal(t, []string{}, normalizeDietaryTags(nil))
+}
+
+func TestNormalizeAllergens(t *testing.T) {
+	assert.Equal(t, []string{"milk", "peanuts"}, normalizeAllergens([]string{" Peanuts", "milk", "MILK", " "}))
+	assert.Equal(t, []string{"gluten"}, normalizeAllergens(splitList("gluten,,")))
+}
+
+func TestSummarizeDiet(t *testing.T) {
+	summary := summarizeDiet([]dietaryItem{
+		{Name: "Fruit Platter", Quantity: 4, DietaryTags: []string{"halal", "vegan", "vegetarian"}},
+		{Name: "Cheese Cake", Quantity: 3, DietaryTags: []string{"vegetarian"}, Allergens: []string{"gluten", "milk"}},
+		{Name: "Croissant", Quantity: 2, Allergens: []string{"gluten"}},
+	})
+
+	assert.Equal(t, 9, summary.TotalItems)
+	assert.Equal(t, map[string]int{"vegetarian": 7, "vegan": 4, "halal": 4, "gluten_free": 0}, summary.Tags)
+	assert.Equal(t, []models.AllergenSummary{
+		{Allergen: "gluten", Items: 5, Snacks: []string{"Cheese Cake", "Croissant"}},
+		{Allergen: "milk", Items: 3, Snacks: []string{"Cheese Cake"}},
+	}, summary.Allergens)
+
+	// Reservations without snacks still report every tag
+	empty := summarizeDiet(nil)
+	assert.Equal(t, 0, empty.TotalItems)
+	assert.Len(t, empty.Tags, 4)
+	assert.Empty(t, empty.Allergens)
+}
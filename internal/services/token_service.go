@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"e_meeting/internal/auth"
+	"e_meeting/internal/models"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// errRefreshTokenReused is returned when a refresh token that was already exchanged comes back
+var errRefreshTokenReused = errors.New("refresh token reuse detected")
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// TokenService issues short-lived access tokens and rotating refresh tokens.
+// Every login starts a session whose ID is also its refresh token family; each
+// refresh uses up the token presented and issues the next one of the family.
+type TokenService struct {
+	db         *sql.DB
+	jwtConfig  *auth.JWTConfig
+	denylist   *auth.Denylist
+	sessions   *auth.SessionCache
+	refreshTTL time.Duration
+}
+
+func NewTokenService(db *sql.DB, jwtConfig *auth.JWTConfig, denylist *auth.Denylist, sessions *auth.SessionCache, refreshTTL time.Duration) *TokenService {
+	return &TokenService{
+		db:         db,
+		jwtConfig:  jwtConfig,
+		denylist:   denylist,
+		sessions:   sessions,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// newRefreshToken returns a random refresh token and the hash stored for it
+func newRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("error generating refresh token: %v", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// refreshTokenState is a stored refresh token with the account it belongs to
+type refreshTokenState struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	FamilyID      uuid.UUID
+	ExpiresAt     time.Time
+	Used          bool
+	Revoked       bool
+	Username      string
+	Role          string
+	TokenVersion  int
+	AccountActive bool
+}
+
+// checkRefreshToken decides whether a refresh token may be exchanged at now
+func checkRefreshToken(token refreshTokenState, now time.Time) error {
+	switch {
+	case token.Revoked:
+		return fmt.Errorf("refresh token has been revoked")
+	case token.Used:
+		return errRefreshTokenReused
+	case !now.Before(token.ExpiresAt):
+		return fmt.Errorf("refresh token expired")
+	case !token.AccountActive:
+		return fmt.Errorf("account is deactivated")
+	}
+	return nil
+}
+
+// issue signs an access token and stores the next refresh token of a session,
+// valid until expiresAt
+func (s *TokenService) issue(q execer, userID uuid.UUID, username, role string, tokenVersion int, sessionID uuid.UUID, expiresAt time.Time) (*models.TokenResponse, error) {
+	accessToken, err := s.jwtConfig.GenerateToken(userID.String(), username, role, tokenVersion, sessionID.String())
+	if err != nil {
+		return nil, fmt.Errorf("error generating access token: %v", err)
+	}
+
+	refreshToken, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = q.Exec(`
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, userID, sessionID, hash, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("error storing refresh token: %v", err)
+	}
+
+	// Expired tokens can never be exchanged, drop those of this user
+	_, err = q.Exec(`DELETE FROM refresh_tokens WHERE user_id = $1 AND expires_at < NOW()`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error removing expired refresh tokens: %v", err)
+	}
+
+	return &models.TokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.jwtConfig.TokenDuration.Seconds()),
+	}, nil
+}
+
+// IssueTokens starts a new session for a user that just signed in
+func (s *TokenService) IssueTokens(user *models.User, client models.ClientInfo) (*models.TokenResponse, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	expiresAt := time.Now().Add(s.refreshTTL)
+	var sessionID uuid.UUID
+	err = tx.QueryRow(`
+		INSERT INTO sessions (user_id, user_agent, ip_address, expires_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4)
+		RETURNING id
+	`, user.ID, truncateUserAgent(client.UserAgent), client.IPAddress, expiresAt).Scan(&sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("error creating session: %v", err)
+	}
+
+	response, err := s.issue(tx, user.ID, user.Username, user.Role, user.TokenVersion, sessionID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return response, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. Presenting a token that
+// was already exchanged revokes its whole session, since one of the two parties
+// holding it is not the user.
+func (s *TokenService) Refresh(refreshToken string, client models.ClientInfo) (*models.TokenResponse, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var token refreshTokenState
+	err = tx.QueryRow(`
+		SELECT rt.id, rt.user_id, rt.family_id, rt.expires_at, rt.used_at IS NOT NULL, rt.revoked_at IS NOT NULL,
+			u.username, u.role, u.token_version, u.status AND u.deleted_at IS NULL
+		FROM refresh_tokens rt
+		JOIN users u ON u.id = rt.user_id
+		WHERE rt.token_hash = $1
+		FOR UPDATE OF rt
+	`, hashRefreshToken(refreshToken)).Scan(
+		&token.ID, &token.UserID, &token.FamilyID, &token.ExpiresAt, &token.Used, &token.Revoked,
+		&token.Username, &token.Role, &token.TokenVersion, &token.AccountActive,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid refresh token")
+		}
+		return nil, fmt.Errorf("error fetching refresh token: %v", err)
+	}
+
+	if err := checkRefreshToken(token, time.Now()); err != nil {
+		if err == errRefreshTokenReused {
+			if revokeErr := revokeSession(tx, token.FamilyID); revokeErr != nil {
+				return nil, revokeErr
+			}
+			if commitErr := tx.Commit(); commitErr != nil {
+				return nil, fmt.Errorf("error committing transaction: %v", commitErr)
+			}
+			s.sessions.Invalidate(token.FamilyID.String())
+		}
+		return nil, err
+	}
+
+	_, err = tx.Exec(`UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1`, token.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error using refresh token: %v", err)
+	}
+
+	expiresAt := time.Now().Add(s.refreshTTL)
+	_, err = tx.Exec(`
+		UPDATE sessions
+		SET last_used_at = NOW(), expires_at = $2, ip_address = COALESCE(NULLIF($3, ''), ip_address)
+		WHERE id = $1
+	`, token.FamilyID, expiresAt, client.IPAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error updating session: %v", err)
+	}
+
+	response, err := s.issue(tx, token.UserID, token.Username, token.Role, token.TokenVersion, token.FamilyID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return response, nil
+}
+
+// Logout signs out the session of a login of userID, given by its ID or by one
+// of its refresh tokens, and revokes the access token with ID jti until it expires
+func (s *TokenService) Logout(ctx context.Context, userID uuid.UUID, sessionID, refreshToken, jti string, accessExpiresAt time.Time) error {
+	var session uuid.UUID
+	err := s.db.QueryRow(`
+		SELECT id FROM sessions
+		WHERE user_id = $1 AND (id::text = $2 OR id = (
+			SELECT family_id FROM refresh_tokens WHERE token_hash = $3
+		))
+	`, userID, sessionID, hashRefreshToken(refreshToken)).Scan(&session)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error fetching session: %v", err)
+	}
+	if err == nil {
+		if err := revokeSession(s.db, session); err != nil {
+			return err
+		}
+		s.sessions.Invalidate(session.String())
+	}
+
+	if jti != "" {
+		if err := s.denylist.Revoke(ctx, jti, accessExpiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRefreshToken(t *testing.T) {
+	token, hash, err := newRefreshToken()
+	require.NoError(t, err)
+	assert.Len(t, token, 43)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hashRefreshToken(token), hash)
+	assert.NotEqual(t, token, hash)
+
+	other, _, err := newRefreshToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestCheckRefreshToken(t *testing.T) {
+	now := time.Date(2026, 5, 4, 8, 0, 0, 0, time.UTC)
+	valid := refreshTokenState{ExpiresAt: now.Add(time.Hour), AccountActive: true}
+	assert.NoError(t, checkRefreshToken(valid, now))
+
+	// A token that was already exchanged is reported as reused
+	used := valid
+	used.Used = true
+	assert.Equal(t, errRefreshTokenReused, checkRefreshToken(used, now))
+
+	// Once its family is revoked a reused token is just revoked
+	used.Revoked = true
+	assert.EqualError(t, checkRefreshToken(used, now), "refresh token has been revoked")
+
+	expired := valid
+	expired.ExpiresAt = now
+	assert.EqualError(t, checkRefreshToken(expired, now), "refresh token expired")
+
+	inactive := valid
+	inactive.AccountActive = false
+	assert.EqualError(t, checkRefreshToken(inactive, now), "account is deactivated")
+}
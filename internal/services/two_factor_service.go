@@ -0,0 +1,643 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"e_meeting/internal/auth"
+	"e_meeting/internal/models"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// recoveryCodeCount is the number of recovery codes issued at a time
+	recoveryCodeCount = 10
+	// maxCodeAttempts is the number of codes an account may try before it has to wait
+	maxCodeAttempts = 5
+	// codeAttemptWindow is how long after the last try the codes tried are forgotten
+	codeAttemptWindow = 15 * time.Minute
+)
+
+// recoveryCodeEncoding spells recovery codes in lowercase letters and digits 2-7
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// TwoFactorService enrols TOTP authenticators, checks the second factor of logins
+// and manages the roles that must use one
+type TwoFactorService struct {
+	db           *sql.DB
+	jwtConfig    *auth.JWTConfig
+	tokens       TokenIssuer
+	denylist     *auth.Denylist
+	issuer       string
+	challengeTTL time.Duration
+}
+
+func NewTwoFactorService(db *sql.DB, jwtConfig *auth.JWTConfig, tokens TokenIssuer, denylist *auth.Denylist, issuer string, challengeTTL time.Duration) *TwoFactorService {
+	return &TwoFactorService{
+		db:           db,
+		jwtConfig:    jwtConfig,
+		tokens:       tokens,
+		denylist:     denylist,
+		issuer:       issuer,
+		challengeTTL: challengeTTL,
+	}
+}
+
+// nextCodeAttempt returns the number of the code about to be tried by an account
+// that tried attempts codes, the last one at attemptedAt
+func nextCodeAttempt(attempts int, attemptedAt *time.Time, now time.Time) int {
+	if attemptedAt == nil || now.Sub(*attemptedAt) >= codeAttemptWindow {
+		return 1
+	}
+	return attempts + 1
+}
+
+// challengePurpose decides what a login must go through after the password was checked.
+// An empty purpose means the login is complete.
+func challengePurpose(enabled, required bool) string {
+	switch {
+	case enabled:
+		return auth.ChallengeTwoFactor
+	case required:
+		return auth.ChallengeTwoFactorSetup
+	}
+	return ""
+}
+
+// newRecoveryCodes returns a fresh set of recovery codes formatted as xxxxx-xxxxx
+func newRecoveryCodes() ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("error generating recovery code: %v", err)
+		}
+		code := recoveryCodeEncoding.EncodeToString(buf)[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode accepts recovery codes typed with or without separators and in any case
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeRecoveryCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// twoFactorAccount is an account locked for a change of its second factor
+type twoFactorAccount struct {
+	ID           uuid.UUID
+	Username     string
+	Role         string
+	TokenVersion int
+	Active       bool
+	Secret       *string
+	Enabled      bool
+	LastStep     *int64
+	Attempts     int        // Codes tried since the last accepted one
+	AttemptedAt  *time.Time // When the last code was tried
+}
+
+func lockTwoFactorAccount(tx *sql.Tx, userID uuid.UUID) (*twoFactorAccount, error) {
+	var account twoFactorAccount
+	err := tx.QueryRow(`
+		SELECT id, username, role, token_version, status AND deleted_at IS NULL,
+			totp_secret, two_factor_enabled, totp_last_step, two_factor_attempts, two_factor_attempted_at
+		FROM users
+		WHERE id = $1
+		FOR UPDATE
+	`, userID).Scan(
+		&account.ID, &account.Username, &account.Role, &account.TokenVersion, &account.Active,
+		&account.Secret, &account.Enabled, &account.LastStep, &account.Attempts, &account.AttemptedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+	return &account, nil
+}
+
+// verifyCode checks a code of the account's authenticator, or one of its unused
+// recovery codes when allowRecovery is set. Accepted codes cannot be used again.
+func verifyCode(tx *sql.Tx, account *twoFactorAccount, code string, allowRecovery bool) (bool, error) {
+	if account.Secret != nil {
+		lastStep := int64(-1)
+		if account.LastStep != nil {
+			lastStep = *account.LastStep
+		}
+		if step, ok := auth.VerifyTOTP(*account.Secret, strings.TrimSpace(code), time.Now(), lastStep); ok {
+			_, err := tx.Exec(`UPDATE users SET totp_last_step = $2 WHERE id = $1`, account.ID, step)
+			if err != nil {
+				return false, fmt.Errorf("error updating user: %v", err)
+			}
+			return true, nil
+		}
+	}
+	if !allowRecovery {
+		return false, nil
+	}
+
+	result, err := tx.Exec(`
+		UPDATE user_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, account.ID, hashRecoveryCode(code))
+	if err != nil {
+		return false, fmt.Errorf("error using recovery code: %v", err)
+	}
+	used, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error using recovery code: %v", err)
+	}
+	return used == 1, nil
+}
+
+// checkCode verifies a code for a locked account within its attempt limit. The
+// attempt is counted before the code is checked; as the account row stays locked
+// until tx ends, concurrent requests cannot try more codes than the limit. A
+// refused code commits tx so the attempt sticks, the caller's changes are only
+// made once the code is accepted.
+func checkCode(tx *sql.Tx, account *twoFactorAccount, code string, allowRecovery bool) error {
+	now := time.Now()
+	attempt := nextCodeAttempt(account.Attempts, account.AttemptedAt, now)
+	_, err := tx.Exec(`
+		UPDATE users SET two_factor_attempts = $2, two_factor_attempted_at = $3 WHERE id = $1
+	`, account.ID, attempt, now)
+	if err != nil {
+		return fmt.Errorf("error counting two-factor attempt: %v", err)
+	}
+
+	ok := false
+	if attempt <= maxCodeAttempts {
+		if ok, err = verifyCode(tx, account, code, allowRecovery); err != nil {
+			return err
+		}
+	}
+	if !ok {
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error committing transaction: %v", err)
+		}
+		if attempt > maxCodeAttempts {
+			return fmt.Errorf("too many attempts, try again later")
+		}
+		return fmt.Errorf("invalid two-factor code")
+	}
+
+	_, err = tx.Exec(`
+		UPDATE users SET two_factor_attempts = 0, two_factor_attempted_at = NULL WHERE id = $1
+	`, account.ID)
+	if err != nil {
+		return fmt.Errorf("error resetting two-factor attempts: %v", err)
+	}
+	return nil
+}
+
+// replaceRecoveryCodes stores a new set of recovery codes, the previous ones stop working
+func replaceRecoveryCodes(tx *sql.Tx, userID uuid.UUID) ([]string, error) {
+	codes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error removing recovery codes: %v", err)
+	}
+	for _, code := range codes {
+		_, err = tx.Exec(`
+			INSERT INTO user_recovery_codes (user_id, code_hash)
+			VALUES ($1, $2)
+		`, userID, hashRecoveryCode(code))
+		if err != nil {
+			return nil, fmt.Errorf("error storing recovery code: %v", err)
+		}
+	}
+	return codes, nil
+}
+
+// policyRequired reports whether members of a role must use a second factor
+func policyRequired(q queryer, role string) (bool, error) {
+	var required bool
+	err := q.QueryRow(`SELECT required FROM two_factor_policies WHERE role = $1`, role).Scan(&required)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error fetching two-factor policy: %v", err)
+	}
+	return required, nil
+}
+
+// Challenge returns the challenge a user whose password was checked must complete
+// before getting tokens, or nil when the password is enough
+func (s *TwoFactorService) Challenge(user *models.User) (*models.LoginResponse, error) {
+	required := false
+	if !user.TwoFactorEnabled {
+		var err error
+		required, err = policyRequired(s.db, user.Role)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	purpose := challengePurpose(user.TwoFactorEnabled, required)
+	if purpose == "" {
+		return nil, nil
+	}
+
+	token, err := s.jwtConfig.GenerateChallengeToken(user.ID.String(), purpose, s.challengeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("error generating challenge token: %v", err)
+	}
+	return &models.LoginResponse{
+		UserID:                 user.ID.String(),
+		TwoFactorRequired:      true,
+		TwoFactorSetupRequired: purpose == auth.ChallengeTwoFactorSetup,
+		ChallengeToken:         token,
+	}, nil
+}
+
+// challenge checks a challenge token that has not been completed yet
+func (s *TwoFactorService) challenge(ctx context.Context, challengeToken string) (*auth.Challenge, uuid.UUID, error) {
+	challenge, err := s.jwtConfig.ValidateChallengeToken(challengeToken)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	userID, err := uuid.Parse(challenge.UserID)
+	if err != nil {
+		return nil, uuid.Nil, auth.ErrInvalidChallenge
+	}
+	used, err := s.denylist.IsRevoked(ctx, challenge.ID)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	if used {
+		return nil, uuid.Nil, auth.ErrInvalidChallenge
+	}
+	return challenge, userID, nil
+}
+
+// SetupChallenge starts the enrolment a login challenge asked for
+func (s *TwoFactorService) SetupChallenge(ctx context.Context, challengeToken string) (*models.TwoFactorSetupResponse, error) {
+	challenge, userID, err := s.challenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Purpose != auth.ChallengeTwoFactorSetup {
+		return nil, auth.ErrInvalidChallenge
+	}
+	return s.Setup(userID)
+}
+
+// Setup generates a new authenticator secret for an account. It only takes
+// effect once a code of it is confirmed.
+func (s *TwoFactorService) Setup(userID uuid.UUID) (*models.TwoFactorSetupResponse, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	account, err := lockTwoFactorAccount(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.Active {
+		return nil, fmt.Errorf("account is deactivated")
+	}
+	if account.Enabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(`
+		UPDATE users SET totp_secret = $2, totp_last_step = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, userID, secret)
+	if err != nil {
+		return nil, fmt.Errorf("error updating user: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(s.issuer, account.Username, secret),
+	}, nil
+}
+
+// VerifyLogin completes a login challenge with a code and issues the tokens of
+// the login. Completing an enrolment challenge enables 2FA and also returns the
+// recovery codes.
+func (s *TwoFactorService) VerifyLogin(ctx context.Context, challengeToken, code string, client models.ClientInfo) (*models.LoginResponse, error) {
+	challenge, userID, err := s.challenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	account, err := lockTwoFactorAccount(tx, userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, auth.ErrInvalidChallenge
+		}
+		return nil, err
+	}
+	if !account.Active {
+		return nil, fmt.Errorf("account is deactivated")
+	}
+
+	var recoveryCodes []string
+	switch challenge.Purpose {
+	case auth.ChallengeTwoFactor:
+		if !account.Enabled {
+			return nil, auth.ErrInvalidChallenge
+		}
+		err = checkCode(tx, account, code, true)
+	case auth.ChallengeTwoFactorSetup:
+		if account.Enabled {
+			err = checkCode(tx, account, code, true)
+			break
+		}
+		if account.Secret == nil {
+			return nil, fmt.Errorf("two-factor setup has not been started")
+		}
+		err = checkCode(tx, account, code, false)
+		if err == nil {
+			recoveryCodes, err = s.enable(tx, userID)
+		}
+	default:
+		return nil, auth.ErrInvalidChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	// A challenge completes a single login
+	if err := s.denylist.Revoke(ctx, challenge.ID, challenge.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.tokens.IssueTokens(&models.User{
+		ID:           account.ID,
+		Username:     account.Username,
+		Role:         account.Role,
+		TokenVersion: account.TokenVersion,
+	}, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		UserID:        account.ID.String(),
+		Token:         tokens.Token,
+		RefreshToken:  tokens.RefreshToken,
+		ExpiresIn:     tokens.ExpiresIn,
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// enable turns on 2FA for an account with a confirmed secret and returns its recovery codes
+func (s *TwoFactorService) enable(tx *sql.Tx, userID uuid.UUID) ([]string, error) {
+	_, err := tx.Exec(`UPDATE users SET two_factor_enabled = TRUE, updated_at = NOW() WHERE id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error updating user: %v", err)
+	}
+	return replaceRecoveryCodes(tx, userID)
+}
+
+// Enable confirms the pending secret of an account with one of its codes
+func (s *TwoFactorService) Enable(userID uuid.UUID, code string) (*models.RecoveryCodesResponse, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	account, err := lockTwoFactorAccount(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if account.Enabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+	if account.Secret == nil {
+		return nil, fmt.Errorf("two-factor setup has not been started")
+	}
+
+	if err := checkCode(tx, account, code, false); err != nil {
+		return nil, err
+	}
+	codes, err := s.enable(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns off 2FA for an account, confirmed with a code or a recovery code.
+// Members of a role that requires 2FA cannot turn it off.
+func (s *TwoFactorService) Disable(userID uuid.UUID, code string) error {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	account, err := lockTwoFactorAccount(tx, userID)
+	if err != nil {
+		return err
+	}
+	if !account.Enabled {
+		return fmt.Errorf("two-factor authentication is not enabled")
+	}
+	required, err := policyRequired(tx, account.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return fmt.Errorf("two-factor authentication is required for your role")
+	}
+
+	if err := checkCode(tx, account, code, true); err != nil {
+		return err
+	}
+	if err := clearTwoFactor(tx, userID); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	return nil
+}
+
+// clearTwoFactor removes the authenticator and recovery codes of an account
+func clearTwoFactor(tx *sql.Tx, userID uuid.UUID) error {
+	_, err := tx.Exec(`
+		UPDATE users
+		SET two_factor_enabled = FALSE, totp_secret = NULL, totp_last_step = NULL,
+			two_factor_attempts = 0, two_factor_attempted_at = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("error updating user: %v", err)
+	}
+	_, err = tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("error removing recovery codes: %v", err)
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of an account, confirmed
+// with a code of its authenticator
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uuid.UUID, code string) (*models.RecoveryCodesResponse, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	account, err := lockTwoFactorAccount(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.Enabled {
+		return nil, fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	if err := checkCode(tx, account, code, false); err != nil {
+		return nil, err
+	}
+	codes, err := replaceRecoveryCodes(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// ResetTwoFactor removes the second factor of a user that lost it, for admins.
+// If the role requires 2FA, the next login enrols a new authenticator.
+func (s *TwoFactorService) ResetTwoFactor(userID uuid.UUID) error {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := lockTwoFactorAccount(tx, userID); err != nil {
+		return err
+	}
+	if err := clearTwoFactor(tx, userID); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	return nil
+}
+
+// GetPolicies returns the 2FA policy of every role
+func (s *TwoFactorService) GetPolicies() (*models.TwoFactorPolicyListResponse, error) {
+	rows, err := s.db.Query(`SELECT role, required, updated_at FROM two_factor_policies`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying two-factor policies: %v", err)
+	}
+	defer rows.Close()
+
+	stored := map[string]models.TwoFactorPolicy{}
+	for rows.Next() {
+		var policy models.TwoFactorPolicy
+		if err := rows.Scan(&policy.Role, &policy.Required, &policy.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning two-factor policy: %v", err)
+		}
+		stored[policy.Role] = policy
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating two-factor policies: %v", err)
+	}
+
+	response := &models.TwoFactorPolicyListResponse{Policies: []models.TwoFactorPolicy{}}
+	for _, role := range []string{"user", models.RoleAdmin, models.RoleCatering} {
+		policy, ok := stored[role]
+		if !ok {
+			policy = models.TwoFactorPolicy{Role: role}
+		}
+		response.Policies = append(response.Policies, policy)
+	}
+	return response, nil
+}
+
+// SetPolicy sets whether members of a role must use 2FA. Members without an
+// authenticator enrol one at their next login.
+func (s *TwoFactorService) SetPolicy(role string, required bool, adminID uuid.UUID) (*models.TwoFactorPolicy, error) {
+	switch role {
+	case "user", models.RoleAdmin, models.RoleCatering:
+	default:
+		return nil, fmt.Errorf("invalid role, expected user, admin or catering")
+	}
+
+	var policy models.TwoFactorPolicy
+	err := s.db.QueryRow(`
+		INSERT INTO two_factor_policies (role, required, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (role) DO UPDATE
+		SET required = EXCLUDED.required, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING role, required, updated_at
+	`, role, required, adminID).Scan(&policy.Role, &policy.Required, &policy.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error saving two-factor policy: %v", err)
+	}
+	return &policy, nil
+}
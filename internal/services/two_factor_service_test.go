@@ -0,0 +1,51 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"e_meeting/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengePurpose(t *testing.T) {
+	assert.Equal(t, auth.ChallengeTwoFactor, challengePurpose(true, false))
+	assert.Equal(t, auth.ChallengeTwoFactor, challengePurpose(true, true))
+	assert.Equal(t, auth.ChallengeTwoFactorSetup, challengePurpose(false, true))
+	assert.Equal(t, "", challengePurpose(false, false))
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, err := newRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Regexp(t, regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`), code)
+		assert.False(t, seen[code], "duplicate recovery code %s", code)
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	// Codes match however they are typed
+	hash := hashRecoveryCode("abcde-fgh23")
+	assert.Equal(t, hash, hashRecoveryCode("ABCDE FGH23"))
+	assert.Equal(t, hash, hashRecoveryCode("abcdefgh23"))
+	assert.NotEqual(t, hash, hashRecoveryCode("abcde-fgh24"))
+}
+
+func TestNextCodeAttempt(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-time.Minute)
+	old := now.Add(-codeAttemptWindow)
+
+	assert.Equal(t, 1, nextCodeAttempt(0, nil, now))
+	assert.Equal(t, maxCodeAttempts+1, nextCodeAttempt(maxCodeAttempts, &recent, now))
+	// Attempts are forgotten once the account stopped trying for a while
+	assert.Equal(t, 1, nextCodeAttempt(maxCodeAttempts, &old, now))
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"database/sql"
+	"e_meeting/internal/auth"
+	"e_meeting/internal/models"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// UserManagementService lets admins list, promote, deactivate and remove user accounts
+// while always keeping at least one active admin
+type UserManagementService struct {
+	db         *sql.DB
+	userStates *auth.UserStateCache
+}
+
+func NewUserManagementService(db *sql.DB, userStates *auth.UserStateCache) *UserManagementService {
+	return &UserManagementService{
+		db:         db,
+		userStates: userStates,
+	}
+}
+
+// userState is what the last admin rule and token revocation need to know about an account
+type userState struct {
+	Role    string
+	Status  bool
+	Deleted bool
+}
+
+// isActive reports whether an account can currently sign in
+func (u userState) isActive() bool {
+	return u.Status && !u.Deleted
+}
+
+// isActiveAdmin reports whether an account can currently sign in as an admin
+func (u userState) isActiveAdmin() bool {
+	return u.Role == models.RoleAdmin && u.isActive()
+}
+
+// revokesTokens reports whether a change must invalidate the tokens issued so far,
+// which carry the old role and were issued to an account that could sign in
+func revokesTokens(before, after userState) bool {
+	return before.Role != after.Role || (before.isActive() && !after.isActive())
+}
+
+// checkLastAdmin refuses a change that would leave no active admin.
+// activeAdmins is the number of active admins before the change.
+func checkLastAdmin(before, after userState, activeAdmins int) error {
+	if before.isActiveAdmin() && !after.isActiveAdmin() && activeAdmins <= 1 {
+		return fmt.Errorf("cannot remove the last active admin")
+	}
+	return nil
+}
+
+// lockActiveAdmins locks the active admins and returns their number, before any
+// account is locked so role changes cannot both remove "another" admin
+func lockActiveAdmins(tx *sql.Tx) (int, error) {
+	var activeAdmins int
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM (
+			SELECT id FROM users
+			WHERE role = 'admin' AND status AND deleted_at IS NULL
+			FOR UPDATE
+		) admins
+	`).Scan(&activeAdmins)
+	if err != nil {
+		return 0, fmt.Errorf("error counting admins: %v", err)
+	}
+	return activeAdmins, nil
+}
+
+const adminUserColumns = `
+	id, username, email, role, status, two_factor_enabled, language, prof_pic, default_cost_center_id,
+	created_at, updated_at, deleted_at`
+
+func scanAdminUser(row interface{ Scan(...interface{}) error }) (*models.AdminUser, error) {
+	var user models.AdminUser
+	err := row.Scan(
+		&user.ID, &user.Username, &user.Email, &user.Role, &user.Status, &user.TwoFactorEnabled, &user.Language, &user.ProfPic,
+		&user.DefaultCostCenterID, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUsers searches user accounts by username or email, newest first
+func (s *UserManagementService) GetUsers(query *models.AdminUserQuery) (*models.AdminUserListResponse, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = 10
+	}
+	if query.PageSize > 100 {
+		query.PageSize = 100
+	}
+
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	argCount := 1
+
+	if !query.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if query.Search != "" {
+		conditions = append(conditions, fmt.Sprintf("(username ILIKE $%d OR email ILIKE $%d)", argCount, argCount))
+		args = append(args, "%"+query.Search+"%")
+		argCount++
+	}
+	switch query.Role {
+	case "":
+	case "user", models.RoleAdmin, models.RoleCatering:
+		conditions = append(conditions, fmt.Sprintf("role = $%d", argCount))
+		args = append(args, query.Role)
+		argCount++
+	default:
+		return nil, fmt.Errorf("invalid role, expected user, admin or catering")
+	}
+	switch query.Status {
+	case "":
+	case "active", "inactive":
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argCount))
+		args = append(args, query.Status == "active")
+		argCount++
+	default:
+		return nil, fmt.Errorf("invalid status, expected active or inactive")
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var totalCount int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM users WHERE `+where, args...).Scan(&totalCount)
+	if err != nil {
+		return nil, fmt.Errorf("error getting total count: %v", err)
+	}
+
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT %s
+		FROM users
+		WHERE %s
+		ORDER BY created_at DESC, username ASC
+		LIMIT $%d OFFSET $%d`,
+		adminUserColumns, where, argCount, argCount+1,
+	), append(args, query.PageSize, (query.Page-1)*query.PageSize)...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying users: %v", err)
+	}
+	defer rows.Close()
+
+	users := []models.AdminUser{}
+	for rows.Next() {
+		user, err := scanAdminUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning user: %v", err)
+		}
+		users = append(users, *user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %v", err)
+	}
+
+	return &models.AdminUserListResponse{
+		Users:      users,
+		TotalCount: totalCount,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: (totalCount + query.PageSize - 1) / query.PageSize,
+	}, nil
+}
+
+// GetUser returns an account, including a soft deleted one
+func (s *UserManagementService) GetUser(userID uuid.UUID) (*models.AdminUser, error) {
+	user, err := scanAdminUser(s.db.QueryRow(`SELECT `+adminUserColumns+` FROM users WHERE id = $1`, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+	return user, nil
+}
+
+// UpdateRole changes the role of an account
+func (s *UserManagementService) UpdateRole(userID, actorID uuid.UUID, role string) (*models.AdminUser, error) {
+	return s.updateUser(userID, actorID, func(state *userState) (string, []interface{}) {
+		state.Role = role
+		return "role = $2", []interface{}{role}
+	})
+}
+
+// UpdateStatus activates or deactivates an account
+func (s *UserManagementService) UpdateStatus(userID, actorID uuid.UUID, active bool) (*models.AdminUser, error) {
+	return s.updateUser(userID, actorID, func(state *userState) (string, []interface{}) {
+		state.Status = active
+		return "status = $2", []interface{}{active}
+	})
+}
+
+// DeleteUser soft deletes an account. Its reservations and history are kept.
+func (s *UserManagementService) DeleteUser(userID, actorID uuid.UUID) error {
+	_, err := s.updateUser(userID, actorID, func(state *userState) (string, []interface{}) {
+		if state.Deleted {
+			return "", nil
+		}
+		state.Deleted = true
+		return "deleted_at = NOW()", nil
+	})
+	return err
+}
+
+// RestoreUser brings back a soft deleted account
+func (s *UserManagementService) RestoreUser(userID, actorID uuid.UUID) (*models.AdminUser, error) {
+	return s.updateUser(userID, actorID, func(state *userState) (string, []interface{}) {
+		state.Deleted = false
+		return "deleted_at = NULL", nil
+	})
+}
+
+// updateUser applies a change to one account under the last admin rule.
+// change updates the state in place and returns the SET clause to apply,
+// whose arguments start at $2; an empty clause leaves the account untouched.
+// Admins may not take away their own access.
+func (s *UserManagementService) updateUser(userID, actorID uuid.UUID, change func(*userState) (string, []interface{})) (*models.AdminUser, error) {
+	// Start transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	activeAdmins, err := lockActiveAdmins(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var before userState
+	err = tx.QueryRow(`
+		SELECT role, status, deleted_at IS NOT NULL
+		FROM users
+		WHERE id = $1
+		FOR UPDATE
+	`, userID).Scan(&before.Role, &before.Status, &before.Deleted)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+
+	after := before
+	set, args := change(&after)
+	if set != "" {
+		if userID == actorID && before.isActiveAdmin() && !after.isActiveAdmin() {
+			return nil, fmt.Errorf("admins cannot remove their own access")
+		}
+		if err := checkLastAdmin(before, after, activeAdmins); err != nil {
+			return nil, err
+		}
+
+		if revokesTokens(before, after) {
+			set += ", token_version = token_version + 1"
+		}
+		_, err = tx.Exec(`UPDATE users SET `+set+`, updated_at = NOW() WHERE id = $1`, append([]interface{}{userID}, args...)...)
+		if err != nil {
+			return nil, fmt.Errorf("error updating user: %v", err)
+		}
+		if before.isActive() && !after.isActive() {
+			if _, err := revokeUserSessions(tx, userID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	user, err := scanAdminUser(tx.QueryRow(`SELECT `+adminUserColumns+` FROM users WHERE id = $1`, userID))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+	if s.userStates != nil {
+		s.userStates.Invalidate(userID.String())
+	}
+
+	return user, nil
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLastAdmin(t *testing.T) {
+	admin := userState{Role: "admin", Status: true}
+
+	demoted := admin
+	demoted.Role = "user"
+	deactivated := admin
+	deactivated.Status = false
+	deleted := admin
+	deleted.Deleted = true
+
+	// The last active admin cannot be demoted, deactivated or deleted
+	for _, after := range []userState{demoted, deactivated, deleted} {
+		assert.EqualError(t, checkLastAdmin(admin, after, 1), "cannot remove the last active admin")
+		assert.NoError(t, checkLastAdmin(admin, after, 2))
+	}
+
+	// Changes that keep the admin active are fine
+	assert.NoError(t, checkLastAdmin(admin, admin, 1))
+
+	// Accounts that are not active admins do not count
+	assert.NoError(t, checkLastAdmin(deactivated, userState{Role: "user"}, 1))
+	assert.NoError(t, checkLastAdmin(userState{Role: "user", Status: true}, userState{Role: "user"}, 1))
+	assert.NoError(t, checkLastAdmin(deleted, admin, 0))
+}
+
+func TestRevokesTokens(t *testing.T) {
+	user := userState{Role: "user", Status: true}
+
+	promoted := user
+	promoted.Role = "admin"
+	deactivated := user
+	deactivated.Status = false
+	deleted := user
+	deleted.Deleted = true
+
+	// Tokens carry the role and are only valid for accounts that can sign in
+	assert.True(t, revokesTokens(user, promoted))
+	assert.True(t, revokesTokens(user, deactivated))
+	assert.True(t, revokesTokens(user, deleted))
+
+	// Giving access back or changing nothing keeps them
+	assert.False(t, revokesTokens(user, user))
+	assert.False(t, revokesTokens(deactivated, user))
+	assert.False(t, revokesTokens(deleted, user))
+	assert.False(t, revokesTokens(deactivated, deleted))
+}
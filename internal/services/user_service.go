@@ -2,7 +2,6 @@ package services
 
 import (
 	"context"
-	"e_meeting/internal/auth"
 	"e_meeting/internal/models"
 	"e_meeting/internal/repositories"
 	"errors"
@@ -15,20 +14,38 @@ import (
 
 type UserService interface {
 	Register(req models.RegisterRequest) (*models.User, error)
-	Login(req models.LoginRequest) (string, string, error)
+	Login(req models.LoginRequest, client models.ClientInfo) (*models.LoginResponse, error)
 	GetProfile(userID string) (*models.UserProfileResponse, error)
 	UpdateProfile(userID string, req *models.UpdateProfileRequest) (*models.UserProfileResponse, error)
+	UpdateAvatar(ctx context.Context, userID string, data []byte) (*models.UserProfileResponse, error)
+}
+
+// TokenIssuer starts a session for a user that just signed in
+type TokenIssuer interface {
+	IssueTokens(user *models.User, client models.ClientInfo) (*models.TokenResponse, error)
+}
+
+// TwoFactorChallenger decides whether a login needs a second factor and returns
+// its challenge, or nil when the password is enough
+type TwoFactorChallenger interface {
+	Challenge(user *models.User) (*models.LoginResponse, error)
 }
 
 type userService struct {
-	userRepo  repositories.UserRepository
-	jwtConfig *auth.JWTConfig
+	userRepo      repositories.UserRepository
+	authenticator Authenticator
+	tokens        TokenIssuer
+	twoFactor     TwoFactorChallenger
+	media         *MediaService
 }
 
-func NewUserService(userRepo repositories.UserRepository, jwtConfig *auth.JWTConfig) UserService {
+func NewUserService(userRepo repositories.UserRepository, authenticator Authenticator, tokens TokenIssuer, twoFactor TwoFactorChallenger, media *MediaService) UserService {
 	return &userService{
-		userRepo:  userRepo,
-		jwtConfig: jwtConfig,
+		userRepo:      userRepo,
+		authenticator: authenticator,
+		tokens:        tokens,
+		twoFactor:     twoFactor,
+		media:         media,
 	}
 }
 
@@ -70,31 +87,41 @@ func (s *userService) Register(req models.RegisterRequest) (*models.User, error)
 	return user, nil
 }
 
-func (s *userService) Login(req models.LoginRequest) (string, string, error) {
-	// Get user by username
-	user, err := s.userRepo.GetUserByUsername(context.Background(), req.Username)
+func (s *userService) Login(req models.LoginRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+	// Check username and password with the configured providers
+	user, err := s.authenticator.Authenticate(context.Background(), req.Username, req.Password)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to get user by username")
-		return "", "", err
+		return nil, err
 	}
-	if user == nil {
-		return "", "", errors.New("invalid credentials, user not found")
+
+	// Deleted accounts are never found, deactivated ones may not sign in
+	if !user.Status {
+		return nil, errors.New("account is deactivated")
 	}
 
-	// Check password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		log.Error().Err(err).Msg("Failed to compare password")
-		return "", "", errors.New("invalid credentials, password doesn't match")
+	// Accounts with 2FA get their tokens once the second factor is checked
+	challenge, err := s.twoFactor.Challenge(user)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create login challenge")
+		return nil, errors.New("failed to generate token")
+	}
+	if challenge != nil {
+		return challenge, nil
 	}
 
-	// Generate JWT token
-	token, err := s.jwtConfig.GenerateToken(user.ID.String(), user.Username, user.Role)
+	// Issue access and refresh tokens
+	tokens, err := s.tokens.IssueTokens(user, client)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to generate token")
-		return "", "", errors.New("failed to generate token")
+		return nil, errors.New("failed to generate token")
 	}
 
-	return token, user.ID.String(), nil
+	return &models.LoginResponse{
+		UserID:       user.ID.String(),
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}, nil
 }
 
 func (s *userService) GetProfile(userID string) (*models.UserProfileResponse, error) {
@@ -117,3 +144,27 @@ func (s *userService) UpdateProfile(userID string, req *models.UpdateProfileRequ
 	}
 	return profile, nil
 }
+
+// UpdateAvatar stores an uploaded profile picture and deletes the one it replaces
+func (s *userService) UpdateAvatar(ctx context.Context, userID string, data []byte) (*models.UserProfileResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %v", err)
+	}
+
+	key, url, err := s.media.StoreAvatar(ctx, id, data)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, oldKey, err := s.userRepo.UpdateAvatar(ctx, id, url, key)
+	if err != nil {
+		s.media.RemoveObject(ctx, key)
+		return nil, err
+	}
+
+	if oldKey != nil {
+		s.media.RemoveObject(ctx, *oldKey)
+	}
+	return profile, nil
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"e_meeting/internal/images"
+	"e_meeting/internal/models"
+	"e_meeting/internal/repositories"
+	"e_meeting/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// avatarRepository keeps the avatar of one user in memory
+type avatarRepository struct {
+	repositories.UserRepository
+	userID uuid.UUID
+	key    *string
+}
+
+func (r *avatarRepository) UpdateAvatar(ctx context.Context, userID uuid.UUID, profPic, key string) (*models.UserProfileResponse, *string, error) {
+	if userID != r.userID {
+		return nil, nil, assert.AnError
+	}
+	oldKey := r.key
+	r.key = &key
+	return &models.UserProfileResponse{ID: userID, ProfPic: &profPic}, oldKey, nil
+}
+
+func TestUserService_UpdateAvatarReplacesPreviousObject(t *testing.T) {
+	dir := t.TempDir()
+	userID := uuid.New()
+	repo := &avatarRepository{userID: userID}
+	media := NewMediaService(nil, storage.NewLocalStorage(dir, "/uploads"), 0)
+	service := NewUserService(repo, nil, nil, nil, media)
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 600, 300))))
+
+	first, err := service.UpdateAvatar(context.Background(), userID.String(), buf.Bytes())
+	require.NoError(t, err)
+	firstKey := *repo.key
+	assert.Equal(t, "/uploads/"+firstKey, *first.ProfPic)
+
+	stored, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(firstKey)))
+	require.NoError(t, err)
+	avatar, err := images.Decode(stored, 0)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, avatarSize, avatarSize), avatar.Bounds())
+
+	// A new upload replaces the stored object
+	_, err = service.UpdateAvatar(context.Background(), userID.String(), buf.Bytes())
+	require.NoError(t, err)
+	assert.NotEqual(t, firstKey, *repo.key)
+	_, err = os.Stat(filepath.Join(dir, filepath.FromSlash(firstKey)))
+	assert.True(t, os.IsNotExist(err))
+
+	// Invalid uploads are rejected before anything is stored
+	_, err = service.UpdateAvatar(context.Background(), userID.String(), []byte("not an image"))
+	assert.ErrorIs(t, err, images.ErrUnsupportedType)
+
+	// The new object is removed when the profile cannot be updated
+	_, err = service.UpdateAvatar(context.Background(), uuid.New().String(), buf.Bytes())
+	assert.Error(t, err)
+	entries, err := os.ReadDir(filepath.Join(dir, "avatars"))
+	require.NoError(t, err)
+	for _, entry := range entries {
+		if entry.Name() != userID.String() {
+			files, _ := os.ReadDir(filepath.Join(dir, "avatars", entry.Name()))
+			assert.Empty(t, files)
+		}
+	}
+}
+
+// loginRepository finds users by username in memory, like GetUserByUsername it
+// never returns soft deleted accounts
+type loginRepository struct {
+	repositories.UserRepository
+	users map[string]*models.User
+}
+
+func (r *loginRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.users[username], nil
+}
+
+// fakeTokenIssuer issues a fixed token pair and remembers who it was issued to
+type fakeTokenIssuer struct {
+	issued []*models.User
+}
+
+func (f *fakeTokenIssuer) IssueTokens(user *models.User, client models.ClientInfo) (*models.TokenResponse, error) {
+	f.issued = append(f.issued, user)
+	return &models.TokenResponse{Token: "access", RefreshToken: "refresh", ExpiresIn: 900}, nil
+}
+
+// fakeChallenger challenges the accounts that enabled 2FA
+type fakeChallenger struct{}
+
+func (fakeChallenger) Challenge(user *models.User) (*models.LoginResponse, error) {
+	if !user.TwoFactorEnabled {
+		return nil, nil
+	}
+	return &models.LoginResponse{UserID: user.ID.String(), TwoFactorRequired: true, ChallengeToken: "challenge"}, nil
+}
+
+func TestUserService_LoginChecksAccountStatus(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	repo := &loginRepository{users: map[string]*models.User{
+		"active":   {ID: uuid.New(), Username: "active", Password: string(hash), Role: "user", Status: true},
+		"inactive": {ID: uuid.New(), Username: "inactive", Password: string(hash), Role: "user", Status: false},
+	}}
+	tokens := &fakeTokenIssuer{}
+	service := NewUserService(repo, NewPasswordAuthenticator(repo), tokens, fakeChallenger{}, nil)
+
+	response, err := service.Login(models.LoginRequest{Username: "active", Password: "secret123"}, models.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, &models.LoginResponse{
+		UserID:       repo.users["active"].ID.String(),
+		Token:        "access",
+		RefreshToken: "refresh",
+		ExpiresIn:    900,
+	}, response)
+
+	// Deactivated accounts are refused, but only once the password matched
+	_, err = service.Login(models.LoginRequest{Username: "inactive", Password: "wrong123"}, models.ClientInfo{})
+	assert.EqualError(t, err, "invalid credentials, password doesn't match")
+	_, err = service.Login(models.LoginRequest{Username: "inactive", Password: "secret123"}, models.ClientInfo{})
+	assert.EqualError(t, err, "account is deactivated")
+
+	// Deleted accounts are not found
+	_, err = service.Login(models.LoginRequest{Username: "deleted", Password: "secret123"}, models.ClientInfo{})
+	assert.EqualError(t, err, "invalid credentials, user not found")
+
+	assert.Equal(t, []*models.User{repo.users["active"]}, tokens.issued)
+}
+
+func TestUserService_LoginReturnsTwoFactorChallenge(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{ID: uuid.New(), Username: "alice", Password: string(hash), Role: "admin", Status: true, TwoFactorEnabled: true}
+	repo := &loginRepository{users: map[string]*models.User{"alice": user}}
+	tokens := &fakeTokenIssuer{}
+	service := NewUserService(repo, NewPasswordAuthenticator(repo), tokens, fakeChallenger{}, nil)
+
+	// The password alone only gets the challenge, no tokens
+	response, err := service.Login(models.LoginRequest{Username: "alice", Password: "secret123"}, models.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, &models.LoginResponse{
+		UserID:            user.ID.String(),
+		TwoFactorRequired: true,
+		ChallengeToken:    "challenge",
+	}, response)
+	assert.Empty(t, tokens.issued)
+
+	_, err = service.Login(models.LoginRequest{Username: "alice", Password: "wrong123"}, models.ClientInfo{})
+	assert.EqualError(t, err, "invalid credentials, password doesn't match")
+}
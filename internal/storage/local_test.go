@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage_PutReplaceDelete(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalStorage(dir, "/uploads/")
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "rooms/a/photo.jpg", []byte("first"), "image/jpeg"))
+	require.NoError(t, store.Put(ctx, "rooms/a/photo.jpg", []byte("second"), "image/jpeg"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "rooms", "a", "photo.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+	assert.Equal(t, "/uploads/rooms/a/photo.jpg", store.URL("rooms/a/photo.jpg"))
+
+	require.NoError(t, store.Delete(ctx, "rooms/a/photo.jpg"))
+	_, err = os.Stat(filepath.Join(dir, "rooms", "a", "photo.jpg"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Deleting a missing object is not an error
+	assert.NoError(t, store.Delete(ctx, "rooms/a/photo.jpg"))
+}
+
+func TestLocalStorage_RejectsInvalidKeys(t *testing.T) {
+	store := NewLocalStorage(t.TempDir(), "/uploads")
+	for _, key := range []string{"", "/etc/passwd", "../secret", "rooms/../../secret", "rooms//a.jpg", `rooms\a.jpg`} {
+		assert.ErrorIs(t, store.Put(context.Background(), key, []byte("x"), "image/jpeg"), ErrInvalidKey, key)
+		assert.ErrorIs(t, store.Delete(context.Background(), key), ErrInvalidKey, key)
+	}
+}
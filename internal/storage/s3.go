@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Storage keeps objects in a bucket of an S3-compatible service such as
+// Cloudflare R2. Objects are read through publicURL, for example a public
+// bucket domain or CDN in front of the bucket.
+type S3Storage struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+// NewS3Storage connects to an S3-compatible endpoint given as host[:port]
+func NewS3Storage(endpoint, accessKeyID, secretAccessKey, bucket, publicURL string, useSSL bool) (*S3Storage, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("storage bucket is not configured")
+	}
+	if publicURL == "" {
+		return nil, fmt.Errorf("storage public URL is not configured")
+	}
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
+		Secure: useSSL,
+		Region: "auto",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating storage client: %v", err)
+	}
+	return &S3Storage{
+		client:    client,
+		bucket:    bucket,
+		publicURL: publicURL,
+	}, nil
+}
+
+// R2Endpoint returns the S3 endpoint of a Cloudflare account's R2 buckets
+func R2Endpoint(accountID string) string {
+	return accountID + ".r2.cloudflarestorage.com"
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType:  contentType,
+		CacheControl: "public, max-age=31536000, immutable",
+	})
+	if err != nil {
+		return fmt.Errorf("error uploading object: %v", err)
+	}
+	return nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("error deleting object: %v", err)
+	}
+	return nil
+}
+
+func (s *S3Storage) URL(key string) string {
+	return joinURL(s.publicURL, key)
+}
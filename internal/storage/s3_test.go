@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 records the object requests it receives
+type fakeS3 struct {
+	mu       sync.Mutex
+	requests []string
+	objects  map[string]string
+	types    map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			body = decodeChunks(body)
+		}
+		f.objects[r.URL.Path] = string(body)
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeChunks strips the signed chunk framing used for uploads over plain HTTP
+func decodeChunks(body []byte) []byte {
+	var data []byte
+	for len(body) > 0 {
+		header, rest, _ := bytes.Cut(body, []byte("\r\n"))
+		sizeHex, _, _ := bytes.Cut(header, []byte(";"))
+		size, err := strconv.ParseInt(string(sizeHex), 16, 64)
+		if err != nil || size == 0 {
+			break
+		}
+		data = append(data, rest[:size]...)
+		body = rest[size+2:]
+	}
+	return data
+}
+
+func TestS3Storage_PutDelete(t *testing.T) {
+	fake := &fakeS3{objects: map[string]string{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewS3Storage(strings.TrimPrefix(server.URL, "http://"), "key", "secret", "media", "https://cdn.example.com", false)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "snacks/s1/image.jpg", []byte("jpeg"), "image/jpeg"))
+	assert.Equal(t, "jpeg", fake.objects["/media/snacks/s1/image.jpg"])
+	assert.Equal(t, "image/jpeg", fake.types["/media/snacks/s1/image.jpg"])
+	assert.Equal(t, "https://cdn.example.com/snacks/s1/image.jpg", store.URL("snacks/s1/image.jpg"))
+
+	require.NoError(t, store.Delete(ctx, "snacks/s1/image.jpg"))
+	assert.NotContains(t, fake.objects, "/media/snacks/s1/image.jpg")
+	assert.Equal(t, []string{"PUT /media/snacks/s1/image.jpg", "DELETE /media/snacks/s1/image.jpg"}, fake.requests)
+
+	assert.ErrorIs(t, store.Put(ctx, "../x", []byte("x"), "image/jpeg"), ErrInvalidKey)
+}
+
+func TestNewS3Storage_RequiresBucketAndPublicURL(t *testing.T) {
+	_, err := NewS3Storage("example.com", "key", "secret", "", "https://cdn.example.com", true)
+	assert.Error(t, err)
+	_, err = NewS3Storage("example.com", "key", "secret", "media", "", true)
+	assert.Error(t, err)
+}
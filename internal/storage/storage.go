@@ -0,0 +1,40 @@
+// Package storage defines the object store used for uploaded files and its implementations.
+package storage
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+)
+
+// ErrInvalidKey is returned for keys that are empty or escape the store, such as ../x
+var ErrInvalidKey = errors.New("invalid object key")
+
+// Storage is implemented by object stores. Keys are slash separated paths
+// such as rooms/<room id>/<photo id>.jpg.
+type Storage interface {
+	// Put stores an object, replacing any object with the same key
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete removes an object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL of an object
+	URL(key string) string
+}
+
+// cleanKey validates a key and returns it in canonical form
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	cleaned := path.Clean(key)
+	if cleaned != key || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
+
+// joinURL appends a key to a base URL
+func joinURL(baseURL, key string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + key
+}
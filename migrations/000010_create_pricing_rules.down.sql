@@ -0,0 +1,10 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_pricing_rules_room_type_id;
+DROP INDEX IF EXISTS idx_pricing_rules_room_id;
+DROP INDEX IF EXISTS idx_rooms_room_type_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS holidays;
+DROP TABLE IF EXISTS pricing_rules;
+ALTER TABLE rooms DROP COLUMN IF EXISTS room_type_id;
+DROP TABLE IF EXISTS room_types;
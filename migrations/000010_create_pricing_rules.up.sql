@@ -0,0 +1,45 @@
+-- Create room_types table
+CREATE TABLE IF NOT EXISTS room_types (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Link rooms to a room type
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS room_type_id UUID REFERENCES room_types(id) ON DELETE SET NULL;
+
+-- Create pricing_rules table
+-- A rule without room_id and room_type_id applies to every room.
+-- days_of_week uses 0 = Sunday ... 6 = Saturday, an empty array matches every day.
+-- A NULL start_time/end_time window matches the whole day, end_time <= start_time wraps past midnight.
+CREATE TABLE IF NOT EXISTS pricing_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    room_id UUID REFERENCES rooms(id) ON DELETE CASCADE,
+    room_type_id UUID REFERENCES room_types(id) ON DELETE CASCADE,
+    days_of_week INT[] NOT NULL DEFAULT '{}',
+    start_time TIME,
+    end_time TIME,
+    multiplier DECIMAL(6,3) NOT NULL CHECK (multiplier > 0),
+    priority INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT pricing_rule_single_scope CHECK (room_id IS NULL OR room_type_id IS NULL),
+    CONSTRAINT pricing_rule_window CHECK ((start_time IS NULL) = (end_time IS NULL))
+);
+
+-- Create holidays table
+CREATE TABLE IF NOT EXISTS holidays (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    date DATE NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    surcharge_multiplier DECIMAL(6,3) NOT NULL CHECK (surcharge_multiplier > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_rooms_room_type_id ON rooms(room_type_id);
+CREATE INDEX IF NOT EXISTS idx_pricing_rules_room_id ON pricing_rules(room_id);
+CREATE INDEX IF NOT EXISTS idx_pricing_rules_room_type_id ON pricing_rules(room_type_id);
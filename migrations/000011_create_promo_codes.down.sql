@@ -0,0 +1,8 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_promo_code_redemptions_reservation_id;
+DROP INDEX IF EXISTS idx_promo_code_redemptions_user_id;
+DROP INDEX IF EXISTS idx_promo_code_redemptions_promo_code_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS promo_code_redemptions;
+DROP TABLE IF EXISTS promo_codes;
@@ -0,0 +1,7 @@
+ALTER TABLE reservations
+    DROP COLUMN IF EXISTS tax_amount,
+    DROP COLUMN IF EXISTS tax_rate,
+    DROP COLUMN IF EXISTS discount_total,
+    DROP COLUMN IF EXISTS snack_subtotal,
+    DROP COLUMN IF EXISTS room_subtotal,
+    DROP COLUMN IF EXISTS room_price_per_hour;
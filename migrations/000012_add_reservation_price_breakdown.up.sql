@@ -0,0 +1,28 @@
+-- Store the price breakdown of every reservation
+ALTER TABLE reservations
+    ADD COLUMN IF NOT EXISTS room_price_per_hour DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS room_subtotal DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS snack_subtotal DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS discount_total DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(5,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+-- Backfill existing reservations, price stays the grand total
+UPDATE reservations r
+SET
+    room_price_per_hour = rm.price_per_hour,
+    snack_subtotal = COALESCE((
+        SELECT SUM(rs.price * rs.quantity)
+        FROM reservation_snacks rs
+        WHERE rs.reservation_id = r.id
+    ), 0),
+    discount_total = COALESCE((
+        SELECT SUM(pcr.discount_amount)
+        FROM promo_code_redemptions pcr
+        WHERE pcr.reservation_id = r.id
+    ), 0)
+FROM rooms rm
+WHERE rm.id = r.room_id;
+
+UPDATE reservations
+SET room_subtotal = price - snack_subtotal + discount_total;
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_price_quotes_expires_at;
+DROP INDEX IF EXISTS idx_price_quotes_user_id;
+
+-- Drop table
+DROP TABLE IF EXISTS price_quotes;
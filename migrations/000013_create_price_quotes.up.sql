@@ -0,0 +1,20 @@
+-- Create price_quotes table
+-- calculation holds the full priced response that a booking using the quote must honour
+CREATE TABLE IF NOT EXISTS price_quotes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    promo_code VARCHAR(50),
+    snacks JSONB NOT NULL DEFAULT '[]',
+    calculation JSONB NOT NULL,
+    total DECIMAL(10,2) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    reservation_id UUID REFERENCES reservations(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_price_quotes_user_id ON price_quotes(user_id);
+CREATE INDEX IF NOT EXISTS idx_price_quotes_expires_at ON price_quotes(expires_at);
@@ -0,0 +1,8 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_invoice_lines_invoice_id;
+DROP INDEX IF EXISTS idx_invoices_user_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS invoice_lines;
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS invoice_sequences;
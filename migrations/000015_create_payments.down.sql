@@ -0,0 +1,8 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_payment_events_payment_id;
+DROP INDEX IF EXISTS idx_payments_reservation_id;
+DROP INDEX IF EXISTS idx_payments_open_reservation;
+
+-- Drop tables
+DROP TABLE IF EXISTS payment_events;
+DROP TABLE IF EXISTS payments;
@@ -0,0 +1,35 @@
+-- Create payments table
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reservation_id UUID NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id),
+    provider VARCHAR(50) NOT NULL,
+    provider_reference VARCHAR(255),
+    amount DECIMAL(10,2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    checkout_url TEXT,
+    failure_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_provider_reference UNIQUE (provider, provider_reference)
+);
+
+-- Create payment_events table, the log of gateway webhooks used to ignore redeliveries
+CREATE TABLE IF NOT EXISTS payment_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    payload JSONB NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_payment_event UNIQUE (provider, event_id)
+);
+
+-- Only one open payment per reservation
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_open_reservation ON payments(reservation_id) WHERE status = 'pending';
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_payments_reservation_id ON payments(reservation_id);
+CREATE INDEX IF NOT EXISTS idx_payment_events_payment_id ON payment_events(payment_id);
@@ -0,0 +1,9 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_reservation_ledger_created_at;
+DROP INDEX IF EXISTS idx_reservation_ledger_reservation_id;
+DROP INDEX IF EXISTS idx_cancellation_policies_default;
+DROP INDEX IF EXISTS idx_cancellation_policies_room_type;
+
+-- Drop tables
+DROP TABLE IF EXISTS reservation_ledger;
+DROP TABLE IF EXISTS cancellation_policies;
@@ -0,0 +1,30 @@
+-- Create cancellation_policies table; the policy without a room type is the default
+CREATE TABLE IF NOT EXISTS cancellation_policies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_type_id UUID REFERENCES room_types(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    free_cancellation_hours INT NOT NULL DEFAULT 24 CHECK (free_cancellation_hours >= 0),
+    late_cancellation_fee_percent DECIMAL(5,2) NOT NULL DEFAULT 0 CHECK (late_cancellation_fee_percent BETWEEN 0 AND 100),
+    no_show_fee_percent DECIMAL(5,2) NOT NULL DEFAULT 100 CHECK (no_show_fee_percent BETWEEN 0 AND 100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One policy per room type and a single default policy
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cancellation_policies_room_type ON cancellation_policies(room_type_id) WHERE room_type_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cancellation_policies_default ON cancellation_policies((room_type_id IS NULL)) WHERE room_type_id IS NULL;
+
+-- Create reservation_ledger table for cancellation fees and refunds
+CREATE TABLE IF NOT EXISTS reservation_ledger (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reservation_id UUID NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    entry_type VARCHAR(30) NOT NULL CHECK (entry_type IN ('cancellation_fee', 'refund')),
+    amount DECIMAL(10,2) NOT NULL CHECK (amount >= 0),
+    description TEXT NOT NULL,
+    cancellation_policy_id UUID REFERENCES cancellation_policies(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_reservation_ledger_reservation_id ON reservation_ledger(reservation_id);
+CREATE INDEX IF NOT EXISTS idx_reservation_ledger_created_at ON reservation_ledger(created_at);
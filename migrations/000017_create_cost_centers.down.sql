@@ -0,0 +1,12 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_reservations_cost_center_id;
+DROP INDEX IF EXISTS idx_users_default_cost_center_id;
+DROP INDEX IF EXISTS idx_cost_centers_department_id;
+
+-- Drop columns
+ALTER TABLE reservations DROP COLUMN IF EXISTS cost_center_id;
+ALTER TABLE users DROP COLUMN IF EXISTS default_cost_center_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS cost_centers;
+DROP TABLE IF EXISTS departments;
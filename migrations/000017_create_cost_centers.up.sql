@@ -0,0 +1,29 @@
+-- Create departments table
+CREATE TABLE IF NOT EXISTS departments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create cost_centers table
+CREATE TABLE IF NOT EXISTS cost_centers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    department_id UUID NOT NULL REFERENCES departments(id),
+    code VARCHAR(30) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Default cost center of each user
+ALTER TABLE users ADD COLUMN IF NOT EXISTS default_cost_center_id UUID REFERENCES cost_centers(id) ON DELETE SET NULL;
+
+-- Cost center charged for each reservation
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS cost_center_id UUID REFERENCES cost_centers(id);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_cost_centers_department_id ON cost_centers(department_id);
+CREATE INDEX IF NOT EXISTS idx_users_default_cost_center_id ON users(default_cost_center_id);
+CREATE INDEX IF NOT EXISTS idx_reservations_cost_center_id ON reservations(cost_center_id);
@@ -0,0 +1,9 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_reservations_approval_status;
+DROP INDEX IF EXISTS idx_cost_center_budgets_month;
+
+-- Drop columns
+ALTER TABLE reservations DROP COLUMN IF EXISTS approval_status;
+
+-- Drop tables
+DROP TABLE IF EXISTS cost_center_budgets;
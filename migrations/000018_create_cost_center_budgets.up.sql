@@ -0,0 +1,19 @@
+-- Create cost_center_budgets table, one budget per cost center and month
+CREATE TABLE IF NOT EXISTS cost_center_budgets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    cost_center_id UUID NOT NULL REFERENCES cost_centers(id) ON DELETE CASCADE,
+    month DATE NOT NULL CHECK (EXTRACT(DAY FROM month) = 1),
+    amount DECIMAL(12,2) NOT NULL CHECK (amount > 0),
+    enforcement VARCHAR(20) NOT NULL DEFAULT 'block' CHECK (enforcement IN ('block', 'approval')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_cost_center_month UNIQUE (cost_center_id, month)
+);
+
+-- Reservations over budget wait for an admin decision
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS approval_status VARCHAR(20)
+    CHECK (approval_status IN ('required', 'approved', 'rejected'));
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_cost_center_budgets_month ON cost_center_budgets(month);
+CREATE INDEX IF NOT EXISTS idx_reservations_approval_status ON reservations(approval_status) WHERE approval_status = 'required';
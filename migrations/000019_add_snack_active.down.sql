@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_snacks_active;
+
+ALTER TABLE snacks DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE snacks DROP COLUMN IF EXISTS active;
@@ -0,0 +1,5 @@
+-- Archived snacks stay in the catalogue for historical reservations but cannot be ordered
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_snacks_active ON snacks(active);
@@ -0,0 +1,11 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_snack_stock_movements_reservation_id;
+DROP INDEX IF EXISTS idx_snack_stock_movements_snack_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS snack_stock_movements;
+
+-- Drop columns
+ALTER TABLE snacks DROP COLUMN IF EXISTS low_stock_threshold;
+ALTER TABLE snacks DROP COLUMN IF EXISTS daily_capacity;
+ALTER TABLE snacks DROP COLUMN IF EXISTS stock_quantity;
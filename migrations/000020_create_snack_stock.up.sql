@@ -0,0 +1,21 @@
+-- Stock levels; a NULL stock or capacity means it is not tracked
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS stock_quantity INT CHECK (stock_quantity >= 0);
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS daily_capacity INT CHECK (daily_capacity >= 0);
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS low_stock_threshold INT NOT NULL DEFAULT 0 CHECK (low_stock_threshold >= 0);
+
+-- Create snack_stock_movements table, the log of every stock change
+CREATE TABLE IF NOT EXISTS snack_stock_movements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    snack_id UUID NOT NULL REFERENCES snacks(id) ON DELETE CASCADE,
+    reservation_id UUID REFERENCES reservations(id) ON DELETE SET NULL,
+    movement_type VARCHAR(20) NOT NULL CHECK (movement_type IN ('restock', 'adjustment', 'reserve', 'release')),
+    quantity INT NOT NULL,
+    stock_after INT NOT NULL,
+    note TEXT,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_snack_stock_movements_snack_id ON snack_stock_movements(snack_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_snack_stock_movements_reservation_id ON snack_stock_movements(reservation_id);
@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_snacks_allergens;
+DROP INDEX IF EXISTS idx_snacks_dietary_tags;
+
+-- Drop columns
+ALTER TABLE snacks DROP COLUMN IF EXISTS allergens;
+ALTER TABLE snacks DROP COLUMN IF EXISTS dietary_tags;
@@ -0,0 +1,8 @@
+-- Dietary attributes and allergens of snacks
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS dietary_tags TEXT[] NOT NULL DEFAULT '{}'
+    CHECK (dietary_tags <@ ARRAY['vegetarian', 'vegan', 'halal', 'gluten_free']::TEXT[]);
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS allergens TEXT[] NOT NULL DEFAULT '{}';
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_snacks_dietary_tags ON snacks USING GIN (dietary_tags);
+CREATE INDEX IF NOT EXISTS idx_snacks_allergens ON snacks USING GIN (allergens);
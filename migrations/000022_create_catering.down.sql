@@ -0,0 +1,14 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_catering_orders_deliver_at;
+DROP INDEX IF EXISTS idx_rooms_location_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS catering_orders;
+ALTER TABLE rooms DROP COLUMN IF EXISTS location_id;
+DROP TABLE IF EXISTS locations;
+
+-- Catering users fall back to the user role. Enum values cannot be dropped,
+-- so 'catering' stays in user_role but is rejected by the constraint.
+UPDATE users SET role = 'user' WHERE role::text = 'catering';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS valid_role;
+ALTER TABLE users ADD CONSTRAINT valid_role CHECK (role IN ('admin', 'user'));
@@ -0,0 +1,34 @@
+-- Add the catering role; the new enum value is compared as text because it
+-- cannot be used before this migration commits
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'catering';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS valid_role;
+ALTER TABLE users ADD CONSTRAINT valid_role CHECK (role::text IN ('admin', 'user', 'catering'));
+
+-- Create locations table, the buildings or sites served by one kitchen
+CREATE TABLE IF NOT EXISTS locations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Link rooms to a location
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS location_id UUID REFERENCES locations(id) ON DELETE SET NULL;
+
+-- Create catering_orders table, the kitchen side of the snacks of a reservation.
+-- deliver_at is a wall clock time like the reservation start_time.
+CREATE TABLE IF NOT EXISTS catering_orders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reservation_id UUID NOT NULL UNIQUE REFERENCES reservations(id) ON DELETE CASCADE,
+    deliver_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'preparing', 'delivered')),
+    notes TEXT,
+    status_updated_by UUID REFERENCES users(id),
+    status_updated_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_rooms_location_id ON rooms(location_id);
+CREATE INDEX IF NOT EXISTS idx_catering_orders_deliver_at ON catering_orders(deliver_at);
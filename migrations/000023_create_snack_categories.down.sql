@@ -0,0 +1,14 @@
+-- Drop tables
+DROP TABLE IF EXISTS location_snacks;
+
+-- Restore the free-text category
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS category VARCHAR(100);
+UPDATE snacks s SET category = c.name
+FROM snack_categories c
+WHERE c.id = s.category_id;
+ALTER TABLE snacks ALTER COLUMN category SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_snacks_category ON snacks(category);
+
+DROP INDEX IF EXISTS idx_snacks_category_id;
+ALTER TABLE snacks DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS snack_categories;
@@ -0,0 +1,40 @@
+-- Create snack_categories table, ordered by sort_order then name
+CREATE TABLE IF NOT EXISTS snack_categories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_snack_categories_name ON snack_categories(LOWER(name));
+
+-- One category per free-text category, ignoring case and surrounding spaces.
+-- The most used spelling becomes the category name.
+INSERT INTO snack_categories (name)
+SELECT DISTINCT ON (LOWER(TRIM(category))) TRIM(category)
+FROM snacks
+GROUP BY TRIM(category)
+ORDER BY LOWER(TRIM(category)), COUNT(*) DESC, TRIM(category);
+
+-- Make snacks reference their category
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS category_id UUID REFERENCES snack_categories(id);
+UPDATE snacks s SET category_id = c.id
+FROM snack_categories c
+WHERE LOWER(c.name) = LOWER(TRIM(s.category));
+ALTER TABLE snacks ALTER COLUMN category_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_snacks_category;
+ALTER TABLE snacks DROP COLUMN IF EXISTS category;
+CREATE INDEX IF NOT EXISTS idx_snacks_category_id ON snacks(category_id);
+
+-- Create location_snacks table, the menu of a location.
+-- A location without entries serves the whole catalogue.
+CREATE TABLE IF NOT EXISTS location_snacks (
+    location_id UUID NOT NULL REFERENCES locations(id) ON DELETE CASCADE,
+    snack_id UUID NOT NULL REFERENCES snacks(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (location_id, snack_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_location_snacks_snack_id ON location_snacks(snack_id);
@@ -0,0 +1,26 @@
+-- Restore the price columns with the rates in effect today
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS price_per_hour DECIMAL(10,2);
+UPDATE rooms r SET price_per_hour = (
+    SELECT rp.price FROM room_prices rp
+    WHERE rp.room_id = r.id
+    ORDER BY rp.effective_from <= CURRENT_DATE DESC,
+        CASE WHEN rp.effective_from <= CURRENT_DATE THEN rp.effective_from END DESC,
+        rp.effective_from ASC
+    LIMIT 1
+);
+ALTER TABLE rooms ALTER COLUMN price_per_hour SET NOT NULL;
+
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS price DECIMAL(10,2);
+UPDATE snacks s SET price = (
+    SELECT sp.price FROM snack_prices sp
+    WHERE sp.snack_id = s.id
+    ORDER BY sp.effective_from <= CURRENT_DATE DESC,
+        CASE WHEN sp.effective_from <= CURRENT_DATE THEN sp.effective_from END DESC,
+        sp.effective_from ASC
+    LIMIT 1
+);
+ALTER TABLE snacks ALTER COLUMN price SET NOT NULL;
+
+-- Drop tables
+DROP TABLE IF EXISTS snack_prices;
+DROP TABLE IF EXISTS room_prices;
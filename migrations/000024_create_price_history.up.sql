@@ -0,0 +1,33 @@
+-- Create room_prices and snack_prices tables, the rate history of rooms and snacks.
+-- A rate applies from effective_from until the next change; rates dated after
+-- today are scheduled changes. The first rate also applies to earlier dates.
+CREATE TABLE IF NOT EXISTS room_prices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    price DECIMAL(10,2) NOT NULL CHECK (price >= 0),
+    effective_from DATE NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (room_id, effective_from)
+);
+
+CREATE TABLE IF NOT EXISTS snack_prices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    snack_id UUID NOT NULL REFERENCES snacks(id) ON DELETE CASCADE,
+    price DECIMAL(10,2) NOT NULL CHECK (price >= 0),
+    effective_from DATE NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (snack_id, effective_from)
+);
+
+-- The current prices become the first rate of each room and snack
+INSERT INTO room_prices (room_id, price, effective_from)
+SELECT id, price_per_hour, COALESCE(created_at::date, CURRENT_DATE) FROM rooms;
+
+INSERT INTO snack_prices (snack_id, price, effective_from)
+SELECT id, price, COALESCE(created_at::date, CURRENT_DATE) FROM snacks;
+
+-- Prices are read from the history from now on
+ALTER TABLE rooms DROP COLUMN IF EXISTS price_per_hour;
+ALTER TABLE snacks DROP COLUMN IF EXISTS price;
@@ -0,0 +1,8 @@
+-- Drop snack image columns
+ALTER TABLE snacks DROP COLUMN IF EXISTS thumbnail_url;
+ALTER TABLE snacks DROP COLUMN IF EXISTS thumbnail_key;
+ALTER TABLE snacks DROP COLUMN IF EXISTS image_url;
+ALTER TABLE snacks DROP COLUMN IF EXISTS image_key;
+
+-- Drop tables
+DROP TABLE IF EXISTS room_photos;
@@ -0,0 +1,19 @@
+-- Create room_photos table. Keys locate the objects in storage, URLs are
+-- their public addresses.
+CREATE TABLE IF NOT EXISTS room_photos (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    image_key VARCHAR(255) NOT NULL,
+    image_url TEXT NOT NULL,
+    thumbnail_key VARCHAR(255) NOT NULL,
+    thumbnail_url TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_photos_room_id ON room_photos(room_id);
+
+-- One image per snack
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS image_key VARCHAR(255);
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS image_url TEXT;
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS thumbnail_key VARCHAR(255);
+ALTER TABLE snacks ADD COLUMN IF NOT EXISTS thumbnail_url TEXT;
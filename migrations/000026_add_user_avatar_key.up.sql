@@ -0,0 +1,2 @@
+-- Storage key of an uploaded avatar, NULL when prof_pic is not an uploaded file
+ALTER TABLE users ADD COLUMN IF NOT EXISTS prof_pic_key VARCHAR(255);
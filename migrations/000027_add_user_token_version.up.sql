@@ -0,0 +1,2 @@
+-- Bumped whenever an account loses access, invalidating every token issued before
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Codes tried for the second factor of an account since the last accepted one.
+-- They are counted per account, not per login challenge, so signing in again
+-- does not allow more guesses; the count is forgotten a while after the last try.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS two_factor_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS two_factor_attempted_at TIMESTAMP WITH TIME ZONE;